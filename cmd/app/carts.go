@@ -0,0 +1,112 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"go-fiber-api/database"
+	cartRepo "go-fiber-api/internal/app/cart/repository"
+	cartService "go-fiber-api/internal/app/cart/service"
+	couponRepo "go-fiber-api/internal/app/coupon/repository"
+	couponService "go-fiber-api/internal/app/coupon/service"
+	inventoryRepo "go-fiber-api/internal/app/inventory/repository"
+	inventoryService "go-fiber-api/internal/app/inventory/service"
+	productRepo "go-fiber-api/internal/app/product/repository"
+	shippingRepo "go-fiber-api/internal/app/shipping/repository"
+	shippingService "go-fiber-api/internal/app/shipping/service"
+	taxRepo "go-fiber-api/internal/app/tax/repository"
+	taxService "go-fiber-api/internal/app/tax/service"
+	"go-fiber-api/internal/shared/notifier"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var cartsCmd = &cobra.Command{
+	Use:   "carts",
+	Short: "Maintain carts",
+}
+
+var cartsSweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Detect abandoned carts, send reminders and purge carts past the retention period",
+	Args:  cobra.NoArgs,
+	Run:   sweepCarts,
+}
+
+var cartsReportCmd = &cobra.Command{
+	Use:   "report [file]",
+	Short: "Write the cart abandonment report as JSON (default stdout)",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   reportCarts,
+}
+
+func init() {
+	cartsReportCmd.Flags().Int("days", 7, "report on carts last active in the past N days")
+
+	cartsCmd.AddCommand(cartsSweepCmd, cartsReportCmd)
+	rootCmd.AddCommand(cartsCmd)
+}
+
+// newCartServiceFromEnv memuat .env dan koneksi database untuk command CLI
+func newCartServiceFromEnv() cartService.Cart {
+	if os.Getenv("ENV") != "production" {
+		if err := godotenv.Load(); err != nil {
+			log.Println("⚠️  .env file tidak ditemukan, menggunakan default environment")
+		}
+	}
+
+	database.ConnectDB()
+	tx := database.NewTransactor(database.DB)
+	inventoryService := inventoryService.NewInventoryService(inventoryRepo.NewInventoryRepository(database.DB), reservationTTL(), notifier.NewFromEnv())
+	couponService := couponService.NewCouponService(couponRepo.NewCouponRepository(database.DB), tx)
+	taxCalculator := taxService.NewRuleCalculator(taxRepo.NewTaxRepository(database.DB))
+	shippingService := shippingService.NewShippingService(shippingRepo.NewShippingRepository(database.DB), tx, freeShippingThreshold())
+	return cartService.NewCartService(cartRepo.NewCartRepository(database.DB), productRepo.NewProductRepository(database.DB),
+		inventoryService, couponService, taxCalculator, shippingService, notifier.NewFromEnv(), tx, cartConfig())
+}
+
+func sweepCarts(cmd *cobra.Command, args []string) {
+	service := newCartServiceFromEnv()
+	result, err := service.SweepAbandonedCarts(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Gagal menjalankan sweep cart: %v", err)
+	}
+	log.Printf("✅ Sweep selesai: %d abandoned, %d reminder, %d recovered, %d closed, %d cart dihapus (%d baris)\n",
+		result.Abandoned, result.Reminded, result.Recovered, result.Closed, result.PurgedCarts, result.PurgedItems)
+}
+
+func reportCarts(cmd *cobra.Command, args []string) {
+	days, _ := cmd.Flags().GetInt("days")
+	if days < 1 {
+		log.Fatal("❌ --days minimal 1")
+	}
+
+	var output io.Writer = os.Stdout
+	if len(args) == 1 && args[0] != "-" {
+		file, err := os.Create(args[0])
+		if err != nil {
+			log.Fatalf("❌ Gagal membuat file: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	service := newCartServiceFromEnv()
+	to := time.Now()
+	report, err := service.AbandonmentReport(context.Background(), to.AddDate(0, 0, -days), to)
+	if err != nil {
+		log.Fatalf("❌ Gagal membuat laporan: %v", err)
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("❌ Gagal menulis laporan: %v", err)
+	}
+	log.Printf("✅ Abandonment rate %.2f%% dari %d cart aktif\n", report.AbandonmentRate*100, report.ActiveCarts)
+}
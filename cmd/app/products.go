@@ -0,0 +1,136 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go-fiber-api/database"
+	attributeRepo "go-fiber-api/internal/app/attribute/repository"
+	attributeService "go-fiber-api/internal/app/attribute/service"
+	inventoryRepo "go-fiber-api/internal/app/inventory/repository"
+	inventoryService "go-fiber-api/internal/app/inventory/service"
+	productRepo "go-fiber-api/internal/app/product/repository"
+	productService "go-fiber-api/internal/app/product/service"
+	taxRepo "go-fiber-api/internal/app/tax/repository"
+	taxService "go-fiber-api/internal/app/tax/service"
+	"go-fiber-api/internal/shared/notifier"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var productsCmd = &cobra.Command{
+	Use:   "products",
+	Short: "Manage products in bulk",
+}
+
+var productsImportCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Import products from a CSV or JSONL file (use - for stdin)",
+	Args:  cobra.ExactArgs(1),
+	Run:   importProducts,
+}
+
+var productsExportCmd = &cobra.Command{
+	Use:   "export [file]",
+	Short: "Export all products to a CSV or JSONL file (default stdout)",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   exportProducts,
+}
+
+func init() {
+	productsImportCmd.Flags().String("format", "", "file format: csv or jsonl (default: from file extension)")
+	productsImportCmd.Flags().Bool("dry-run", false, "validate every row and print the report without writing")
+	productsExportCmd.Flags().String("format", "", "file format: csv or jsonl (default: from file extension, csv for stdout)")
+
+	productsCmd.AddCommand(productsImportCmd, productsExportCmd)
+	rootCmd.AddCommand(productsCmd)
+}
+
+// newProductServiceFromEnv memuat .env dan koneksi database untuk command CLI
+func newProductServiceFromEnv() productService.Product {
+	if os.Getenv("ENV") != "production" {
+		if err := godotenv.Load(); err != nil {
+			log.Println("⚠️  .env file tidak ditemukan, menggunakan default environment")
+		}
+	}
+
+	database.ConnectDB()
+	inventoryService := inventoryService.NewInventoryService(inventoryRepo.NewInventoryRepository(database.DB), reservationTTL(), notifier.NewFromEnv())
+	attributeService := attributeService.NewAttributeService(attributeRepo.NewAttributeRepository(database.DB))
+	taxService := taxService.NewTaxService(taxRepo.NewTaxRepository(database.DB))
+	return productService.NewProductService(productRepo.NewProductRepository(database.DB), inventoryService, attributeService, taxService, database.NewTransactor(database.DB))
+}
+
+// formatFromPath menebak format dari ekstensi file jika flag --format kosong
+func formatFromPath(format, path string) string {
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return productService.FormatJSONL
+	}
+	return productService.FormatCSV
+}
+
+func importProducts(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	path := args[0]
+
+	var input io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("❌ Gagal membuka file: %v", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	service := newProductServiceFromEnv()
+	report, err := service.ImportProducts(context.Background(), formatFromPath(format, path), input, dryRun)
+	if err != nil {
+		log.Fatalf("❌ Gagal import produk: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatalf("❌ Gagal menulis report: %v", err)
+	}
+
+	if report.Failed > 0 {
+		log.Printf("⚠️  %d dari %d baris gagal", report.Failed, report.Total)
+		os.Exit(1)
+	}
+	log.Printf("✅ Import selesai: %d dibuat, %d diupdate (dry-run: %t)", report.Created, report.Updated, dryRun)
+}
+
+func exportProducts(cmd *cobra.Command, args []string) {
+	format, _ := cmd.Flags().GetString("format")
+
+	var output io.Writer = os.Stdout
+	path := "-"
+	if len(args) == 1 && args[0] != "-" {
+		path = args[0]
+		file, err := os.Create(path)
+		if err != nil {
+			log.Fatalf("❌ Gagal membuat file: %v", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	service := newProductServiceFromEnv()
+	if err := service.ExportProducts(context.Background(), formatFromPath(format, path), output); err != nil {
+		log.Fatalf("❌ Gagal export produk: %v", err)
+	}
+	log.Println("✅ Export produk selesai")
+}
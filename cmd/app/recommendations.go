@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"go-fiber-api/database"
+	productRepo "go-fiber-api/internal/app/product/repository"
+	recommendationRepo "go-fiber-api/internal/app/recommendation/repository"
+	recommendationService "go-fiber-api/internal/app/recommendation/service"
+	"go-fiber-api/utils/helper/env"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+var recommendationsCmd = &cobra.Command{
+	Use:   "recommendations",
+	Short: "Manage product recommendations",
+}
+
+var recommendationsComputeCmd = &cobra.Command{
+	Use:   "compute",
+	Short: "Recompute frequently-bought-together scores from cart co-occurrence",
+	Args:  cobra.NoArgs,
+	Run:   runRecommendationsCompute,
+}
+
+func init() {
+	recommendationsCmd.AddCommand(recommendationsComputeCmd)
+	rootCmd.AddCommand(recommendationsCmd)
+}
+
+func runRecommendationsCompute(cmd *cobra.Command, args []string) {
+	if os.Getenv("ENV") != "production" {
+		if err := godotenv.Load(); err != nil {
+			log.Println("⚠️  .env file tidak ditemukan, menggunakan default environment")
+		}
+	}
+
+	database.ConnectDB()
+	service := newRecommendationService()
+
+	count, err := service.Recompute(context.Background())
+	if err != nil {
+		log.Fatalf("❌ Gagal menghitung rekomendasi: %v", err)
+	}
+	log.Printf("✅ %d pasangan rekomendasi tersimpan\n", count)
+}
+
+// newRecommendationService membuat service rekomendasi dari koneksi database yang sudah terbuka.
+// RECOMMENDATION_MIN_PAIRS mengatur jumlah minimum user per pasangan produk (default 2).
+func newRecommendationService() recommendationService.Recommendation {
+	return recommendationService.NewRecommendationService(
+		recommendationRepo.NewRecommendationRepository(database.DB),
+		productRepo.NewProductRepository(database.DB),
+		env.Int("RECOMMENDATION_MIN_PAIRS", 2),
+	)
+}
@@ -1,11 +1,21 @@
 package app
 
 import (
+	"context"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"go-fiber-api/database"
+	"go-fiber-api/internal/shared/notifier"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/helper/env"
+
+	// Attribute
+	attributeController "go-fiber-api/internal/app/attribute/controller"
+	attributeRepo "go-fiber-api/internal/app/attribute/repository"
+	attributeService "go-fiber-api/internal/app/attribute/service"
 
 	// User
 	userController "go-fiber-api/internal/app/user/controller"
@@ -17,6 +27,44 @@ import (
 	cartRepo "go-fiber-api/internal/app/cart/repository"
 	cartService "go-fiber-api/internal/app/cart/service"
 
+	// Coupon
+	couponController "go-fiber-api/internal/app/coupon/controller"
+	couponRepo "go-fiber-api/internal/app/coupon/repository"
+	couponService "go-fiber-api/internal/app/coupon/service"
+
+	// Currency
+	currencyController "go-fiber-api/internal/app/currency/controller"
+	currencyRepo "go-fiber-api/internal/app/currency/repository"
+	currencyService "go-fiber-api/internal/app/currency/service"
+
+	// Inventory
+	inventoryController "go-fiber-api/internal/app/inventory/controller"
+	inventoryRepo "go-fiber-api/internal/app/inventory/repository"
+	inventoryService "go-fiber-api/internal/app/inventory/service"
+
+	// Recommendation
+	recommendationController "go-fiber-api/internal/app/recommendation/controller"
+
+	// Review
+	reviewController "go-fiber-api/internal/app/review/controller"
+	reviewRepo "go-fiber-api/internal/app/review/repository"
+	reviewService "go-fiber-api/internal/app/review/service"
+
+	// Wishlist
+	wishlistController "go-fiber-api/internal/app/wishlist/controller"
+	wishlistRepo "go-fiber-api/internal/app/wishlist/repository"
+	wishlistService "go-fiber-api/internal/app/wishlist/service"
+
+	// Shipping
+	shippingController "go-fiber-api/internal/app/shipping/controller"
+	shippingRepo "go-fiber-api/internal/app/shipping/repository"
+	shippingService "go-fiber-api/internal/app/shipping/service"
+
+	// Tax
+	taxController "go-fiber-api/internal/app/tax/controller"
+	taxRepo "go-fiber-api/internal/app/tax/repository"
+	taxService "go-fiber-api/internal/app/tax/service"
+
 	// Product
 	productController "go-fiber-api/internal/app/product/controller"
 	productRepo "go-fiber-api/internal/app/product/repository"
@@ -48,16 +96,59 @@ func serveApp(cmd *cobra.Command, args []string) {
 	userRepo := userRepo.NewUserRepository(database.DB)
 	userService := userService.NewUserService(userRepo)
 
+	currencyRepo := currencyRepo.NewCurrencyRepository(database.DB)
+	currencyService := currencyService.NewCurrencyService(currencyRepo)
+
+	inventoryRepo := inventoryRepo.NewInventoryRepository(database.DB)
+	inventoryService := inventoryService.NewInventoryService(inventoryRepo, reservationTTL(), notifier.NewFromEnv())
+	go inventoryService.RunReservationSweeper(context.Background(), time.Minute)
+
+	attributeRepo := attributeRepo.NewAttributeRepository(database.DB)
+	attributeService := attributeService.NewAttributeService(attributeRepo)
+
+	taxRepo := taxRepo.NewTaxRepository(database.DB)
+	taxCalculator := taxService.NewRuleCalculator(taxRepo)
+	taxService := taxService.NewTaxService(taxRepo)
+
 	productRepo := productRepo.NewProductRepository(database.DB)
-	productService := productService.NewProductService(productRepo)
+	productService := productService.NewProductService(productRepo, inventoryService, attributeService, taxService, database.NewTransactor(database.DB))
+
+	reviewRepo := reviewRepo.NewReviewRepository(database.DB)
+	reviewService := reviewService.NewReviewService(reviewRepo, productRepo)
+
+	// Rekomendasi dihitung ulang berkala; RECOMMENDATION_INTERVAL=0 mematikan job (jalankan lewat CLI)
+	recommendationService := newRecommendationService()
+	go recommendationService.RunScheduler(context.Background(), env.Duration("RECOMMENDATION_INTERVAL", time.Hour))
+
+	couponRepo := couponRepo.NewCouponRepository(database.DB)
+	couponService := couponService.NewCouponService(couponRepo, database.NewTransactor(database.DB))
+
+	shippingRepo := shippingRepo.NewShippingRepository(database.DB)
+	shippingService := shippingService.NewShippingService(shippingRepo, database.NewTransactor(database.DB), freeShippingThreshold())
 
 	cartRepo := cartRepo.NewCartRepository(database.DB)
-	cartService := cartService.NewCartService(cartRepo, productRepo)
+	cartService := cartService.NewCartService(cartRepo, productRepo, inventoryService, couponService, taxCalculator, shippingService,
+		notifier.NewFromEnv(), database.NewTransactor(database.DB), cartConfig())
+	go cartService.RunGuestCartSweeper(context.Background(), time.Hour)
+	// CART_ABANDONMENT_INTERVAL=0 mematikan job abandoned cart (jalankan lewat CLI)
+	go cartService.RunAbandonedCartJob(context.Background(), env.Duration("CART_ABANDONMENT_INTERVAL", time.Hour))
+
+	wishlistRepo := wishlistRepo.NewWishlistRepository(database.DB)
+	wishlistService := wishlistService.NewWishlistService(wishlistRepo, productRepo, cartService, database.NewTransactor(database.DB))
 
 	mux := http.NewServeMux()
-	userController.NewUserController(mux, userService)
-	productController.NewProductController(mux, productService)
-	cartController.NewCartController(mux, cartService)
+	userController.NewUserController(mux, userService, cartService)
+	currencyController.NewCurrencyController(mux, currencyService)
+	attributeController.NewAttributeController(mux, attributeService)
+	productController.NewProductController(mux, productService, currencyService)
+	inventoryController.NewInventoryController(mux, inventoryService)
+	cartController.NewCartController(mux, cartService, currencyService)
+	couponController.NewCouponController(mux, couponService)
+	taxController.NewTaxController(mux, taxService)
+	shippingController.NewShippingController(mux, shippingService)
+	reviewController.NewReviewController(mux, reviewService)
+	recommendationController.NewRecommendationController(mux, recommendationService)
+	wishlistController.NewWishlistController(mux, wishlistService)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -67,3 +158,48 @@ func serveApp(cmd *cobra.Command, args []string) {
 	log.Fatal(http.ListenAndServe(":"+port, mux))
 
 }
+
+// reservationTTL membaca STOCK_RESERVATION_TTL (contoh: "15m"). Kosong atau nol berarti
+// stok hanya dicek tanpa reservasi.
+func reservationTTL() time.Duration {
+	value := os.Getenv("STOCK_RESERVATION_TTL")
+	if value == "" {
+		return 0
+	}
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("⚠️  STOCK_RESERVATION_TTL tidak valid (%q), reservasi stok dimatikan\n", value)
+		return 0
+	}
+	return ttl
+}
+
+// cartConfig membaca pengaturan service cart dari env
+func cartConfig() cartService.Config {
+	return cartService.Config{
+		GuestTTL:        env.Duration("CART_GUEST_TTL", 30*24*time.Hour),
+		MaxLines:        env.Int("CART_MAX_LINES", 50),
+		MaxLineQuantity: env.Int("CART_MAX_LINE_QUANTITY", 999),
+
+		DefaultTaxCountry: env.String("TAX_DEFAULT_COUNTRY", "ID"),
+
+		AbandonAfter:  env.Duration("CART_ABANDON_AFTER", 24*time.Hour),
+		Retention:     env.Duration("CART_RETENTION", 90*24*time.Hour),
+		ArchivePurged: env.String("CART_PURGE_MODE", "archive") != "delete",
+	}
+}
+
+// freeShippingThreshold membaca SHIPPING_FREE_THRESHOLD (desimal dalam mata uang default,
+// mis. "500000"). Kosong atau tidak valid berarti free shipping tidak aktif.
+func freeShippingThreshold() types.Money {
+	value := os.Getenv("SHIPPING_FREE_THRESHOLD")
+	if value == "" {
+		return types.Money{}
+	}
+	threshold, err := types.ParseMoney(value, types.DefaultCurrency())
+	if err != nil {
+		log.Printf("⚠️  SHIPPING_FREE_THRESHOLD tidak valid (%q), free shipping dimatikan\n", value)
+		return types.Money{}
+	}
+	return threshold
+}
@@ -0,0 +1,15 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// IsUniqueViolation bernilai true jika err berasal dari pelanggaran unique index/constraint
+// bernama constraint. Dipakai untuk insert yang cek keunikannya bisa kalah cepat dengan
+// request lain di antara pengecekan dan penulisan.
+func IsUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505" && pqErr.Constraint == constraint
+}
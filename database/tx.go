@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// DBTX adalah operasi query yang dimiliki *sqlx.DB maupun *sqlx.Tx, sehingga repository
+// bisa dipakai di dalam maupun di luar transaksi
+type DBTX interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
+}
+
+type txKey struct{}
+
+type afterCommitKey struct{}
+
+// afterCommitHooks menampung fungsi yang dijalankan setelah transaksi terluar commit
+type afterCommitHooks struct {
+	fns []func()
+}
+
+// AfterCommit menjadwalkan fn setelah transaksi yang dibawa ctx berhasil commit; jika transaksi
+// di-rollback fn tidak dijalankan. Tanpa transaksi di ctx, fn langsung dijalankan.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(afterCommitKey{}).(*afterCommitHooks); ok {
+		hooks.fns = append(hooks.fns, fn)
+		return
+	}
+	fn()
+}
+
+// Conn mengembalikan transaksi yang dibawa ctx, atau db jika tidak ada transaksi
+func Conn(ctx context.Context, db *sqlx.DB) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return tx
+	}
+	return db
+}
+
+// RunInTx menjalankan fn dalam transaksi dan menyimpannya di ctx, sehingga repository lain yang
+// dipanggil dengan ctx tersebut ikut transaksi yang sama. Jika ctx sudah membawa transaksi,
+// fn ikut transaksi itu dan commit/rollback diserahkan ke pemiliknya.
+func RunInTx(ctx context.Context, db *sqlx.DB, fn func(ctx context.Context, tx *sqlx.Tx) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx, tx)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	hooks := &afterCommitHooks{}
+	txCtx := context.WithValue(context.WithValue(ctx, txKey{}, tx), afterCommitKey{}, hooks)
+	if err := fn(txCtx, tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	for _, hook := range hooks.fns {
+		hook()
+	}
+	return nil
+}
+
+// Transactor adalah unit of work untuk service: semua repository yang dipanggil di dalam fn
+// dengan ctx yang diberikan berjalan dalam satu transaksi
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type transactor struct {
+	db *sqlx.DB
+}
+
+func NewTransactor(db *sqlx.DB) Transactor {
+	return &transactor{db: db}
+}
+
+func (t *transactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return RunInTx(ctx, t.db, func(ctx context.Context, _ *sqlx.Tx) error {
+		return fn(ctx)
+	})
+}
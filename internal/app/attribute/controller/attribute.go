@@ -0,0 +1,73 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/attribute/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type attribute struct {
+	attributeService service.Attribute
+}
+
+func NewAttributeController(mux *http.ServeMux, attributeService service.Attribute) {
+	a := &attribute{attributeService: attributeService}
+
+	mux.HandleFunc("GET /v1/attributes", a.GetAll)
+	mux.HandleFunc("PUT /v1/admin/attributes/{code}", middleware.ValidateRole(types.RoleAdmin)(a.Save))
+	mux.HandleFunc("DELETE /v1/admin/attributes/{code}", middleware.ValidateRole(types.RoleAdmin)(a.Delete))
+}
+
+func (a *attribute) GetAll(w http.ResponseWriter, r *http.Request) {
+	definitions, err := a.attributeService.GetAll(r.Context())
+	if err != nil {
+		slog.Error("GetAllAttributes failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, definitions)
+}
+
+func (a *attribute) Save(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	slog.Info("SaveAttribute called", "code", code)
+
+	var req dto.AttributeDefinitionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("SaveAttribute failed - invalid JSON", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		slog.Error("SaveAttribute failed - validation error", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	definition, err := a.attributeService.Save(r.Context(), code, &req)
+	if err != nil {
+		slog.Error("SaveAttribute failed", "code", code, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, definition)
+}
+
+func (a *attribute) Delete(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	if err := a.attributeService.Delete(r.Context(), code); err != nil {
+		slog.Error("DeleteAttribute failed", "code", code, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, map[string]string{"message": "Attribute deleted"})
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Tipe nilai atribut
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeEnum    = "enum"
+)
+
+// Definition adalah skema satu atribut produk. AllowedValues wajib untuk enum,
+// dan opsional untuk string (jika diisi, nilai harus salah satu di antaranya).
+type Definition struct {
+	Code          string         `db:"code" json:"code"`
+	Name          string         `db:"name" json:"name"`
+	Type          string         `db:"type" json:"type"`
+	AllowedValues pq.StringArray `db:"allowed_values" json:"allowed_values"`
+	Filterable    bool           `db:"filterable" json:"filterable"`
+	CreatedAt     time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time      `db:"updated_at" json:"updated_at"`
+}
+
+// Allows memeriksa apakah value termasuk AllowedValues; selalu true jika daftar kosong
+func (d *Definition) Allows(value string) bool {
+	if len(d.AllowedValues) == 0 {
+		return true
+	}
+	for _, allowed := range d.AllowedValues {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/internal/app/attribute/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Attribute interface {
+	GetAll(ctx context.Context) ([]model.Definition, error)
+	GetByCode(ctx context.Context, code string) (*model.Definition, error)
+	Upsert(ctx context.Context, d *model.Definition) error
+	Delete(ctx context.Context, code string) (bool, error)
+}
+
+type attributeRepo struct {
+	db *sqlx.DB
+}
+
+func NewAttributeRepository(db *sqlx.DB) Attribute {
+	return &attributeRepo{db: db}
+}
+
+func (r *attributeRepo) GetAll(ctx context.Context) ([]model.Definition, error) {
+	var definitions []model.Definition
+	query := `SELECT * FROM attribute_definitions ORDER BY code`
+
+	if err := r.db.SelectContext(ctx, &definitions, query); err != nil {
+		slog.Error("Failed to get attribute definitions", "error", err)
+		return nil, err
+	}
+	return definitions, nil
+}
+
+func (r *attributeRepo) GetByCode(ctx context.Context, code string) (*model.Definition, error) {
+	var definition model.Definition
+	if err := r.db.GetContext(ctx, &definition, `SELECT * FROM attribute_definitions WHERE code = $1`, code); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+func (r *attributeRepo) Upsert(ctx context.Context, d *model.Definition) error {
+	query := `
+		INSERT INTO attribute_definitions (code, name, type, allowed_values, filterable)
+		VALUES (:code, :name, :type, :allowed_values, :filterable)
+		ON CONFLICT (code) DO UPDATE
+		SET name = EXCLUDED.name, type = EXCLUDED.type, allowed_values = EXCLUDED.allowed_values,
+			filterable = EXCLUDED.filterable, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	slog.Info("Executing query Upsert attribute", "query", query, "code", d.Code)
+	rows, err := r.db.NamedQueryContext(ctx, query, d)
+	if err != nil {
+		slog.Error("Failed to save attribute definition", "code", d.Code, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&d.CreatedAt, &d.UpdatedAt)
+	}
+	return nil
+}
+
+// Delete menghapus definisi atribut. Nilai yang sudah tersimpan di produk tidak ikut dihapus,
+// tetapi tidak lagi bisa difilter dan akan ditolak saat produk diperbarui.
+func (r *attributeRepo) Delete(ctx context.Context, code string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM attribute_definitions WHERE code = $1`, code)
+	if err != nil {
+		slog.Error("Failed to delete attribute definition", "code", code, "error", err)
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-fiber-api/internal/app/attribute/model"
+	"go-fiber-api/internal/app/attribute/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+)
+
+var codePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type Attribute interface {
+	GetAll(ctx context.Context) ([]model.Definition, error)
+	Save(ctx context.Context, code string, req *dto.AttributeDefinitionRequest) (*model.Definition, error)
+	Delete(ctx context.Context, code string) error
+	Validate(ctx context.Context, attrs types.Attributes) error
+	ParseFilters(ctx context.Context, raw map[string][]string) (map[string][]any, error)
+}
+
+type attributeService struct {
+	repo repository.Attribute
+}
+
+func NewAttributeService(repo repository.Attribute) Attribute {
+	return &attributeService{repo: repo}
+}
+
+func (s *attributeService) GetAll(ctx context.Context) ([]model.Definition, error) {
+	definitions, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if definitions == nil {
+		definitions = []model.Definition{}
+	}
+	return definitions, nil
+}
+
+// Save menambah atau mengubah definisi atribut. Mengubah tipe tidak memvalidasi ulang nilai
+// yang sudah tersimpan; nilai lama baru ditolak saat produknya diperbarui.
+func (s *attributeService) Save(ctx context.Context, code string, req *dto.AttributeDefinitionRequest) (*model.Definition, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !codePattern.MatchString(code) {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "Attribute code must start with a letter and contain only a-z, 0-9 and _", web.ErrValidation)
+	}
+
+	switch req.Type {
+	case model.TypeEnum:
+		if len(req.AllowedValues) == 0 {
+			return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Enum attributes require allowed_values", web.ErrValidation)
+		}
+	case model.TypeNumber, model.TypeBoolean:
+		if len(req.AllowedValues) > 0 {
+			return nil, web.NewHTTPError(http.StatusUnprocessableEntity,
+				fmt.Sprintf("allowed_values is not supported for %s attributes", req.Type), web.ErrValidation)
+		}
+	}
+
+	definition := &model.Definition{
+		Code:          code,
+		Name:          req.Name,
+		Type:          req.Type,
+		AllowedValues: req.AllowedValues,
+		Filterable:    req.Filterable,
+	}
+	if definition.AllowedValues == nil {
+		definition.AllowedValues = []string{}
+	}
+
+	if err := s.repo.Upsert(ctx, definition); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Attribute definition saved", "code", code, "type", req.Type)
+	return definition, nil
+}
+
+func (s *attributeService) Delete(ctx context.Context, code string) error {
+	deleted, err := s.repo.Delete(ctx, code)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return web.NewHTTPError(http.StatusNotFound, "Attribute not found", web.ErrNotFound)
+	}
+
+	slog.Info("Attribute definition deleted", "code", code)
+	return nil
+}
+
+// Validate memeriksa nilai atribut produk terhadap skema. Semua pelanggaran dikumpulkan
+// dalam satu error 422 agar client bisa memperbaiki sekaligus.
+func (s *attributeService) Validate(ctx context.Context, attrs types.Attributes) error {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	definitions, err := s.definitionsByCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	codes := make([]string, 0, len(attrs))
+	for code := range attrs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var problems []string
+	for _, code := range codes {
+		definition, ok := definitions[code]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s: unknown attribute", code))
+			continue
+		}
+		if err := checkValue(definition, attrs[code]); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", code, err))
+		}
+	}
+
+	if len(problems) > 0 {
+		return web.NewHTTPError(http.StatusUnprocessableEntity, "Invalid attributes: "+strings.Join(problems, "; "), web.ErrValidation)
+	}
+	return nil
+}
+
+// ParseFilters mengonversi filter attr.<code> dari query string sesuai tipe atribut.
+// Beberapa nilai untuk atribut yang sama (attr.color=red&attr.color=blue) digabung dengan OR.
+func (s *attributeService) ParseFilters(ctx context.Context, raw map[string][]string) (map[string][]any, error) {
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	definitions, err := s.definitionsByCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := make(map[string][]any, len(raw))
+	for code, values := range raw {
+		definition, ok := definitions[code]
+		if !ok {
+			return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unknown attribute %q", code), web.ErrValidation)
+		}
+		if !definition.Filterable {
+			return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Attribute %q is not filterable", code), web.ErrValidation)
+		}
+
+		for _, rawValue := range values {
+			value, err := parseFilterValue(definition, rawValue)
+			if err != nil {
+				return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid value for attribute %q: %v", code, err), web.ErrValidation)
+			}
+			filters[code] = append(filters[code], value)
+		}
+	}
+	return filters, nil
+}
+
+func (s *attributeService) definitionsByCode(ctx context.Context) (map[string]*model.Definition, error) {
+	definitions, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]*model.Definition, len(definitions))
+	for i := range definitions {
+		byCode[definitions[i].Code] = &definitions[i]
+	}
+	return byCode, nil
+}
+
+// checkValue memastikan nilai hasil decode JSON sesuai tipe atribut
+func checkValue(definition *model.Definition, value any) error {
+	switch definition.Type {
+	case model.TypeNumber:
+		if _, ok := value.(float64); !ok {
+			return errors.New("must be a number")
+		}
+	case model.TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return errors.New("must be a boolean")
+		}
+	case model.TypeString, model.TypeEnum:
+		text, ok := value.(string)
+		if !ok {
+			return errors.New("must be a string")
+		}
+		if !definition.Allows(text) {
+			return fmt.Errorf("must be one of %s", strings.Join(definition.AllowedValues, ", "))
+		}
+	}
+	return nil
+}
+
+// parseFilterValue mengonversi nilai query string ke tipe yang sama dengan yang tersimpan di JSONB
+func parseFilterValue(definition *model.Definition, raw string) (any, error) {
+	switch definition.Type {
+	case model.TypeNumber:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, errors.New("must be a number")
+		}
+		return number, nil
+	case model.TypeBoolean:
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, errors.New("must be a boolean")
+		}
+		return flag, nil
+	default:
+		if !definition.Allows(raw) {
+			return nil, fmt.Errorf("must be one of %s", strings.Join(definition.AllowedValues, ", "))
+		}
+		return raw, nil
+	}
+}
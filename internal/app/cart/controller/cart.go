@@ -1,52 +1,95 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"go-fiber-api/internal/app/cart/model"
 	"go-fiber-api/internal/app/cart/service"
+	currencyService "go-fiber-api/internal/app/currency/service"
+	taxModel "go-fiber-api/internal/app/tax/model"
 	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
 	"go-fiber-api/middleware"
 	"go-fiber-api/utils/web"
 )
 
 type cart struct {
-	service service.Cart
+	service         service.Cart
+	currencyService currencyService.Currency
 }
 
-func NewCartController(mux *http.ServeMux, cartService service.Cart) {
-	c := &cart{service: cartService}
-
-	// Cart routes
-	mux.Handle("GET /v1/cart", middleware.AuthMiddleware(http.HandlerFunc(c.GetAll)))
-	mux.Handle("GET /v1/cart/", middleware.AuthMiddleware(http.HandlerFunc(c.GetByID)))
-	mux.Handle("GET /v1/cart/total", middleware.AuthMiddleware(http.HandlerFunc(c.GetCartTotal)))
-	mux.Handle("POST /v1/cart", middleware.AuthMiddleware(http.HandlerFunc(c.Create)))
-	mux.Handle("POST /v1/cart/bulk", middleware.AuthMiddleware(http.HandlerFunc(c.CreateMany)))
-	mux.Handle("PUT /v1/cart/", middleware.AuthMiddleware(http.HandlerFunc(c.Update)))
-	mux.Handle("DELETE /v1/cart/", middleware.AuthMiddleware(http.HandlerFunc(c.Delete)))
-	mux.Handle("DELETE /v1/cart/bulk", middleware.AuthMiddleware(http.HandlerFunc(c.DeleteMany)))
+func NewCartController(mux *http.ServeMux, cartService service.Cart, currencyService currencyService.Currency) {
+	c := &cart{service: cartService, currencyService: currencyService}
+
+	// Cart routes. Tanpa login, cart diakses lewat guest cart token di header X-Cart-Token;
+	// token baru dikirim di header response saat item pertama ditambahkan.
+	mux.Handle("GET /v1/cart", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.GetAll)))
+	mux.Handle("GET /v1/cart/{id}", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.GetByID)))
+	mux.Handle("GET /v1/cart/total", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.GetCartTotal)))
+	mux.Handle("GET /v1/cart/summary", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.GetCartSummary)))
+	mux.Handle("POST /v1/cart", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.Create)))
+	mux.Handle("POST /v1/cart/bulk", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.CreateMany)))
+	mux.Handle("POST /v1/cart/validate", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.Validate)))
+	mux.Handle("PUT /v1/cart/{id}", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.Update)))
+	mux.Handle("DELETE /v1/cart/{id}", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.Delete)))
+	mux.Handle("DELETE /v1/cart/bulk", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.DeleteMany)))
+	mux.Handle("POST /v1/cart/{id}/save-for-later", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.SaveForLater)))
+	mux.Handle("POST /v1/cart/{id}/move-to-cart", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.MoveToCart)))
+	mux.Handle("POST /v1/cart/merge", middleware.AuthMiddleware(http.HandlerFunc(c.Merge)))
+	mux.Handle("POST /v1/cart/coupon", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.ApplyCoupon)))
+	mux.Handle("DELETE /v1/cart/coupon", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.RemoveCoupon)))
+	mux.Handle("POST /v1/cart/coupon/redeem", middleware.AuthMiddleware(http.HandlerFunc(c.RedeemCoupon)))
+	mux.Handle("POST /v1/cart/shipping-quote", middleware.OptionalAuthMiddleware(http.HandlerFunc(c.QuoteShipping)))
+
+	mux.HandleFunc("GET /v1/admin/carts/abandonment-report", middleware.ValidateRole(types.RoleAdmin)(c.AbandonmentReport))
 }
 
-// validateUserAccess memvalidasi apakah user memiliki akses ke cart item tertentu
-func (c *cart) validateUserAccess(r *http.Request, cartID uint) error {
-	userID := web.GetUserID(r)
-	if userID == 0 {
-		return web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication)
+// CartTokenHeader membawa cart token guest cart pada request dan response
+const CartTokenHeader = "X-Cart-Token"
+
+// owner menentukan pemilik cart dari request: user yang login, atau guest cart dari header
+// X-Cart-Token. Jika request tidak membawa keduanya dan start bernilai true, guest cart baru
+// dibuat dan token-nya dikirim lewat header response X-Cart-Token.
+func (c *cart) owner(w http.ResponseWriter, r *http.Request, start bool) (model.Owner, error) {
+	if userID := web.GetUserID(r); userID != 0 {
+		return model.UserOwner(userID), nil
+	}
+	if token := r.Header.Get(CartTokenHeader); token != "" {
+		return c.service.ResolveGuestCart(r.Context(), token)
+	}
+	if !start {
+		return model.Owner{}, web.NewHTTPError(http.StatusUnauthorized, "Login or a cart token is required", web.ErrAuthentication)
 	}
 
-	// Cek apakah cart item milik user yang sedang login
+	owner, token, err := c.service.StartGuestCart(r.Context())
+	if err != nil {
+		return model.Owner{}, err
+	}
+	w.Header().Set(CartTokenHeader, token)
+	return owner, nil
+}
+
+// validateUserAccess memvalidasi apakah pemilik cart (user atau guest) memiliki akses ke cart item tertentu
+func (c *cart) validateUserAccess(w http.ResponseWriter, r *http.Request, cartID uint) error {
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		return err
+	}
+
+	// Cek apakah cart item milik pemilik cart pada request ini
 	item, err := c.service.GetByID(r.Context(), cartID)
 	if err != nil {
 		return web.NewHTTPError(http.StatusNotFound, "Cart item not found", web.ErrNotFound)
 	}
 
-	if item.UserID != userID {
+	if !owner.Owns(item) {
 		return web.NewHTTPError(http.StatusForbidden, "Access denied to this cart item", web.ErrForbidden)
 	}
 
@@ -54,17 +97,24 @@ func (c *cart) validateUserAccess(r *http.Request, cartID uint) error {
 }
 
 func (c *cart) GetAll(w http.ResponseWriter, r *http.Request) {
-	userID := web.GetUserID(r)
-	if userID == 0 {
-		slog.Warn("Unauthorized GetAll request: user ID not found in context")
-		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("Fetching cart items", "owner", owner)
+	items, err := c.service.GetByOwner(r.Context(), owner)
+	if err != nil {
+		slog.Error("Failed to fetch cart items", "owner", owner, "error", err)
+		web.Err(w, err)
 		return
 	}
 
-	slog.Info("Fetching cart items", "user_id", userID)
-	items, err := c.service.GetByUserID(r.Context(), userID)
+	// Baris saved for later dikirim terpisah, sudah di-reprice dan dicek stoknya
+	saved, err := c.service.GetSavedForLater(r.Context(), owner)
 	if err != nil {
-		slog.Error("Failed to fetch cart items", "user_id", userID, "error", err)
+		slog.Error("Failed to fetch saved cart items", "owner", owner, "error", err)
 		web.Err(w, err)
 		return
 	}
@@ -73,31 +123,55 @@ func (c *cart) GetAll(w http.ResponseWriter, r *http.Request) {
 	if items == nil {
 		items = []model.CartItem{}
 	}
+	if saved == nil {
+		saved = []model.CartItem{}
+	}
 
-	web.OK(w, http.StatusOK, map[string]interface{}{
-		"items": items,
-		"count": len(items),
-	})
-}
+	response := map[string]interface{}{
+		"items":           items,
+		"count":           len(items),
+		"saved_for_later": saved,
+	}
 
-func (c *cart) GetByID(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/v1/cart/")
-	if idStr == "" || idStr == "total" { // Hindari konflik dengan endpoint lain
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Cart ID is required", web.ErrValidation))
-		return
+	// Konversi harga jika client meminta mata uang lain
+	if target := web.RequestedCurrency(r); target != "" {
+		converter, err := c.currencyService.NewConverter(r.Context(), target)
+		if err != nil {
+			web.Err(w, err)
+			return
+		}
+		for _, list := range [][]model.CartItem{items, saved} {
+			for i := range list {
+				price, _, err := converter.Convert(r.Context(), list[i].LinePrice())
+				if err != nil {
+					web.Err(w, err)
+					return
+				}
+				unitPrice, _, err := converter.Convert(r.Context(), list[i].CurrentPrice())
+				if err != nil {
+					web.Err(w, err)
+					return
+				}
+				list[i].Price, list[i].Currency = price.Amount, price.Currency
+				list[i].CurrentUnitPrice, list[i].CurrentCurrency = &unitPrice.Amount, &unitPrice.Currency
+			}
+		}
+		response["currency"] = converter.Currency()
+		response["exchange_rates"] = converter.RatesUsed()
 	}
 
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	web.OK(w, http.StatusOK, response)
+}
+
+func (c *cart) GetByID(w http.ResponseWriter, r *http.Request) {
+	cartID, err := web.PathID(r, "id", "cart")
 	if err != nil {
-		slog.Warn("Invalid cart ID format", "cart_id", idStr)
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid cart ID format", web.ErrValidation))
+		web.Err(w, err)
 		return
 	}
 
-	cartID := uint(id)
-
 	// Validasi akses user
-	if err := c.validateUserAccess(r, cartID); err != nil {
+	if err := c.validateUserAccess(w, r, cartID); err != nil {
 		web.Err(w, err)
 		return
 	}
@@ -114,38 +188,326 @@ func (c *cart) GetByID(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *cart) GetCartTotal(w http.ResponseWriter, r *http.Request) {
-	userID := web.GetUserID(r)
-	if userID == 0 {
-		slog.Warn("Unauthorized GetCartTotal request: user ID not found")
-		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
 		return
 	}
 
-	slog.Info("Calculating cart total", "user_id", userID)
-	total, err := c.service.GetCartTotal(r.Context(), userID)
+	slog.Info("Calculating cart total", "owner", owner)
+	total, err := c.service.GetCartTotal(r.Context(), owner, taxRegion(r))
 	if err != nil {
-		slog.Error("Failed to calculate cart total", "user_id", userID, "error", err)
+		slog.Error("Failed to calculate cart total", "owner", owner, "error", err)
 		web.Err(w, err)
 		return
 	}
 
-	web.OK(w, http.StatusOK, map[string]interface{}{
-		"user_id": userID,
-		"total":   total,
-	})
+	c.writeTotal(w, r, total)
 }
 
-func (c *cart) Create(w http.ResponseWriter, r *http.Request) {
-	userID := web.GetUserID(r)
-	if userID == 0 {
-		slog.Warn("Unauthorized Create request: user ID not found in context")
-		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
+// taxRegion membaca wilayah pajak dari ?country=ID&region=JK; kosong berarti negara default
+func taxRegion(r *http.Request) taxModel.Region {
+	query := r.URL.Query()
+	return taxModel.Region{
+		Country: strings.ToUpper(query.Get("country")),
+		Region:  strings.ToUpper(query.Get("region")),
+	}
+}
+
+// writeTotal menulis total cart. Jika client meminta mata uang lain, harga per baris, diskon dan
+// pajak dikonversi satu per satu, lalu subtotal, total diskon, total pajak dan total_amount
+// dijumlahkan ulang dari nilai yang sudah dikonversi agar rinciannya tetap cocok dengan totalnya.
+func (c *cart) writeTotal(w http.ResponseWriter, r *http.Request, total *dto.CartTotalResponse) {
+	if target := web.RequestedCurrency(r); target != "" {
+		converter, err := c.currencyService.NewConverter(r.Context(), target)
+		if err != nil {
+			web.Err(w, err)
+			return
+		}
+		if err := convertTotal(r.Context(), converter, total); err != nil {
+			web.Err(w, err)
+			return
+		}
+		total.Currency = converter.Currency()
+		total.ExchangeRates = converter.RatesUsed()
+	}
+
+	web.OK(w, http.StatusOK, total)
+}
+
+func convertTotal(ctx context.Context, converter *currencyService.Converter, total *dto.CartTotalResponse) error {
+	var err error
+	subtotal := types.NewMoney(0, converter.Currency())
+	for i := range total.Items {
+		item := &total.Items[i]
+		if item.Price, _, err = converter.Convert(ctx, item.Price); err != nil {
+			return err
+		}
+		if item.Subtotal, _, err = converter.Convert(ctx, item.Subtotal); err != nil {
+			return err
+		}
+		if subtotal, err = subtotal.Add(item.Subtotal); err != nil {
+			return err
+		}
+		if item.Tax != nil {
+			if item.Tax.Amount, _, err = converter.Convert(ctx, item.Tax.Amount); err != nil {
+				return err
+			}
+		}
+	}
+
+	discountTotal := types.NewMoney(0, converter.Currency())
+	for i := range total.Discounts {
+		discount := &total.Discounts[i]
+		if discount.Amount, _, err = converter.Convert(ctx, discount.Amount); err != nil {
+			return err
+		}
+		if discountTotal, err = discountTotal.Add(discount.Amount); err != nil {
+			return err
+		}
+	}
+	discountTotal.Amount = min(discountTotal.Amount, subtotal.Amount)
+
+	// Pajak per jenis adalah jumlah pajak baris dengan jenis yang sama, seperti pada perhitungan awal
+	taxTotal := types.NewMoney(0, converter.Currency())
+	exclusive := types.NewMoney(0, converter.Currency())
+	for i := range total.Taxes {
+		tax := &total.Taxes[i]
+		if tax.TaxableAmount, _, err = converter.Convert(ctx, tax.TaxableAmount); err != nil {
+			return err
+		}
+		tax.Amount = types.NewMoney(0, converter.Currency())
+		for _, item := range total.Items {
+			if item.Tax != nil && item.Tax.Name == tax.Name && item.Tax.Rate == tax.Rate && item.Tax.Inclusive == tax.Inclusive {
+				if tax.Amount, err = tax.Amount.Add(item.Tax.Amount); err != nil {
+					return err
+				}
+			}
+		}
+		if taxTotal, err = taxTotal.Add(tax.Amount); err != nil {
+			return err
+		}
+		if !tax.Inclusive {
+			if exclusive, err = exclusive.Add(tax.Amount); err != nil {
+				return err
+			}
+		}
+	}
+
+	amount, err := subtotal.Sub(discountTotal)
+	if err != nil {
+		return err
+	}
+	if amount, err = amount.Add(exclusive); err != nil {
+		return err
+	}
+
+	total.Subtotal = subtotal
+	total.DiscountTotal = discountTotal
+	total.TaxTotal = taxTotal
+	total.TotalAmount = amount
+	return nil
+}
+
+// GetCartSummary mengembalikan jumlah item dan total cart untuk badge di header
+func (c *cart) GetCartSummary(w http.ResponseWriter, r *http.Request) {
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	summary, err := c.service.GetCartSummary(r.Context(), owner, taxRegion(r))
+	if err != nil {
+		slog.Error("Failed to calculate cart summary", "owner", owner, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	if target := web.RequestedCurrency(r); target != "" {
+		converter, err := c.currencyService.NewConverter(r.Context(), target)
+		if err != nil {
+			web.Err(w, err)
+			return
+		}
+		amounts := []*types.Money{&summary.DiscountTotal, &summary.TaxTotal, &summary.TotalAmount}
+		if summary.FreeShipping != nil {
+			amounts = append(amounts, &summary.FreeShipping.Threshold, &summary.FreeShipping.Remaining)
+		}
+		for _, amount := range amounts {
+			if *amount, _, err = converter.Convert(r.Context(), *amount); err != nil {
+				web.Err(w, err)
+				return
+			}
+		}
+		summary.Currency = converter.Currency()
+		summary.ExchangeRates = converter.RatesUsed()
+	}
+
+	web.OK(w, http.StatusOK, summary)
+}
+
+// Validate melaporkan perubahan harga dan stok di cart sebelum checkout
+func (c *cart) Validate(w http.ResponseWriter, r *http.Request) {
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	result, err := c.service.Validate(r.Context(), owner)
+	if err != nil {
+		slog.Error("Failed to validate cart", "owner", owner, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, result)
+}
+
+// ApplyCoupon memasang kupon ke cart dan mengembalikan total cart beserta diskonnya
+func (c *cart) ApplyCoupon(w http.ResponseWriter, r *http.Request) {
+	var req dto.CartCouponRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("Failed to decode ApplyCoupon request body", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid JSON format", web.ErrValidation))
+		return
+	}
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := c.service.ApplyCoupon(r.Context(), owner, req.Code); err != nil {
+		slog.Error("Failed to apply coupon", "owner", owner, "code", req.Code, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	total, err := c.service.GetCartTotal(r.Context(), owner, taxRegion(r))
+	if err != nil {
+		slog.Error("Failed to calculate cart total", "owner", owner, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	c.writeTotal(w, r, total)
+}
+
+// RedeemCoupon menebus kupon yang terpasang di cart user saat checkout dan mengembalikan total
+// cart beserta diskonnya. Hanya untuk user yang login karena pemakaian kupon dicatat per user.
+func (c *cart) RedeemCoupon(w http.ResponseWriter, r *http.Request) {
+	owner := model.UserOwner(web.GetUserID(r))
+
+	total, err := c.service.RedeemCoupon(r.Context(), owner, taxRegion(r))
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	c.writeTotal(w, r, total)
+}
+
+// QuoteShipping mengembalikan metode pengiriman yang tersedia untuk isi cart ke alamat tujuan
+func (c *cart) QuoteShipping(w http.ResponseWriter, r *http.Request) {
+	var req dto.ShippingQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("Failed to decode QuoteShipping request body", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid JSON format", web.ErrValidation))
+		return
+	}
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	quote, err := c.service.QuoteShipping(r.Context(), owner, &req)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if target := web.RequestedCurrency(r); target != "" {
+		converter, err := c.currencyService.NewConverter(r.Context(), target)
+		if err != nil {
+			web.Err(w, err)
+			return
+		}
+		amounts := make([]*types.Money, 0, len(quote.Methods)+2)
+		for i := range quote.Methods {
+			amounts = append(amounts, &quote.Methods[i].Price)
+		}
+		if quote.FreeShipping != nil {
+			amounts = append(amounts, &quote.FreeShipping.Threshold, &quote.FreeShipping.Remaining)
+		}
+		for _, amount := range amounts {
+			if *amount, _, err = converter.Convert(r.Context(), *amount); err != nil {
+				web.Err(w, err)
+				return
+			}
+		}
+		quote.ExchangeRates = converter.RatesUsed()
+	}
+
+	web.OK(w, http.StatusOK, quote)
+}
+
+// AbandonmentReport mengembalikan laporan abandoned cart untuk ?days=N hari terakhir (default 7)
+func (c *cart) AbandonmentReport(w http.ResponseWriter, r *http.Request) {
+	days := 7
+	if raw := r.URL.Query().Get("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > 365 {
+			web.Err(w, web.NewHTTPError(http.StatusBadRequest, "days must be between 1 and 365", web.ErrValidation))
+			return
+		}
+		days = parsed
+	}
+
+	to := time.Now()
+	report, err := c.service.AbandonmentReport(r.Context(), to.AddDate(0, 0, -days), to)
+	if err != nil {
+		slog.Error("Failed to build abandonment report", "days", days, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, report)
+}
+
+// RemoveCoupon melepas kupon dari cart
+func (c *cart) RemoveCoupon(w http.ResponseWriter, r *http.Request) {
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := c.service.RemoveCoupon(r.Context(), owner); err != nil {
+		slog.Error("Failed to remove coupon", "owner", owner, "error", err)
+		web.Err(w, err)
 		return
 	}
 
+	web.OKNoContent(w, http.StatusOK)
+}
+
+func (c *cart) Create(w http.ResponseWriter, r *http.Request) {
 	var input dto.CartItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
-		slog.Warn("Failed to decode Create request body", "user_id", userID, "error", err)
+		slog.Warn("Failed to decode Create request body", "error", err)
 		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid JSON format", web.ErrValidation))
 		return
 	}
@@ -161,15 +523,22 @@ func (c *cart) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	slog.Info("Creating new cart item", "user_id", userID, "product_id", input.ProductID, "quantity", input.Quantity)
-	item, err := c.service.Create(r.Context(), userID, &input)
+	// Guest cart baru dibuat setelah input valid agar request yang ditolak tidak meninggalkan cart kosong
+	owner, err := c.owner(w, r, true)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("Creating new cart item", "owner", owner, "product_id", input.ProductID, "quantity", input.Quantity)
+	item, err := c.service.Create(r.Context(), owner, &input)
 	if err != nil {
-		slog.Error("Failed to create cart item", "user_id", userID, "product_id", input.ProductID, "error", err)
+		slog.Error("Failed to create cart item", "owner", owner, "product_id", input.ProductID, "error", err)
 		web.Err(w, err)
 		return
 	}
 
-	slog.Info("Cart item created successfully", "user_id", userID, "cart_id", item.ID, "product_id", item.ProductID)
+	slog.Info("Cart item created successfully", "owner", owner, "cart_id", item.ID, "product_id", item.ProductID)
 	web.OK(w, http.StatusCreated, map[string]interface{}{
 		"message": "Item added to cart successfully",
 		"item":    item,
@@ -177,16 +546,9 @@ func (c *cart) Create(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *cart) CreateMany(w http.ResponseWriter, r *http.Request) {
-	userID := web.GetUserID(r)
-	if userID == 0 {
-		slog.Warn("Unauthorized CreateMany request: user ID not found")
-		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
-		return
-	}
-
 	var inputs []dto.CartItemRequest
 	if err := json.NewDecoder(r.Body).Decode(&inputs); err != nil {
-		slog.Warn("Failed to decode CreateMany request body", "user_id", userID, "error", err)
+		slog.Warn("Failed to decode CreateMany request body", "error", err)
 		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid JSON format", web.ErrValidation))
 		return
 	}
@@ -215,15 +577,37 @@ func (c *cart) CreateMany(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	slog.Info("Creating multiple cart items", "user_id", userID, "count", len(inputs))
-	items, err := c.service.CreateMany(r.Context(), userID, inputs)
+	atomic, err := bulkAtomic(r)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	owner, err := c.owner(w, r, true)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("Creating multiple cart items", "owner", owner, "count", len(inputs), "atomic", atomic)
+	results, err := c.service.CreateMany(r.Context(), owner, inputs, atomic)
 	if err != nil {
-		slog.Error("Failed to create multiple cart items", "user_id", userID, "count", len(inputs), "error", err)
+		slog.Error("Failed to create multiple cart items", "owner", owner, "count", len(inputs), "error", err)
 		web.Err(w, err)
 		return
 	}
 
-	slog.Info("Multiple cart items created successfully", "user_id", userID, "created_count", len(items))
+	if failed := model.CountFailed(results); failed > 0 {
+		writeBulkPartial(w, results, failed)
+		return
+	}
+
+	items := make([]*model.CartItem, 0, len(results))
+	for _, result := range results {
+		items = append(items, result.Item)
+	}
+
+	slog.Info("Multiple cart items created successfully", "owner", owner, "created_count", len(items))
 	web.OK(w, http.StatusCreated, map[string]interface{}{
 		"message": fmt.Sprintf("%d items added to cart successfully", len(items)),
 		"items":   items,
@@ -232,23 +616,14 @@ func (c *cart) CreateMany(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *cart) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/v1/cart/")
-	if idStr == "" {
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Cart ID is required", web.ErrValidation))
-		return
-	}
-
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	cartID, err := web.PathID(r, "id", "cart")
 	if err != nil {
-		slog.Warn("Invalid cart ID for update", "cart_id", idStr)
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid cart ID format", web.ErrValidation))
+		web.Err(w, err)
 		return
 	}
 
-	cartID := uint(id)
-
 	// Validasi akses user
-	if err := c.validateUserAccess(r, cartID); err != nil {
+	if err := c.validateUserAccess(w, r, cartID); err != nil {
 		web.Err(w, err)
 		return
 	}
@@ -287,23 +662,14 @@ func (c *cart) Update(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *cart) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := strings.TrimPrefix(r.URL.Path, "/v1/cart/")
-	if idStr == "" {
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Cart ID is required", web.ErrValidation))
-		return
-	}
-
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	cartID, err := web.PathID(r, "id", "cart")
 	if err != nil {
-		slog.Warn("Invalid cart ID for deletion", "cart_id", idStr)
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid cart ID format", web.ErrValidation))
+		web.Err(w, err)
 		return
 	}
 
-	cartID := uint(id)
-
 	// Validasi akses user
-	if err := c.validateUserAccess(r, cartID); err != nil {
+	if err := c.validateUserAccess(w, r, cartID); err != nil {
 		web.Err(w, err)
 		return
 	}
@@ -322,10 +688,9 @@ func (c *cart) Delete(w http.ResponseWriter, r *http.Request) {
 }
 
 func (c *cart) DeleteMany(w http.ResponseWriter, r *http.Request) {
-	userID := web.GetUserID(r)
-	if userID == 0 {
-		slog.Warn("Unauthorized DeleteMany request: user ID not found")
-		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
+	owner, err := c.owner(w, r, false)
+	if err != nil {
+		web.Err(w, err)
 		return
 	}
 
@@ -334,7 +699,7 @@ func (c *cart) DeleteMany(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		slog.Warn("Failed to decode DeleteMany request", "user_id", userID, "error", err)
+		slog.Warn("Failed to decode DeleteMany request", "owner", owner, "error", err)
 		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid JSON format", web.ErrValidation))
 		return
 	}
@@ -349,34 +714,131 @@ func (c *cart) DeleteMany(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validasi bahwa semua cart items milik user yang sedang login
-	for _, cartID := range body.IDs {
-		item, err := c.service.GetByID(r.Context(), cartID)
-		if err != nil {
-			slog.Error("Cart item not found for bulk delete", "cart_id", cartID, "user_id", userID)
-			web.Err(w, web.NewHTTPError(http.StatusNotFound,
-				fmt.Sprintf("Cart item %d not found", cartID), web.ErrNotFound))
-			return
-		}
-
-		if item.UserID != userID {
-			slog.Warn("Access denied for bulk delete", "cart_id", cartID, "owner_id", item.UserID, "requester_id", userID)
-			web.Err(w, web.NewHTTPError(http.StatusForbidden,
-				fmt.Sprintf("Access denied to cart item %d", cartID), web.ErrForbidden))
-			return
-		}
+	atomic, err := bulkAtomic(r)
+	if err != nil {
+		web.Err(w, err)
+		return
 	}
 
-	slog.Info("Deleting multiple cart items", "user_id", userID, "ids", body.IDs, "count", len(body.IDs))
-	if err := c.service.DeleteMany(r.Context(), body.IDs); err != nil {
-		slog.Error("Failed to delete multiple cart items", "user_id", userID, "ids", body.IDs, "error", err)
+	// Kepemilikan setiap cart item divalidasi oleh service di dalam transaksi
+	slog.Info("Deleting multiple cart items", "owner", owner, "ids", body.IDs, "count", len(body.IDs), "atomic", atomic)
+	results, err := c.service.DeleteMany(r.Context(), owner, body.IDs, atomic)
+	if err != nil {
+		slog.Error("Failed to delete multiple cart items", "owner", owner, "ids", body.IDs, "error", err)
 		web.Err(w, err)
 		return
 	}
 
-	slog.Info("Multiple cart items deleted successfully", "user_id", userID, "count", len(body.IDs))
+	if failed := model.CountFailed(results); failed > 0 {
+		writeBulkPartial(w, results, failed)
+		return
+	}
+
+	slog.Info("Multiple cart items deleted successfully", "owner", owner, "count", len(body.IDs))
 	web.OK(w, http.StatusOK, map[string]interface{}{
 		"message": fmt.Sprintf("%d cart items deleted successfully", len(body.IDs)),
 		"count":   len(body.IDs),
 	})
 }
+
+// SaveForLater memindahkan baris dari cart ke daftar saved for later
+func (c *cart) SaveForLater(w http.ResponseWriter, r *http.Request) {
+	cartID, ok := c.accessibleItemID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := c.service.SaveForLater(r.Context(), cartID)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, map[string]interface{}{
+		"message": "Cart item saved for later",
+		"item":    item,
+	})
+}
+
+// MoveToCart memindahkan baris saved for later kembali ke cart. Stok direservasi ulang, sehingga
+// bisa gagal jika stok tidak lagi cukup.
+func (c *cart) MoveToCart(w http.ResponseWriter, r *http.Request) {
+	cartID, ok := c.accessibleItemID(w, r)
+	if !ok {
+		return
+	}
+
+	item, err := c.service.MoveToCart(r.Context(), cartID)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, map[string]interface{}{
+		"message": "Cart item moved to cart",
+		"item":    item,
+	})
+}
+
+// accessibleItemID membaca {id} dari path dan memastikan baris tersebut milik pemilik cart pada request
+func (c *cart) accessibleItemID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := web.PathID(r, "id", "cart")
+	if err == nil {
+		err = c.validateUserAccess(w, r, id)
+	}
+	if err != nil {
+		web.Err(w, err)
+		return 0, false
+	}
+	return id, true
+}
+
+// bulkAtomic membaca query ?atomic= pada endpoint bulk. Default true: semua item berhasil
+// atau tidak ada perubahan sama sekali.
+func bulkAtomic(r *http.Request) (bool, error) {
+	raw := r.URL.Query().Get("atomic")
+	if raw == "" {
+		return true, nil
+	}
+	atomic, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, web.NewHTTPError(http.StatusBadRequest, "atomic must be true or false", web.ErrValidation)
+	}
+	return atomic, nil
+}
+
+// writeBulkPartial menulis 207 beserta hasil per item saat sebagian item non-atomic gagal
+func writeBulkPartial(w http.ResponseWriter, results []model.BulkItemResult, failed int) {
+	web.Err(w, web.NewHTTPError(http.StatusMultiStatus,
+		fmt.Sprintf("%d of %d items failed", failed, len(results)), web.ErrBulkOperationFailed).
+		WithData(map[string]interface{}{
+			"results":   results,
+			"succeeded": len(results) - failed,
+			"failed":    failed,
+		}))
+}
+
+// Merge menggabungkan guest cart dari header X-Cart-Token ke cart user yang login.
+// Login dengan header yang sama sudah melakukannya otomatis; endpoint ini untuk alur login lain.
+func (c *cart) Merge(w http.ResponseWriter, r *http.Request) {
+	userID := web.GetUserID(r)
+	if userID == 0 {
+		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
+		return
+	}
+
+	token := r.Header.Get(CartTokenHeader)
+	if token == "" {
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "X-Cart-Token header is required", web.ErrValidation))
+		return
+	}
+
+	result, err := c.service.MergeGuestCart(r.Context(), userID, token)
+	if err != nil {
+		slog.Error("Failed to merge guest cart", "user_id", userID, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, result)
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+// Abandonment adalah catatan cart yang tidak disentuh melewati batas waktu. Catatan tertutup saat
+// pemilik kembali mengubah cart (recovered), cart dikosongkan atau dihapus tanpa aktivitas baru
+// (closed), atau cart dihapus karena masa retensinya habis (purged).
+type Abandonment struct {
+	ID             uint       `db:"id"`
+	CreatedAt      time.Time  `db:"created_at"`
+	UserID         *uint      `db:"user_id"`
+	GuestCartID    *uint      `db:"guest_cart_id"`
+	LastActivityAt time.Time  `db:"last_activity_at"`
+	ItemCount      int        `db:"item_count"` // Jumlah unit saat terdeteksi
+	TotalAmount    int64      `db:"total_amount"`
+	Currency       string     `db:"currency"`
+	NotifiedAt     *time.Time `db:"notified_at"`
+	RecoveredAt    *time.Time `db:"recovered_at"`
+	ClosedAt       *time.Time `db:"closed_at"`
+	PurgedAt       *time.Time `db:"purged_at"`
+
+	Email string `db:"email"` // Dari join ke users, hanya terisi saat mengambil reminder
+}
+
+// Total mengembalikan nilai cart saat terdeteksi abandoned
+func (a Abandonment) Total() types.Money {
+	return types.NewMoney(a.TotalAmount, a.Currency)
+}
+
+// SweepResult adalah hasil satu putaran job abandoned cart
+type SweepResult struct {
+	Recovered   int64 // Catatan yang ditutup karena pemilik kembali ke cart
+	Closed      int64 // Catatan yang ditutup karena cart-nya kosong atau sudah dihapus
+	Abandoned   int64 // Cart yang baru terdeteksi abandoned
+	Reminded    int64
+	PurgedCarts int64
+	PurgedItems int64 // Baris yang dihapus atau dipindahkan ke arsip
+}
+
+// AbandonmentStats adalah angka mentah untuk laporan abandonment dalam satu periode
+type AbandonmentStats struct {
+	Active                 int64 `db:"active"` // Cart dengan aktivitas dalam periode
+	Abandoned              int64 `db:"abandoned"`
+	Reminded               int64 `db:"reminded"`
+	Recovered              int64 `db:"recovered"`
+	RecoveredAfterReminder int64 `db:"recovered_after_reminder"`
+	Closed                 int64 `db:"closed"`
+	Purged                 int64 `db:"purged"`
+}
@@ -0,0 +1,27 @@
+package model
+
+// BulkItemResult adalah hasil satu item pada operasi bulk cart
+type BulkItemResult struct {
+	Index   int            `json:"index"`
+	ID      uint           `json:"id,omitempty"`
+	Success bool           `json:"success"`
+	Item    *CartItem      `json:"item,omitempty"`
+	Error   *BulkItemError `json:"error,omitempty"`
+}
+
+// BulkItemError menjelaskan kenapa satu item bulk gagal
+type BulkItemError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// CountFailed menghitung item yang gagal
+func CountFailed(results []BulkItemResult) int {
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	return failed
+}
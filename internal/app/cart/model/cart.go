@@ -1,15 +1,130 @@
 package model
 
+import (
+	"encoding/json"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+// Kode masalah pada hasil validasi cart
+const (
+	IssuePriceChanged       = "price_changed"
+	IssueProductUnavailable = "product_unavailable"
+	IssueInsufficientStock  = "insufficient_stock"
+	IssueOutOfStock         = "out_of_stock"
+)
+
 type CartItem struct {
-	ID        uint    `db:"id" json:"id"`
-	UserID    uint    `db:"user_id" json:"user_id"`
-	ProductID uint    `db:"product_id" json:"product_id"`
-	Name      string  `db:"name" json:"name"`
-	Quantity  int     `db:"quantity" json:"quantity"`
-	Price     float64 `db:"price" json:"price"`
-	Color     string  `db:"color" json:"color"`
-	Size      string  `db:"size" json:"size"`
-	CreatedAt string  `db:"created_at" json:"created_at"`
-	UpdatedAt string  `db:"updated_at" json:"updated_at"`
-	DeletedAt *string `db:"deleted_at" json:"deleted_at,omitempty"`
+	ID          uint    `db:"id" json:"id"`
+	UserID      *uint   `db:"user_id" json:"user_id,omitempty"` // Nil untuk baris guest cart
+	GuestCartID *uint   `db:"guest_cart_id" json:"-"`
+	ProductID   uint    `db:"product_id" json:"product_id"`
+	Name        string  `db:"name" json:"name"`
+	Quantity    int     `db:"quantity" json:"quantity"`
+	Price       int64   `db:"price" json:"-"`      // Total harga baris dalam minor unit
+	Currency    string  `db:"currency" json:"-"`   // Kode ISO 4217
+	UnitPrice   int64   `db:"unit_price" json:"-"` // Snapshot harga satuan saat item ditambahkan atau diubah
+	Color       string  `db:"color" json:"color"`
+	Size        string  `db:"size" json:"size"`
+	CreatedAt   string  `db:"created_at" json:"created_at"`
+	UpdatedAt   string  `db:"updated_at" json:"updated_at"`
+	DeletedAt   *string `db:"deleted_at" json:"deleted_at,omitempty"`
+
+	// Baris saved for later tidak ikut total dan checkout dan tidak memegang reservasi stok
+	SavedForLater bool `db:"saved_for_later" json:"saved_for_later"`
+
+	// Harga satuan produk saat ini dari join ke products; nil jika produk sudah tidak ada
+	CurrentUnitPrice *int64  `db:"current_unit_price" json:"-"`
+	CurrentCurrency  *string `db:"current_currency" json:"-"`
+	ProductType      *string `db:"product_type" json:"-"`
+	ProductCategory  *string `db:"product_category" json:"-"` // Untuk eligibility kupon
+	ProductTaxClass  *string `db:"product_tax_class" json:"-"`
+
+	// Berat dan dimensi kemasan produk untuk ongkos kirim
+	ProductWeightGrams *int `db:"product_weight_grams" json:"-"`
+	ProductLengthMM    *int `db:"product_length_mm" json:"-"`
+	ProductWidthMM     *int `db:"product_width_mm" json:"-"`
+	ProductHeightMM    *int `db:"product_height_mm" json:"-"`
+
+	// Terisi oleh Reprice
+	PriceChanged bool        `db:"-" json:"price_changed"`
+	added        types.Money // Snapshot sebelum Currency ditimpa mata uang produk saat ini
+
+	// Hasil pengecekan stok untuk baris saved for later saat cart dibaca
+	AvailableQuantity *int     `db:"-" json:"available_quantity,omitempty"`
+	Issues            []string `db:"-" json:"issues,omitempty"`
+}
+
+// SplitSaved memisahkan baris aktif dari baris saved for later dengan urutan tetap
+func SplitSaved(items []CartItem) (active, saved []CartItem) {
+	for _, item := range items {
+		if item.SavedForLater {
+			saved = append(saved, item)
+		} else {
+			active = append(active, item)
+		}
+	}
+	return active, saved
+}
+
+// FindLine mencari baris dengan produk, warna dan ukuran yang sama
+func FindLine(items []CartItem, productID uint, color, size string) *CartItem {
+	for i := range items {
+		if items[i].ProductID == productID && items[i].Color == color && items[i].Size == size {
+			return &items[i]
+		}
+	}
+	return nil
+}
+
+// IsBundle bernilai true jika baris ini berisi produk bundle
+func (c CartItem) IsBundle() bool {
+	return c.ProductType != nil && *c.ProductType == "bundle"
+}
+
+// LinePrice mengembalikan total harga baris sebagai Money
+func (c CartItem) LinePrice() types.Money {
+	return types.NewMoney(c.Price, c.Currency)
+}
+
+// AddedUnitPrice mengembalikan harga satuan saat item ditambahkan atau terakhir diubah
+func (c CartItem) AddedUnitPrice() types.Money {
+	if c.added.Currency != "" {
+		return c.added
+	}
+	return types.NewMoney(c.UnitPrice, c.Currency)
+}
+
+// CurrentPrice mengembalikan harga satuan produk saat ini, atau snapshot jika belum di-reprice
+func (c CartItem) CurrentPrice() types.Money {
+	if c.CurrentUnitPrice == nil || c.CurrentCurrency == nil {
+		return c.AddedUnitPrice()
+	}
+	return types.NewMoney(*c.CurrentUnitPrice, *c.CurrentCurrency)
+}
+
+// Reprice menghitung ulang harga baris dari harga produk saat ini. Snapshot UnitPrice tidak diubah,
+// sehingga PriceChanged tetap true sampai baris diperbarui. Baris yang produknya sudah tidak ada
+// tetap memakai harga terakhir.
+func (c *CartItem) Reprice() {
+	c.added = types.NewMoney(c.UnitPrice, c.Currency)
+	if c.CurrentUnitPrice == nil || c.CurrentCurrency == nil {
+		return
+	}
+
+	current := c.CurrentPrice()
+	c.PriceChanged = current.Amount != c.added.Amount || current.Currency != c.added.Currency
+	line := current.Mul(c.Quantity)
+	c.Price, c.Currency = line.Amount, line.Currency
+}
+
+// MarshalJSON menulis price sebagai Money agar mata uangnya ikut terkirim
+func (c CartItem) MarshalJSON() ([]byte, error) {
+	type alias CartItem
+	return json.Marshal(struct {
+		alias
+		Price          types.Money `json:"price"`
+		UnitPrice      types.Money `json:"unit_price"`
+		AddedUnitPrice types.Money `json:"added_unit_price"`
+	}{alias(c), c.LinePrice(), c.CurrentPrice(), c.AddedUnitPrice()})
 }
@@ -0,0 +1,66 @@
+package model
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Owner adalah pemilik cart: user yang login, atau guest cart yang diidentifikasi cart token.
+// Tepat satu dari UserID dan GuestCartID terisi.
+type Owner struct {
+	UserID      uint
+	GuestCartID uint
+}
+
+func UserOwner(userID uint) Owner {
+	return Owner{UserID: userID}
+}
+
+func GuestOwner(guestCartID uint) Owner {
+	return Owner{GuestCartID: guestCartID}
+}
+
+func (o Owner) IsGuest() bool {
+	return o.UserID == 0
+}
+
+// Owns bernilai true jika baris cart milik owner ini
+func (o Owner) Owns(item *CartItem) bool {
+	if o.IsGuest() {
+		return item.GuestCartID != nil && *item.GuestCartID == o.GuestCartID
+	}
+	return item.UserID != nil && *item.UserID == o.UserID
+}
+
+// Owner mengembalikan pemilik baris cart
+func (c CartItem) Owner() Owner {
+	if c.UserID != nil {
+		return UserOwner(*c.UserID)
+	}
+	if c.GuestCartID != nil {
+		return GuestOwner(*c.GuestCartID)
+	}
+	return Owner{}
+}
+
+// GuestCart adalah cart pengunjung yang belum login
+type GuestCart struct {
+	ID        uint      `db:"id" json:"id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	ExpiresAt time.Time `db:"expires_at" json:"expires_at"`
+}
+
+// Hasil penggabungan satu baris guest cart ke cart user
+const (
+	MergeMoved    = "moved"    // Baris dipindah apa adanya ke cart user
+	MergeCombined = "combined" // Quantity ditambahkan ke baris user dengan produk, warna dan ukuran yang sama
+	MergeDropped  = "dropped"  // Produk sudah tidak ada, stoknya habis atau cart user sudah penuh
+)
+
+// LogValue menulis pemilik cart sebagai user_id atau guest_cart_id di log
+func (o Owner) LogValue() slog.Value {
+	if o.IsGuest() {
+		return slog.GroupValue(slog.Uint64("guest_cart_id", uint64(o.GuestCartID)))
+	}
+	return slog.GroupValue(slog.Uint64("user_id", uint64(o.UserID)))
+}
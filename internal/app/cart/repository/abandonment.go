@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/cart/model"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// CloseRecoveredAbandonments menutup catatan abandoned yang cart-nya mendapat aktivitas baru sejak
+// terdeteksi, yaitu baris aktif yang diubah setelah last_activity_at catatan. Menghapus baris saja
+// tidak dihitung sebagai recovered. Seperti CreateAbandonments, baris saved for later diabaikan.
+func (r *cartRepo) CloseRecoveredAbandonments(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE cart_abandonments a SET recovered_at = NOW()
+		WHERE a.recovered_at IS NULL AND a.closed_at IS NULL AND a.purged_at IS NULL
+		  AND EXISTS (
+				SELECT 1 FROM cart_items ci
+				WHERE (ci.user_id = a.user_id OR ci.guest_cart_id = a.guest_cart_id) AND NOT ci.saved_for_later
+				  AND ci.updated_at > a.last_activity_at
+		  )
+	`
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query)
+	if err != nil {
+		slog.Error("Failed to close recovered cart abandonments", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CloseEmptiedAbandonments menutup catatan abandoned yang cart-nya tidak lagi punya baris aktif,
+// misalnya dikosongkan pemiliknya atau guest cart-nya dihapus karena kedaluwarsa. Catatan ini
+// ditandai closed, bukan recovered, agar tidak ikut dihitung di recovery rate.
+func (r *cartRepo) CloseEmptiedAbandonments(ctx context.Context) (int64, error) {
+	query := `
+		UPDATE cart_abandonments a SET closed_at = NOW()
+		WHERE a.recovered_at IS NULL AND a.closed_at IS NULL AND a.purged_at IS NULL
+		  AND NOT EXISTS (
+				SELECT 1 FROM cart_items ci
+				WHERE (ci.user_id = a.user_id OR ci.guest_cart_id = a.guest_cart_id) AND NOT ci.saved_for_later
+		  )
+	`
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query)
+	if err != nil {
+		slog.Error("Failed to close emptied cart abandonments", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// CreateAbandonments mencatat cart yang aktivitas terakhir baris aktifnya sebelum cutoff dan belum
+// punya catatan terbuka. Cart yang hanya berisi baris saved for later tidak dianggap abandoned.
+func (r *cartRepo) CreateAbandonments(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `
+		INSERT INTO cart_abandonments (user_id, guest_cart_id, last_activity_at, item_count, total_amount, currency)
+		SELECT ci.user_id, ci.guest_cart_id, MAX(ci.updated_at), SUM(ci.quantity), SUM(ci.price), MIN(ci.currency)
+		FROM cart_items ci
+		WHERE NOT ci.saved_for_later
+		GROUP BY ci.user_id, ci.guest_cart_id
+		HAVING MAX(ci.updated_at) < $1
+		   AND NOT EXISTS (
+				SELECT 1 FROM cart_abandonments a
+				WHERE a.recovered_at IS NULL AND a.closed_at IS NULL AND a.purged_at IS NULL
+				  AND (a.user_id = ci.user_id OR a.guest_cart_id = ci.guest_cart_id)
+		   )
+		ON CONFLICT DO NOTHING
+	`
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, cutoff)
+	if err != nil {
+		slog.Error("Failed to create cart abandonments", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimReminders menandai sampai limit catatan terbuka milik user sebagai sudah diingatkan dan
+// mengembalikannya beserta email user. Guest cart tidak punya kontak sehingga tidak diingatkan;
+// cart yang aktivitas terakhirnya sebelum notBefore juga dilewati.
+func (r *cartRepo) ClaimReminders(ctx context.Context, notBefore time.Time, limit int) ([]model.Abandonment, error) {
+	var claimed []model.Abandonment
+	query := `
+		UPDATE cart_abandonments a SET notified_at = NOW()
+		FROM users u
+		WHERE u.id = a.user_id AND a.id IN (
+			SELECT id FROM cart_abandonments
+			WHERE user_id IS NOT NULL AND notified_at IS NULL AND recovered_at IS NULL AND closed_at IS NULL AND purged_at IS NULL
+			  AND last_activity_at >= $1
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING a.*, u.email
+	`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &claimed, query, notBefore, limit); err != nil {
+		slog.Error("Failed to claim cart reminders", "error", err)
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// ReleaseReminder membatalkan klaim reminder yang gagal dikirim agar dicoba lagi di putaran berikutnya
+func (r *cartRepo) ReleaseReminder(ctx context.Context, id uint) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `UPDATE cart_abandonments SET notified_at = NULL WHERE id = $1`, id)
+	return err
+}
+
+// archiveColumns adalah kolom cart_items yang disalin ke cart_items_archive
+const archiveColumns = `id, created_at, updated_at, deleted_at, user_id, guest_cart_id, product_id, name,
+	quantity, price, currency, unit_price, color, size, saved_for_later`
+
+// PurgeCarts menghapus cart yang aktivitas terakhirnya sebelum cutoff. Jika archive bernilai true,
+// barisnya dipindahkan ke cart_items_archive. Reservasi stok dilepas dan kupon cart ikut dihapus.
+func (r *cartRepo) PurgeCarts(ctx context.Context, cutoff time.Time, archive bool) (carts, items int64, err error) {
+	err = database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var owners []struct {
+			UserID      *int64 `db:"user_id"`
+			GuestCartID *int64 `db:"guest_cart_id"`
+		}
+		ownerQuery := `
+			SELECT user_id, guest_cart_id FROM cart_items
+			GROUP BY user_id, guest_cart_id
+			HAVING MAX(updated_at) < $1
+		`
+		if err := tx.SelectContext(ctx, &owners, ownerQuery, cutoff); err != nil {
+			return err
+		}
+		if len(owners) == 0 {
+			return nil
+		}
+
+		var userIDs, guestCartIDs []int64
+		for _, owner := range owners {
+			if owner.UserID != nil {
+				userIDs = append(userIDs, *owner.UserID)
+			} else if owner.GuestCartID != nil {
+				guestCartIDs = append(guestCartIDs, *owner.GuestCartID)
+			}
+		}
+		carts = int64(len(owners))
+
+		// Baris yang disentuh setelah cutoff tetap dipertahankan walaupun cart-nya terpilih
+		var itemIDs []int64
+		itemQuery := `
+			SELECT id FROM cart_items
+			WHERE (user_id = ANY($1) OR guest_cart_id = ANY($2)) AND updated_at < $3
+			FOR UPDATE
+		`
+		if err := tx.SelectContext(ctx, &itemIDs, itemQuery, pq.Array(userIDs), pq.Array(guestCartIDs), cutoff); err != nil {
+			return err
+		}
+		items = int64(len(itemIDs))
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM stock_reservations WHERE cart_item_id = ANY($1)`, pq.Array(itemIDs)); err != nil {
+			return err
+		}
+
+		deleteQuery := `DELETE FROM cart_items WHERE id = ANY($1)`
+		if archive {
+			deleteQuery = `
+				WITH moved AS (DELETE FROM cart_items WHERE id = ANY($1) RETURNING *)
+				INSERT INTO cart_items_archive (` + archiveColumns + `, archived_at)
+				SELECT ` + archiveColumns + `, NOW() FROM moved
+			`
+		}
+		if _, err := tx.ExecContext(ctx, deleteQuery, pq.Array(itemIDs)); err != nil {
+			return err
+		}
+
+		ownerArgs := []any{pq.Array(userIDs), pq.Array(guestCartIDs)}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM cart_coupons WHERE user_id = ANY($1) OR guest_cart_id = ANY($2)`, ownerArgs...); err != nil {
+			return err
+		}
+		closeQuery := `
+			UPDATE cart_abandonments SET purged_at = NOW()
+			WHERE recovered_at IS NULL AND closed_at IS NULL AND purged_at IS NULL AND (user_id = ANY($1) OR guest_cart_id = ANY($2))
+		`
+		_, err := tx.ExecContext(ctx, closeQuery, ownerArgs...)
+		return err
+	})
+	if err != nil {
+		slog.Error("Failed to purge carts", "cutoff", cutoff, "archive", archive, "error", err)
+		return 0, 0, err
+	}
+	return carts, items, nil
+}
+
+// AbandonmentStats menghitung angka laporan untuk cart dengan aktivitas terakhir dalam [from, to).
+// Cart aktif mencakup baris yang masih ada, yang sudah diarsipkan, dan cart abandoned yang sudah dihapus.
+func (r *cartRepo) AbandonmentStats(ctx context.Context, from, to time.Time) (*model.AbandonmentStats, error) {
+	var stats model.AbandonmentStats
+	query := `
+		WITH active AS (
+			SELECT user_id, guest_cart_id FROM cart_items WHERE updated_at >= $1 AND updated_at < $2
+			UNION
+			SELECT user_id, guest_cart_id FROM cart_items_archive WHERE updated_at >= $1 AND updated_at < $2
+			UNION
+			SELECT user_id, guest_cart_id FROM cart_abandonments WHERE last_activity_at >= $1 AND last_activity_at < $2
+		)
+		SELECT
+			(SELECT COUNT(*) FROM active) AS active,
+			COUNT(*) AS abandoned,
+			COUNT(notified_at) AS reminded,
+			COUNT(recovered_at) AS recovered,
+			COUNT(*) FILTER (WHERE recovered_at > notified_at) AS recovered_after_reminder,
+			COUNT(closed_at) AS closed,
+			COUNT(purged_at) AS purged
+		FROM cart_abandonments
+		WHERE last_activity_at >= $1 AND last_activity_at < $2
+	`
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &stats, query, from, to); err != nil {
+		slog.Error("Failed to compute cart abandonment stats", "error", err)
+		return nil, err
+	}
+	return &stats, nil
+}
@@ -2,20 +2,39 @@ package repository
 
 import (
 	"context"
+	"go-fiber-api/database"
 	"go-fiber-api/internal/app/cart/model"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Cart interface {
-	FindByUserID(ctx context.Context, userID uint) ([]model.CartItem, error)
+	FindByOwner(ctx context.Context, owner model.Owner) ([]model.CartItem, error)
+	FindByOwnerWithCoupon(ctx context.Context, owner model.Owner) ([]model.CartItem, *uint, error)
 	FindByID(ctx context.Context, id uint) (*model.CartItem, error)
-	FindByUserAndProductID(ctx context.Context, userID, productID uint) (*model.CartItem, error)
 	Create(ctx context.Context, item *model.CartItem) error
 	Update(ctx context.Context, item *model.CartItem) error
 	Delete(ctx context.Context, id uint) error
 	DeleteMany(ctx context.Context, ids []uint) error
-	FindByUserProductColorSize(ctx context.Context, userID, productID uint, color, size string) (*model.CartItem, error)
+	AssignToUser(ctx context.Context, id, userID uint) error
+	SetSavedForLater(ctx context.Context, id uint, saved bool) error
+	CreateGuestCart(ctx context.Context, expiresAt time.Time) (*model.GuestCart, error)
+	FindGuestCart(ctx context.Context, id uint) (*model.GuestCart, error)
+	DeleteGuestCart(ctx context.Context, id uint) error
+	DeleteExpiredGuestCarts(ctx context.Context) (int64, error)
+	FindCartCoupon(ctx context.Context, owner model.Owner) (uint, error)
+	SetCartCoupon(ctx context.Context, owner model.Owner, couponID uint) error
+	DeleteCartCoupon(ctx context.Context, owner model.Owner) (bool, error)
+	TransferGuestCoupon(ctx context.Context, guestCartID, userID uint) error
+	CloseRecoveredAbandonments(ctx context.Context) (int64, error)
+	CloseEmptiedAbandonments(ctx context.Context) (int64, error)
+	CreateAbandonments(ctx context.Context, cutoff time.Time) (int64, error)
+	ClaimReminders(ctx context.Context, notBefore time.Time, limit int) ([]model.Abandonment, error)
+	ReleaseReminder(ctx context.Context, id uint) error
+	PurgeCarts(ctx context.Context, cutoff time.Time, archive bool) (carts, items int64, err error)
+	AbandonmentStats(ctx context.Context, from, to time.Time) (*model.AbandonmentStats, error)
 }
 
 type cartRepo struct {
@@ -26,34 +45,79 @@ func NewCartRepository(db *sqlx.DB) Cart {
 	return &cartRepo{db: db}
 }
 
-func (r *cartRepo) FindByUserID(ctx context.Context, userID uint) ([]model.CartItem, error) {
+// selectCartItems menyertakan harga satuan produk saat ini (sale jika ada) untuk reprice,
+// sehingga listing, total dan summary cukup satu query. Kolom produk bernilai NULL jika
+// produknya sudah dihapus.
+const selectCartItems = `
+	SELECT ci.*, COALESCE(sp.price, p.price) AS current_unit_price, p.currency AS current_currency,
+		p.type AS product_type, p.category AS product_category, p.tax_class AS product_tax_class,
+		p.weight_grams AS product_weight_grams, p.length_mm AS product_length_mm,
+		p.width_mm AS product_width_mm, p.height_mm AS product_height_mm
+	FROM cart_items ci
+	LEFT JOIN products p ON p.id = ci.product_id
+	LEFT JOIN LATERAL (
+		SELECT pp.price
+		FROM product_prices pp
+		WHERE pp.product_id = p.id AND pp.kind = 'sale'
+		  AND pp.valid_from <= NOW() AND (pp.valid_to IS NULL OR pp.valid_to > NOW())
+		ORDER BY pp.valid_from DESC
+		LIMIT 1
+	) sp ON TRUE`
+
+func (r *cartRepo) FindByOwner(ctx context.Context, owner model.Owner) ([]model.CartItem, error) {
 	var items []model.CartItem
-	query := `SELECT * FROM cart_items WHERE user_id = $1`
-	err := r.db.SelectContext(ctx, &items, query, userID)
+	query := selectCartItems + ` WHERE ci.user_id = $1 ORDER BY ci.id`
+	id := owner.UserID
+	if owner.IsGuest() {
+		query = selectCartItems + ` WHERE ci.guest_cart_id = $1 ORDER BY ci.id`
+		id = owner.GuestCartID
+	}
+	err := database.Conn(ctx, r.db).SelectContext(ctx, &items, query, id)
 	return items, err
 }
 
-func (r *cartRepo) FindByID(ctx context.Context, id uint) (*model.CartItem, error) {
-	var item model.CartItem
-	query := `SELECT * FROM cart_items WHERE id = $1 LIMIT 1`
-	err := r.db.GetContext(ctx, &item, query, id)
-	return &item, err
+// FindByOwnerWithCoupon memuat baris cart (termasuk tax class produknya) beserta ID kupon yang
+// terpasang dalam satu query, untuk total dan summary. Cart tanpa baris mengembalikan kupon nil.
+func (r *cartRepo) FindByOwnerWithCoupon(ctx context.Context, owner model.Owner) ([]model.CartItem, *uint, error) {
+	var rows []struct {
+		model.CartItem
+		CartCouponID *uint `db:"cart_coupon_id"`
+	}
+	column, id := "user_id", owner.UserID
+	if owner.IsGuest() {
+		column, id = "guest_cart_id", owner.GuestCartID
+	}
+	query := `
+		SELECT items.*, (SELECT cc.coupon_id FROM cart_coupons cc WHERE cc.` + column + ` = $1) AS cart_coupon_id
+		FROM (` + selectCartItems + ` WHERE ci.` + column + ` = $1) items
+		ORDER BY items.id`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &rows, query, id); err != nil {
+		return nil, nil, err
+	}
+
+	items := make([]model.CartItem, 0, len(rows))
+	var couponID *uint
+	for _, row := range rows {
+		items = append(items, row.CartItem)
+		couponID = row.CartCouponID
+	}
+	return items, couponID, nil
 }
 
-func (r *cartRepo) FindByUserAndProductID(ctx context.Context, userID, productID uint) (*model.CartItem, error) {
+func (r *cartRepo) FindByID(ctx context.Context, id uint) (*model.CartItem, error) {
 	var item model.CartItem
-	query := `SELECT * FROM cart_items WHERE user_id = $1 AND product_id = $2 LIMIT 1`
-	err := r.db.GetContext(ctx, &item, query, userID, productID)
+	query := selectCartItems + ` WHERE ci.id = $1 LIMIT 1`
+	err := database.Conn(ctx, r.db).GetContext(ctx, &item, query, id)
 	return &item, err
 }
 
 func (r *cartRepo) Create(ctx context.Context, item *model.CartItem) error {
 	query := `
-		INSERT INTO cart_items (user_id, product_id, name, quantity, price, color, size)
-		VALUES (:user_id, :product_id, :name, :quantity, :price, :color, :size)
+		INSERT INTO cart_items (user_id, guest_cart_id, product_id, name, quantity, price, currency, unit_price, color, size)
+		VALUES (:user_id, :guest_cart_id, :product_id, :name, :quantity, :price, :currency, :unit_price, :color, :size)
 		RETURNING id
 	`
-	rows, err := r.db.NamedQueryContext(ctx, query, item)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, item)
 	if err != nil {
 		return err
 	}
@@ -68,34 +132,40 @@ func (r *cartRepo) Update(ctx context.Context, item *model.CartItem) error {
 	query := `
 		UPDATE cart_items
 		SET product_id = :product_id, name = :name, quantity = :quantity,
-		    price = :price, color = :color, size = :size
+		    price = :price, currency = :currency, unit_price = :unit_price, color = :color, size = :size,
+		    updated_at = NOW()
 		WHERE id = :id
 	`
-	_, err := r.db.NamedExecContext(ctx, query, item)
+	_, err := database.Conn(ctx, r.db).NamedExecContext(ctx, query, item)
 	return err
 }
 
 func (r *cartRepo) Delete(ctx context.Context, id uint) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM cart_items WHERE id = $1`, id)
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM cart_items WHERE id = $1`, id)
 	return err
 }
 
 func (r *cartRepo) DeleteMany(ctx context.Context, ids []uint) error {
+	ids64 := make([]int64, len(ids))
+	for i, id := range ids {
+		ids64[i] = int64(id)
+	}
+
 	query := `DELETE FROM cart_items WHERE id = ANY($1)`
-	_, err := r.db.ExecContext(ctx, query, ids)
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, pq.Array(ids64))
 	return err
 }
 
-func (r *cartRepo) FindByUserProductColorSize(ctx context.Context, userID, productID uint, color, size string) (*model.CartItem, error) {
-	var item model.CartItem
-	query := `
-		SELECT * FROM cart_items
-		WHERE user_id = $1 AND product_id = $2 AND color = $3 AND size = $4
-		LIMIT 1
-	`
-	err := r.db.GetContext(ctx, &item, query, userID, productID, color, size)
-	if err != nil {
-		return nil, err
-	}
-	return &item, nil
+// AssignToUser memindahkan baris guest cart menjadi milik user
+func (r *cartRepo) AssignToUser(ctx context.Context, id, userID uint) error {
+	query := `UPDATE cart_items SET user_id = $2, guest_cart_id = NULL, updated_at = NOW() WHERE id = $1`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id, userID)
+	return err
+}
+
+// SetSavedForLater memindahkan baris ke daftar saved for later atau kembali ke cart
+func (r *cartRepo) SetSavedForLater(ctx context.Context, id uint, saved bool) error {
+	query := `UPDATE cart_items SET saved_for_later = $2, updated_at = NOW() WHERE id = $1`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id, saved)
+	return err
 }
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/cart/model"
+)
+
+// FindCartCoupon mengembalikan ID kupon yang terpasang di cart, sql.ErrNoRows jika tidak ada
+func (r *cartRepo) FindCartCoupon(ctx context.Context, owner model.Owner) (uint, error) {
+	var couponID uint
+	query := `SELECT coupon_id FROM cart_coupons WHERE user_id = $1`
+	id := owner.UserID
+	if owner.IsGuest() {
+		query = `SELECT coupon_id FROM cart_coupons WHERE guest_cart_id = $1`
+		id = owner.GuestCartID
+	}
+	err := database.Conn(ctx, r.db).GetContext(ctx, &couponID, query, id)
+	return couponID, err
+}
+
+// SetCartCoupon memasang kupon ke cart, menggantikan kupon yang sudah ada
+func (r *cartRepo) SetCartCoupon(ctx context.Context, owner model.Owner, couponID uint) error {
+	query := `
+		INSERT INTO cart_coupons (user_id, coupon_id) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET coupon_id = EXCLUDED.coupon_id, applied_at = NOW()
+	`
+	id := owner.UserID
+	if owner.IsGuest() {
+		query = `
+			INSERT INTO cart_coupons (guest_cart_id, coupon_id) VALUES ($1, $2)
+			ON CONFLICT (guest_cart_id) DO UPDATE SET coupon_id = EXCLUDED.coupon_id, applied_at = NOW()
+		`
+		id = owner.GuestCartID
+	}
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id, couponID)
+	return err
+}
+
+// DeleteCartCoupon melepas kupon dari cart; false jika cart tidak memakai kupon
+func (r *cartRepo) DeleteCartCoupon(ctx context.Context, owner model.Owner) (bool, error) {
+	query := `DELETE FROM cart_coupons WHERE user_id = $1`
+	id := owner.UserID
+	if owner.IsGuest() {
+		query = `DELETE FROM cart_coupons WHERE guest_cart_id = $1`
+		id = owner.GuestCartID
+	}
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	deleted, err := result.RowsAffected()
+	return deleted > 0, err
+}
+
+// TransferGuestCoupon memindahkan kupon guest cart ke cart user, kecuali user sudah memasang kupon sendiri
+func (r *cartRepo) TransferGuestCoupon(ctx context.Context, guestCartID, userID uint) error {
+	query := `
+		UPDATE cart_coupons SET user_id = $2, guest_cart_id = NULL
+		WHERE guest_cart_id = $1 AND NOT EXISTS (SELECT 1 FROM cart_coupons WHERE user_id = $2)
+	`
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, query, guestCartID, userID)
+	return err
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/cart/model"
+	"log/slog"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (r *cartRepo) CreateGuestCart(ctx context.Context, expiresAt time.Time) (*model.GuestCart, error) {
+	var guest model.GuestCart
+	query := `INSERT INTO guest_carts (expires_at) VALUES ($1) RETURNING *`
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &guest, query, expiresAt); err != nil {
+		slog.Error("Failed to create guest cart", "error", err)
+		return nil, err
+	}
+	return &guest, nil
+}
+
+// FindGuestCart hanya mengembalikan guest cart yang belum kedaluwarsa
+func (r *cartRepo) FindGuestCart(ctx context.Context, id uint) (*model.GuestCart, error) {
+	var guest model.GuestCart
+	query := `SELECT * FROM guest_carts WHERE id = $1 AND expires_at > NOW()`
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &guest, query, id); err != nil {
+		return nil, err
+	}
+	return &guest, nil
+}
+
+// DeleteGuestCart menghapus guest cart beserta baris yang masih tersisa di dalamnya
+func (r *cartRepo) DeleteGuestCart(ctx context.Context, id uint) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM guest_carts WHERE id = $1`, id)
+	return err
+}
+
+// DeleteExpiredGuestCarts menghapus guest cart kedaluwarsa. Baris cart ikut terhapus lewat
+// ON DELETE CASCADE; reservasi stoknya dilepas lebih dulu karena tidak punya foreign key.
+func (r *cartRepo) DeleteExpiredGuestCarts(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		releaseQuery := `
+			DELETE FROM stock_reservations
+			WHERE cart_item_id IN (
+				SELECT ci.id FROM cart_items ci
+				JOIN guest_carts g ON g.id = ci.guest_cart_id
+				WHERE g.expires_at <= NOW()
+			)
+		`
+		if _, err := tx.ExecContext(ctx, releaseQuery); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, `DELETE FROM guest_carts WHERE expires_at <= NOW()`)
+		if err != nil {
+			return err
+		}
+		deleted, _ = result.RowsAffected()
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to delete expired guest carts", "error", err)
+		return 0, err
+	}
+	return deleted, nil
+}
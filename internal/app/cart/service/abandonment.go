@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-fiber-api/internal/app/cart/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/notifier"
+	"log/slog"
+	"time"
+)
+
+// reminderBatchSize adalah jumlah reminder yang diklaim per query
+const reminderBatchSize = 100
+
+// SweepAbandonedCarts menjalankan satu putaran job abandoned cart: menutup catatan yang cart-nya
+// sudah disentuh lagi atau sudah kosong, mencatat cart yang tidak disentuh selama AbandonAfter, mengirim reminder
+// paling banyak sekali per catatan, lalu menghapus atau mengarsipkan cart yang melewati Retention.
+func (s *cartService) SweepAbandonedCarts(ctx context.Context) (*model.SweepResult, error) {
+	result := &model.SweepResult{}
+	now := time.Now()
+
+	var err error
+	if result.Recovered, err = s.repo.CloseRecoveredAbandonments(ctx); err != nil {
+		return nil, fmt.Errorf("failed to close recovered carts: %w", err)
+	}
+	if result.Closed, err = s.repo.CloseEmptiedAbandonments(ctx); err != nil {
+		return nil, fmt.Errorf("failed to close emptied carts: %w", err)
+	}
+
+	if s.config.AbandonAfter > 0 {
+		if result.Abandoned, err = s.repo.CreateAbandonments(ctx, now.Add(-s.config.AbandonAfter)); err != nil {
+			return nil, fmt.Errorf("failed to detect abandoned carts: %w", err)
+		}
+		if result.Reminded, err = s.sendReminders(ctx, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if s.config.Retention > 0 {
+		result.PurgedCarts, result.PurgedItems, err = s.repo.PurgeCarts(ctx, now.Add(-s.config.Retention), s.config.ArchivePurged)
+		if err != nil {
+			return nil, fmt.Errorf("failed to purge carts: %w", err)
+		}
+	}
+
+	slog.Info("Abandoned cart sweep finished", "recovered", result.Recovered, "closed", result.Closed, "abandoned", result.Abandoned,
+		"reminded", result.Reminded, "purged_carts", result.PurgedCarts, "purged_items", result.PurgedItems,
+		"archive", s.config.ArchivePurged, "duration", time.Since(now))
+	return result, nil
+}
+
+// sendReminders mengirim reminder untuk catatan yang belum diingatkan. Catatan diklaim sebelum
+// dikirim agar instance lain tidak mengirim reminder yang sama; klaim dilepas jika notifier gagal.
+// Cart yang sudah melewati masa retensi tidak diingatkan karena akan segera dihapus.
+func (s *cartService) sendReminders(ctx context.Context, now time.Time) (int64, error) {
+	var notBefore time.Time
+	if s.config.Retention > 0 {
+		notBefore = now.Add(-s.config.Retention)
+	}
+
+	var sent int64
+	for {
+		claimed, err := s.repo.ClaimReminders(ctx, notBefore, reminderBatchSize)
+		if err != nil {
+			return sent, fmt.Errorf("failed to claim cart reminders: %w", err)
+		}
+
+		failed := 0
+		for _, abandonment := range claimed {
+			if err := s.notifyAbandoned(ctx, abandonment); err != nil {
+				failed++
+				slog.Error("Failed to send abandoned cart reminder", "abandonment_id", abandonment.ID, "user_id", *abandonment.UserID, "error", err)
+				if err := s.repo.ReleaseReminder(ctx, abandonment.ID); err != nil {
+					slog.Error("Failed to release cart reminder", "abandonment_id", abandonment.ID, "error", err)
+				}
+				continue
+			}
+			sent++
+		}
+
+		// Berhenti jika batch tidak penuh, atau semua gagal agar tidak mengklaim ulang yang sama terus-menerus
+		if len(claimed) < reminderBatchSize || failed == len(claimed) {
+			return sent, nil
+		}
+	}
+}
+
+func (s *cartService) notifyAbandoned(ctx context.Context, abandonment model.Abandonment) error {
+	return s.notifier.Notify(ctx, notifier.Notification{
+		Type:    "abandoned_cart",
+		Subject: "You left something in your cart",
+		Message: fmt.Sprintf("%d item(s) worth %s are still waiting in your cart", abandonment.ItemCount, abandonment.Total()),
+		Data: map[string]any{
+			"abandonment_id":   abandonment.ID,
+			"user_id":          *abandonment.UserID,
+			"email":            abandonment.Email,
+			"item_count":       abandonment.ItemCount,
+			"total":            abandonment.Total(),
+			"last_activity_at": abandonment.LastActivityAt,
+		},
+	})
+}
+
+// RunAbandonedCartJob menjalankan SweepAbandonedCarts setiap interval sampai ctx selesai.
+// Interval 0 mematikan job (jalankan lewat CLI).
+func (s *cartService) RunAbandonedCartJob(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	slog.Info("Abandoned cart job started", "interval", interval, "abandon_after", s.config.AbandonAfter, "retention", s.config.Retention)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Abandoned cart job stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.SweepAbandonedCarts(ctx); err != nil {
+				slog.Error("Abandoned cart sweep failed", "error", err)
+			}
+		}
+	}
+}
+
+// AbandonmentReport merangkum abandonment untuk cart dengan aktivitas terakhir dalam [from, to).
+// Abandonment rate adalah cart abandoned dibagi cart aktif; recovery rate adalah cart abandoned
+// yang mendapat aktivitas baru dibagi cart abandoned. Cart yang dikosongkan atau dihapus tanpa
+// aktivitas baru dihitung sebagai closed, bukan recovered.
+func (s *cartService) AbandonmentReport(ctx context.Context, from, to time.Time) (*dto.CartAbandonmentReport, error) {
+	stats, err := s.repo.AbandonmentStats(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute abandonment report: %w", err)
+	}
+
+	return &dto.CartAbandonmentReport{
+		From:                   from,
+		To:                     to,
+		AbandonAfterHours:      s.config.AbandonAfter.Hours(),
+		ActiveCarts:            stats.Active,
+		AbandonedCarts:         stats.Abandoned,
+		RemindersSent:          stats.Reminded,
+		RecoveredCarts:         stats.Recovered,
+		RecoveredAfterReminder: stats.RecoveredAfterReminder,
+		ClosedCarts:            stats.Closed,
+		PurgedCarts:            stats.Purged,
+		AbandonmentRate:        ratio(stats.Abandoned, stats.Active),
+		RecoveryRate:           ratio(stats.Recovered, stats.Abandoned),
+	}, nil
+}
+
+// ratio mengembalikan part/total dibulatkan ke 4 desimal, 0 jika total 0
+func ratio(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part*10000/total) / 10000
+}
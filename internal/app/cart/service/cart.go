@@ -2,48 +2,111 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"go-fiber-api/database"
 	"go-fiber-api/internal/app/cart/model"
 	cartRepo "go-fiber-api/internal/app/cart/repository"
+	couponService "go-fiber-api/internal/app/coupon/service"
+	inventoryService "go-fiber-api/internal/app/inventory/service"
+	productModel "go-fiber-api/internal/app/product/model"
 	productRepo "go-fiber-api/internal/app/product/repository"
+	shippingService "go-fiber-api/internal/app/shipping/service"
+	taxModel "go-fiber-api/internal/app/tax/model"
+	taxService "go-fiber-api/internal/app/tax/service"
 	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/notifier"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
 	"log/slog"
+	"net/http"
+	"time"
 )
 
 type Cart interface {
-	GetByUserID(ctx context.Context, userID uint) ([]model.CartItem, error)
+	GetByOwner(ctx context.Context, owner model.Owner) ([]model.CartItem, error)
+	GetSavedForLater(ctx context.Context, owner model.Owner) ([]model.CartItem, error)
 	GetByID(ctx context.Context, id uint) (*model.CartItem, error)
-	Create(ctx context.Context, userID uint, input *dto.CartItemRequest) (*model.CartItem, error)
-	CreateMany(ctx context.Context, userID uint, inputs []dto.CartItemRequest) ([]model.CartItem, error)
+	Create(ctx context.Context, owner model.Owner, input *dto.CartItemRequest) (*model.CartItem, error)
+	CreateMany(ctx context.Context, owner model.Owner, inputs []dto.CartItemRequest, atomic bool) ([]model.BulkItemResult, error)
 	Update(ctx context.Context, id uint, input *dto.CartItemRequest) (*model.CartItem, error)
 	Delete(ctx context.Context, id uint) error
-	DeleteMany(ctx context.Context, ids []uint) error
-	GetCartTotal(ctx context.Context, userID uint) (float64, error)
+	SaveForLater(ctx context.Context, id uint) (*model.CartItem, error)
+	MoveToCart(ctx context.Context, id uint) (*model.CartItem, error)
+	DeleteMany(ctx context.Context, owner model.Owner, ids []uint, atomic bool) ([]model.BulkItemResult, error)
+	GetCartTotal(ctx context.Context, owner model.Owner, region taxModel.Region) (*dto.CartTotalResponse, error)
+	GetCartSummary(ctx context.Context, owner model.Owner, region taxModel.Region) (*dto.CartSummary, error)
+	Validate(ctx context.Context, owner model.Owner) (*dto.CartValidationResponse, error)
+	ApplyCoupon(ctx context.Context, owner model.Owner, code string) error
+	RemoveCoupon(ctx context.Context, owner model.Owner) error
+	RedeemCoupon(ctx context.Context, owner model.Owner, region taxModel.Region) (*dto.CartTotalResponse, error)
+	QuoteShipping(ctx context.Context, owner model.Owner, req *dto.ShippingQuoteRequest) (*dto.ShippingQuoteResponse, error)
+	StartGuestCart(ctx context.Context) (model.Owner, string, error)
+	ResolveGuestCart(ctx context.Context, token string) (model.Owner, error)
+	MergeGuestCart(ctx context.Context, userID uint, token string) (*dto.CartMergeResult, error)
+	RunGuestCartSweeper(ctx context.Context, interval time.Duration)
+	SweepAbandonedCarts(ctx context.Context) (*model.SweepResult, error)
+	RunAbandonedCartJob(ctx context.Context, interval time.Duration)
+	AbandonmentReport(ctx context.Context, from, to time.Time) (*dto.CartAbandonmentReport, error)
 }
 
 type cartService struct {
 	repo        cartRepo.Cart
 	productRepo productRepo.Product
+	inventory   inventoryService.Inventory
+	coupons     couponService.Coupon
+	tax         taxService.Calculator
+	shipping    shippingService.Shipping
+	notifier    notifier.Notifier
+	tx          database.Transactor
+	config      Config
 }
 
-func NewCartService(cartRepo cartRepo.Cart, productRepo productRepo.Product) Cart {
+// Config adalah pengaturan service cart yang dibaca dari env saat start
+type Config struct {
+	GuestTTL        time.Duration // Umur guest cart beserta cart token-nya
+	MaxLines        int           // Jumlah baris berbeda per cart; 0 berarti tanpa batas
+	MaxLineQuantity int           // Quantity maksimum satu baris; 0 berarti tanpa batas
+
+	// Negara untuk perhitungan pajak jika request tidak menyebutkan wilayah
+	DefaultTaxCountry string
+
+	AbandonAfter  time.Duration // Cart yang tidak disentuh selama ini dianggap abandoned; 0 mematikan reminder
+	Retention     time.Duration // Cart yang tidak disentuh selama ini dihapus; 0 berarti disimpan selamanya
+	ArchivePurged bool          // Pindahkan baris cart yang dihapus ke cart_items_archive
+}
+
+func NewCartService(cartRepo cartRepo.Cart, productRepo productRepo.Product, inventory inventoryService.Inventory, coupons couponService.Coupon, tax taxService.Calculator, shipping shippingService.Shipping, notifier notifier.Notifier, tx database.Transactor, config Config) Cart {
 	return &cartService{
 		repo:        cartRepo,
 		productRepo: productRepo,
+		inventory:   inventory,
+		coupons:     coupons,
+		tax:         tax,
+		shipping:    shipping,
+		notifier:    notifier,
+		tx:          tx,
+		config:      config,
 	}
 }
 
-func (s *cartService) GetByUserID(ctx context.Context, userID uint) ([]model.CartItem, error) {
-	slog.Info("Fetching cart items", "user_id", userID)
+// GetByOwner mengembalikan baris aktif cart yang sudah di-reprice. Baris saved for later tidak
+// termasuk sehingga total, kupon, ongkos kirim dan validasi checkout hanya melihat baris aktif.
+func (s *cartService) GetByOwner(ctx context.Context, owner model.Owner) ([]model.CartItem, error) {
+	slog.Info("Fetching cart items", "owner", owner)
 
-	items, err := s.repo.FindByUserID(ctx, userID)
+	all, err := s.repo.FindByOwner(ctx, owner)
 	if err != nil {
-		slog.Error("Failed to fetch cart items", "user_id", userID, "error", err)
+		slog.Error("Failed to fetch cart items", "owner", owner, "error", err)
 		return nil, fmt.Errorf("failed to fetch cart items: %w", err)
 	}
+	items, _ := model.SplitSaved(all)
+	for i := range items {
+		items[i].Reprice()
+	}
 
-	slog.Info("Cart items fetched successfully", "user_id", userID, "count", len(items))
+	slog.Info("Cart items fetched successfully", "owner", owner, "count", len(items))
 	return items, nil
 }
 
@@ -53,29 +116,43 @@ func (s *cartService) GetByID(ctx context.Context, id uint) (*model.CartItem, er
 		slog.Error("Failed to fetch cart item", "cart_id", id, "error", err)
 		return nil, fmt.Errorf("failed to fetch cart item: %w", err)
 	}
+	item.Reprice()
 	return item, nil
 }
 
 // calculateTotalPrice menghitung total harga berdasarkan harga satuan dan quantity
-func (s *cartService) calculateTotalPrice(unitPrice float64, quantity int) float64 {
-	return unitPrice * float64(quantity)
+func (s *cartService) calculateTotalPrice(unitPrice types.Money, quantity int) types.Money {
+	return unitPrice.Mul(quantity)
 }
 
-// validateCartInput memvalidasi input cart item
+// validateCartInput memvalidasi input cart item, termasuk batas quantity per baris
 func (s *cartService) validateCartInput(input *dto.CartItemRequest) error {
-	if input.Quantity <= 0 {
-		return errors.New("quantity harus lebih dari 0")
-	}
 	if input.ProductID == 0 {
-		return errors.New("product ID tidak boleh kosong")
+		return web.NewHTTPError(http.StatusBadRequest, "Product ID is required", web.ErrInvalidCartData)
 	}
-	return nil
+	return s.checkLineQuantity(input.Quantity)
 }
 
-func (s *cartService) Create(ctx context.Context, userID uint, input *dto.CartItemRequest) (*model.CartItem, error) {
+func (s *cartService) Create(ctx context.Context, owner model.Owner, input *dto.CartItemRequest) (*model.CartItem, error) {
+	var item *model.CartItem
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = s.addItem(ctx, owner, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// addItem menambahkan produk ke cart user. Jika sudah ada baris dengan produk, warna dan ukuran
+// yang sama, quantity baris tersebut yang ditambah. Harus dipanggil di dalam transaksi agar baris
+// baru ikut dibatalkan jika reservasi stok gagal.
+func (s *cartService) addItem(ctx context.Context, owner model.Owner, input *dto.CartItemRequest) (*model.CartItem, error) {
 	// Validasi input
 	if err := s.validateCartInput(input); err != nil {
-		slog.Error("Invalid cart input", "user_id", userID, "error", err)
+		slog.Warn("Invalid cart input", "owner", owner, "error", err)
 		return nil, err
 	}
 
@@ -83,15 +160,17 @@ func (s *cartService) Create(ctx context.Context, userID uint, input *dto.CartIt
 	product, err := s.productRepo.GetProductsByID(ctx, input.ProductID)
 	if err != nil {
 		slog.Error("Product not found", "product_id", input.ProductID, "error", err)
-		return nil, errors.New("produk tidak ditemukan")
+		return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
 	}
 
-	// Cek apakah produk sudah ada di cart dengan size dan color yang sama
-	existingItems, err := s.repo.FindByUserID(ctx, userID)
+	// Cek apakah produk sudah ada di cart dengan size dan color yang sama. Baris saved for later
+	// tidak digabung dan tidak dihitung untuk batas cart.
+	ownerItems, err := s.repo.FindByOwner(ctx, owner)
 	if err != nil {
-		slog.Error("Failed to check existing cart items", "user_id", userID, "error", err)
+		slog.Error("Failed to check existing cart items", "owner", owner, "error", err)
 		return nil, fmt.Errorf("failed to check existing cart items: %w", err)
 	}
+	existingItems, _ := model.SplitSaved(ownerItems)
 
 	// Jika item sudah ada, update quantity-nya
 	for _, existingItem := range existingItems {
@@ -108,81 +187,118 @@ func (s *cartService) Create(ctx context.Context, userID uint, input *dto.CartIt
 			}
 
 			slog.Info("Updating existing cart item", "cart_id", existingItem.ID, "new_quantity", newQuantity)
-			return s.Update(ctx, existingItem.ID, updateInput)
+			return s.updateItem(ctx, existingItem.ID, updateInput)
 		}
 	}
 
+	// Baris baru harus masih muat di cart dan tidak melewati batas per produk
+	if err := s.checkLineCount(len(existingItems)); err != nil {
+		return nil, err
+	}
+	if err := checkProductLimit(product, input.Quantity, productQuantity(existingItems, product.ID, 0)); err != nil {
+		return nil, err
+	}
+
+	// Stok yang dipakai baris ini; untuk bundle setiap komponennya
+	quantities, err := s.stockQuantities(ctx, product, input.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
 	// Buat item baru jika belum ada
-	totalPrice := s.calculateTotalPrice(product.Price, input.Quantity)
+	unitPrice := product.EffectivePrice()
+	totalPrice := s.calculateTotalPrice(unitPrice, input.Quantity)
 
 	item := &model.CartItem{
-		UserID:    userID,
 		ProductID: product.ID,
 		Name:      product.Name,
 		Quantity:  input.Quantity,
-		Price:     totalPrice, // Total harga = harga satuan * quantity
+		Price:     totalPrice.Amount, // Total harga = harga satuan * quantity
+		Currency:  totalPrice.Currency,
+		UnitPrice: unitPrice.Amount,
 		Color:     input.Color,
 		Size:      input.Size,
 	}
+	if owner.IsGuest() {
+		item.GuestCartID = &owner.GuestCartID
+	} else {
+		item.UserID = &owner.UserID
+	}
 
 	if err := s.repo.Create(ctx, item); err != nil {
-		slog.Error("Failed to create cart item", "user_id", userID, "product_id", input.ProductID, "error", err)
+		slog.Error("Failed to create cart item", "owner", owner, "product_id", input.ProductID, "error", err)
 		return nil, fmt.Errorf("failed to create cart item: %w", err)
 	}
 
-	slog.Info("Cart item created successfully", "user_id", userID, "product_id", input.ProductID, "quantity", input.Quantity, "total_price", totalPrice)
+	// Reservasi butuh ID baris; jika gagal, transaksi membatalkan baris yang baru dibuat
+	if err := s.inventory.ReserveAll(ctx, item.ID, owner.UserID, quantities); err != nil {
+		slog.Warn("Stock reservation failed", "owner", owner, "product_id", product.ID, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Cart item created successfully", "owner", owner, "product_id", input.ProductID, "quantity", input.Quantity, "total_price", totalPrice.String())
 	return item, nil
 }
 
-func (s *cartService) CreateMany(ctx context.Context, userID uint, inputs []dto.CartItemRequest) ([]model.CartItem, error) {
-	if len(inputs) == 0 {
-		return []model.CartItem{}, nil
+// CreateMany menambahkan beberapa item dengan aturan yang sama seperti Create, termasuk
+// penggabungan dengan baris yang sudah ada maupun dengan item sebelumnya di batch yang sama.
+// Lihat runBulk untuk perbedaan mode atomic dan non-atomic.
+func (s *cartService) CreateMany(ctx context.Context, owner model.Owner, inputs []dto.CartItemRequest, atomic bool) ([]model.BulkItemResult, error) {
+	var results []model.BulkItemResult
+	add := func(ctx context.Context) error {
+		var err error
+		results, err = s.runBulk(ctx, len(inputs), atomic, func(ctx context.Context, i int) (model.BulkItemResult, error) {
+			item, err := s.addItem(ctx, owner, &inputs[i])
+			if err != nil {
+				return model.BulkItemResult{}, err
+			}
+			return model.BulkItemResult{ID: item.ID, Item: item}, nil
+		})
+		return err
 	}
 
-	var result []model.CartItem
-
-	// Bungkus dalam transaction jika repository mendukung
-	for i, input := range inputs {
-		// Validasi setiap input
-		if err := s.validateCartInput(&input); err != nil {
-			slog.Error("Invalid cart input in bulk create", "index", i, "error", err)
-			return nil, fmt.Errorf("invalid input at index %d: %w", i, err)
-		}
-
-		product, err := s.productRepo.GetProductsByID(ctx, input.ProductID)
-		if err != nil {
-			slog.Error("Product not found for CreateMany", "product_id", input.ProductID, "error", err)
-			return nil, fmt.Errorf("produk tidak ditemukan untuk item ke-%d", i+1)
-		}
-
-		totalPrice := s.calculateTotalPrice(product.Price, input.Quantity)
-
-		item := model.CartItem{
-			UserID:    userID,
-			ProductID: product.ID,
-			Name:      product.Name,
-			Quantity:  input.Quantity,
-			Price:     totalPrice,
-			Color:     input.Color,
-			Size:      input.Size,
-		}
-
-		if err := s.repo.Create(ctx, &item); err != nil {
-			slog.Error("Failed to create cart item (bulk)", "product_id", input.ProductID, "index", i, "error", err)
-			return nil, fmt.Errorf("failed to create cart item at index %d: %w", i, err)
-		}
-
-		result = append(result, item)
+	var err error
+	if atomic {
+		// Satu transaksi menahan kunci semua produk sampai selesai. Semua produk di batch dikunci
+		// lebih dulu berurutan berdasarkan ID agar dua batch dengan urutan produk berbeda tidak deadlock.
+		err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			productIDs := make([]uint, 0, len(inputs))
+			for _, input := range inputs {
+				productIDs = append(productIDs, input.ProductID)
+			}
+			if err := s.inventory.LockProducts(ctx, productIDs...); err != nil {
+				return err
+			}
+			return add(ctx)
+		})
+	} else {
+		err = add(ctx)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	slog.Info("Bulk cart items created successfully", "user_id", userID, "count", len(result))
-	return result, nil
+	slog.Info("Bulk cart items processed", "owner", owner, "count", len(results), "failed", model.CountFailed(results), "atomic", atomic)
+	return results, nil
 }
 
 func (s *cartService) Update(ctx context.Context, id uint, input *dto.CartItemRequest) (*model.CartItem, error) {
+	var item *model.CartItem
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = s.updateItem(ctx, id, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *cartService) updateItem(ctx context.Context, id uint, input *dto.CartItemRequest) (*model.CartItem, error) {
 	// Validasi input
 	if err := s.validateCartInput(input); err != nil {
-		slog.Error("Invalid cart update input", "cart_id", id, "error", err)
+		slog.Warn("Invalid cart update input", "cart_id", id, "error", err)
 		return nil, err
 	}
 
@@ -197,16 +313,46 @@ func (s *cartService) Update(ctx context.Context, id uint, input *dto.CartItemRe
 	product, err := s.productRepo.GetProductsByID(ctx, input.ProductID)
 	if err != nil {
 		slog.Error("Product not found for update", "product_id", input.ProductID, "error", err)
-		return nil, errors.New("produk tidak ditemukan")
+		return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+
+	// Baris saved for later tidak memegang reservasi; batas per produk dan stoknya diperiksa saat
+	// baris dipindah kembali ke cart
+	if !item.SavedForLater {
+		// Batas per produk dihitung bersama baris aktif lain dengan produk yang sama (warna atau ukuran lain)
+		ownerItems, err := s.repo.FindByOwner(ctx, item.Owner())
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing cart items: %w", err)
+		}
+		active, _ := model.SplitSaved(ownerItems)
+		if err := checkProductLimit(product, input.Quantity, productQuantity(active, product.ID, item.ID)); err != nil {
+			return nil, err
+		}
+
+		// Reservasi dihitung ulang untuk quantity baru, tanpa menghitung reservasi baris ini sendiri.
+		// Reservasi untuk produk yang tidak lagi dipakai baris ini (produk diganti) ikut dilepas.
+		quantities, err := s.stockQuantities(ctx, product, input.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.inventory.ReserveAll(ctx, item.ID, item.Owner().UserID, quantities); err != nil {
+			slog.Warn("Stock reservation failed for update", "cart_id", id, "product_id", product.ID, "error", err)
+			return nil, err
+		}
 	}
 
-	// Update item dengan harga yang dihitung ulang
-	totalPrice := s.calculateTotalPrice(product.Price, input.Quantity)
+	// Update item dengan harga yang dihitung ulang; snapshot harga satuan ikut diperbarui
+	unitPrice := product.EffectivePrice()
+	totalPrice := s.calculateTotalPrice(unitPrice, input.Quantity)
 
 	item.ProductID = product.ID
 	item.Name = product.Name
 	item.Quantity = input.Quantity
-	item.Price = totalPrice
+	item.Price = totalPrice.Amount
+	item.Currency = totalPrice.Currency
+	item.UnitPrice = unitPrice.Amount
+	item.CurrentUnitPrice, item.CurrentCurrency = nil, nil
+	item.PriceChanged = false
 	item.Color = input.Color
 	item.Size = input.Size
 
@@ -215,52 +361,447 @@ func (s *cartService) Update(ctx context.Context, id uint, input *dto.CartItemRe
 		return nil, fmt.Errorf("failed to update cart item: %w", err)
 	}
 
-	slog.Info("Cart item updated successfully", "cart_id", id, "quantity", input.Quantity, "total_price", totalPrice)
+	slog.Info("Cart item updated successfully", "cart_id", id, "quantity", input.Quantity, "total_price", totalPrice.String())
 	return item, nil
 }
 
 func (s *cartService) Delete(ctx context.Context, id uint) error {
-	// Cek apakah item ada sebelum dihapus
-	_, err := s.repo.FindByID(ctx, id)
-	if err != nil {
-		slog.Error("Cart item not found for deletion", "cart_id", id, "error", err)
-		return fmt.Errorf("cart item tidak ditemukan: %w", err)
-	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// Cek apakah item ada sebelum dihapus
+		if _, err := s.repo.FindByID(ctx, id); err != nil {
+			slog.Error("Cart item not found for deletion", "cart_id", id, "error", err)
+			return fmt.Errorf("cart item tidak ditemukan: %w", err)
+		}
+		return s.removeItem(ctx, id)
+	})
+}
 
+// removeItem menghapus baris cart beserta reservasi stoknya
+func (s *cartService) removeItem(ctx context.Context, id uint) error {
 	if err := s.repo.Delete(ctx, id); err != nil {
 		slog.Error("Failed to delete cart item", "cart_id", id, "error", err)
 		return fmt.Errorf("failed to delete cart item: %w", err)
 	}
 
+	if err := s.inventory.Release(ctx, id); err != nil {
+		slog.Error("Failed to release reservation", "cart_id", id, "error", err)
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+
 	slog.Info("Cart item deleted successfully", "cart_id", id)
 	return nil
 }
 
-func (s *cartService) DeleteMany(ctx context.Context, ids []uint) error {
-	if len(ids) == 0 {
-		return nil
+// DeleteMany menghapus beberapa baris cart milik owner. Baris yang tidak ada atau milik pemilik
+// lain dianggap gagal. Lihat runBulk untuk perbedaan mode atomic dan non-atomic.
+func (s *cartService) DeleteMany(ctx context.Context, owner model.Owner, ids []uint, atomic bool) ([]model.BulkItemResult, error) {
+	results, err := s.runBulk(ctx, len(ids), atomic, func(ctx context.Context, i int) (model.BulkItemResult, error) {
+		result := model.BulkItemResult{ID: ids[i]}
+
+		item, err := s.repo.FindByID(ctx, ids[i])
+		if errors.Is(err, sql.ErrNoRows) {
+			return result, web.NewHTTPError(http.StatusNotFound, fmt.Sprintf("Cart item %d not found", ids[i]), web.ErrCartNotFound)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to fetch cart item: %w", err)
+		}
+		if !owner.Owns(item) {
+			slog.Warn("Access denied for bulk delete", "cart_id", ids[i], "owner", owner)
+			return result, web.NewHTTPError(http.StatusForbidden, fmt.Sprintf("Access denied to cart item %d", ids[i]), web.ErrCartAccessDenied)
+		}
+
+		return result, s.removeItem(ctx, ids[i])
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.repo.DeleteMany(ctx, ids); err != nil {
-		slog.Error("Failed to delete cart items", "count", len(ids), "error", err)
-		return fmt.Errorf("failed to delete cart items: %w", err)
+	slog.Info("Bulk cart items deleted", "owner", owner, "count", len(results), "failed", model.CountFailed(results), "atomic", atomic)
+	return results, nil
+}
+
+// runBulk menjalankan fn untuk setiap index 0..n-1.
+// Mode atomic memakai satu transaksi untuk semua item: item pertama yang gagal membatalkan
+// seluruh batch dan dikembalikan sebagai error ErrBulkOperationFailed berisi hasil item tersebut.
+// Mode non-atomic memakai transaksi per item sehingga item yang berhasil tetap tersimpan,
+// dan kegagalan dicatat pada hasil masing-masing item.
+func (s *cartService) runBulk(ctx context.Context, n int, atomic bool, fn func(ctx context.Context, i int) (model.BulkItemResult, error)) ([]model.BulkItemResult, error) {
+	results := make([]model.BulkItemResult, 0, n)
+
+	if atomic {
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			for i := 0; i < n; i++ {
+				result, err := fn(ctx, i)
+				result = bulkResult(i, result, err)
+				if err != nil {
+					slog.Warn("Bulk cart operation aborted", "index", i, "error", err)
+					return bulkFailure(result, err)
+				}
+				results = append(results, result)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return results, nil
 	}
 
-	slog.Info("Cart items deleted successfully", "count", len(ids))
-	return nil
+	for i := 0; i < n; i++ {
+		var result model.BulkItemResult
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			result, err = fn(ctx, i)
+			return err
+		})
+		if err != nil {
+			slog.Warn("Bulk cart item failed", "index", i, "error", err)
+		}
+		results = append(results, bulkResult(i, result, err))
+	}
+	return results, nil
+}
+
+// bulkResult melengkapi hasil item dengan index dan status dari err
+func bulkResult(index int, result model.BulkItemResult, err error) model.BulkItemResult {
+	result.Index = index
+	result.Success = err == nil
+	if err == nil {
+		return result
+	}
+
+	result.Item = nil
+	result.Error = &model.BulkItemError{Code: web.ErrProcessing, Message: err.Error()}
+	var httpErr *web.HTTPError
+	if errors.As(err, &httpErr) {
+		result.Error.Code = httpErr.ErrorCode
+	}
+	return result
+}
+
+// bulkFailure membuat error untuk batch atomic yang dibatalkan; status HTTP mengikuti penyebabnya
+func bulkFailure(result model.BulkItemResult, cause error) error {
+	status := http.StatusInternalServerError
+	var httpErr *web.HTTPError
+	if errors.As(cause, &httpErr) {
+		status = httpErr.Code
+	}
+	message := fmt.Sprintf("Item %d failed, no changes were applied: %s", result.Index+1, result.Error.Message)
+	return web.NewHTTPError(status, message, web.ErrBulkOperationFailed).WithData(result)
+}
+
+// stockQuantities mengembalikan stok yang dipakai satu baris cart per product ID: produk itu sendiri,
+// atau untuk bundle setiap komponennya dikali quantity bundle
+func (s *cartService) stockQuantities(ctx context.Context, product *productModel.Product, quantity int) (map[uint]int, error) {
+	if !product.IsBundle() {
+		return map[uint]int{product.ID: quantity}, nil
+	}
+
+	components, err := s.productRepo.GetBundleItems(ctx, []uint{product.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bundle components: %w", err)
+	}
+	if len(components) == 0 {
+		return nil, web.NewHTTPError(http.StatusConflict, "Bundle has no components", web.ErrInvalidCartData)
+	}
+
+	quantities := make(map[uint]int, len(components))
+	for _, component := range components {
+		quantities[component.ComponentID] = component.Quantity * quantity
+	}
+	return quantities, nil
 }
 
-// GetCartTotal menghitung total harga semua item di cart user
-func (s *cartService) GetCartTotal(ctx context.Context, userID uint) (float64, error) {
-	items, err := s.GetByUserID(ctx, userID)
+// loadCart memuat baris aktif cart yang sudah di-reprice beserta kupon yang terpasang dengan satu
+// query repository. Aturan pajak, kupon dan komponen bundle tetap dimuat lewat service modulnya.
+func (s *cartService) loadCart(ctx context.Context, owner model.Owner) ([]model.CartItem, *uint, error) {
+	all, couponID, err := s.repo.FindByOwnerWithCoupon(ctx, owner)
 	if err != nil {
-		return 0, err
+		slog.Error("Failed to fetch cart", "owner", owner, "error", err)
+		return nil, nil, fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+	items, _ := model.SplitSaved(all)
+	for i := range items {
+		items[i].Reprice()
+	}
+	return items, couponID, nil
+}
+
+// GetCartTotal menghitung total harga semua item di cart user beserta rincian per baris, diskon
+// kupon yang terpasang dan pajak untuk wilayah region. Semua item harus memakai mata uang yang
+// sama; cart kosong bernilai nol dalam mata uang default.
+func (s *cartService) GetCartTotal(ctx context.Context, owner model.Owner, region taxModel.Region) (*dto.CartTotalResponse, error) {
+	items, couponID, err := s.loadCart(ctx, owner)
+	if err != nil {
+		return nil, err
 	}
 
-	var total float64
+	subtotal, count, err := sumLines(owner, items)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts, coupon, err := s.cartDiscounts(ctx, owner, items, couponID)
+	if err != nil {
+		return nil, err
+	}
+	discountTotal, total, err := applyDiscounts(subtotal, discounts)
+	if err != nil {
+		return nil, err
+	}
+
+	region = s.taxRegion(region)
+	tax, err := s.cartTax(ctx, region, items, discounts)
+	if err != nil {
+		return nil, err
+	}
+	if total, err = total.Add(tax.Exclusive); err != nil {
+		return nil, err
+	}
+
+	components, err := s.bundleComponents(ctx, items)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]dto.CartItemTotal, 0, len(items))
+	for i, item := range items {
+		line := dto.CartItemTotal{
+			CartItemID:   item.ID,
+			ProductID:    item.ProductID,
+			ProductName:  item.Name,
+			Price:        item.CurrentPrice(),
+			Quantity:     item.Quantity,
+			Subtotal:     item.LinePrice(),
+			PriceChanged: item.PriceChanged,
+		}
+		if lineTax := tax.Lines[i]; lineTax.Rule != nil {
+			line.Tax = &dto.CartLineTax{
+				Name:      lineTax.Rule.Name,
+				Rate:      lineTax.Rule.Rate,
+				Inclusive: lineTax.Rule.Inclusive,
+				Amount:    lineTax.Tax,
+			}
+		}
+		for _, component := range components[item.ProductID] {
+			line.Components = append(line.Components, dto.CartBundleComponent{
+				ProductID:         component.ComponentID,
+				Name:              component.Name,
+				QuantityPerBundle: component.Quantity,
+				Quantity:          component.Quantity * item.Quantity,
+			})
+		}
+		lines = append(lines, line)
+	}
+
+	taxes := make([]dto.CartTax, 0, len(tax.Breakdown))
+	for _, breakdown := range tax.Breakdown {
+		taxes = append(taxes, dto.CartTax{
+			Name:          breakdown.Name,
+			Rate:          breakdown.Rate,
+			Inclusive:     breakdown.Inclusive,
+			TaxableAmount: breakdown.Taxable,
+			Amount:        breakdown.Amount,
+		})
+	}
+
+	return &dto.CartTotalResponse{
+		UserID:        owner.UserID,
+		TotalItems:    count,
+		Subtotal:      subtotal,
+		Discounts:     discounts,
+		DiscountTotal: discountTotal,
+		Taxes:         taxes,
+		TaxTotal:      tax.Total,
+		TotalAmount:   total,
+		TaxRegion:     region.String(),
+		Currency:      total.Currency,
+		Coupon:        coupon,
+		Items:         lines,
+		CalculatedAt:  time.Now(),
+	}, nil
+}
+
+// GetCartSummary mengembalikan jumlah unit dan total cart tanpa rincian per baris
+func (s *cartService) GetCartSummary(ctx context.Context, owner model.Owner, region taxModel.Region) (*dto.CartSummary, error) {
+	items, couponID, err := s.loadCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	subtotal, count, err := sumLines(owner, items)
+	if err != nil {
+		return nil, err
+	}
+
+	discounts, _, err := s.cartDiscounts(ctx, owner, items, couponID)
+	if err != nil {
+		return nil, err
+	}
+	discountTotal, total, err := applyDiscounts(subtotal, discounts)
+	if err != nil {
+		return nil, err
+	}
+	freeShipping := toFreeShippingProgress(s.shipping.FreeShippingProgress(total))
+	tax, err := s.cartTax(ctx, s.taxRegion(region), items, discounts)
+	if err != nil {
+		return nil, err
+	}
+	if total, err = total.Add(tax.Exclusive); err != nil {
+		return nil, err
+	}
+
+	return &dto.CartSummary{
+		UserID:        owner.UserID,
+		ItemCount:     count,
+		DiscountTotal: discountTotal,
+		TaxTotal:      tax.Total,
+		TotalAmount:   total,
+		Currency:      total.Currency,
+		IsEmpty:       len(items) == 0,
+		FreeShipping:  freeShipping,
+	}, nil
+}
+
+// sumLines menjumlahkan harga baris yang sudah di-reprice dan jumlah unitnya
+func sumLines(owner model.Owner, items []model.CartItem) (types.Money, int, error) {
+	total := types.NewMoney(0, types.DefaultCurrency())
+	if len(items) > 0 {
+		total = types.NewMoney(0, items[0].Currency)
+	}
+
+	count := 0
+	for _, item := range items {
+		var err error
+		total, err = total.Add(item.LinePrice())
+		if err != nil {
+			slog.Error("Cart contains mixed currencies", "owner", owner, "error", err)
+			return types.Money{}, 0, web.NewHTTPError(http.StatusConflict, "Cart contains items in different currencies", web.ErrInvalidCartData)
+		}
+		count += item.Quantity
+	}
+	return total, count, nil
+}
+
+// bundleComponents memuat komponen untuk baris bundle saja, dikelompokkan per product ID bundle
+func (s *cartService) bundleComponents(ctx context.Context, items []model.CartItem) (map[uint][]productModel.BundleItem, error) {
+	var bundleIDs []uint
+	for _, item := range items {
+		if item.IsBundle() {
+			bundleIDs = append(bundleIDs, item.ProductID)
+		}
+	}
+	if len(bundleIDs) == 0 {
+		return nil, nil
+	}
+
+	components, err := s.productRepo.GetBundleItems(ctx, bundleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load bundle components: %w", err)
+	}
+
+	byBundle := make(map[uint][]productModel.BundleItem)
+	for _, component := range components {
+		byBundle[component.BundleID] = append(byBundle[component.BundleID], component)
+	}
+	return byBundle, nil
+}
+
+// Validate memeriksa setiap baris cart terhadap harga dan stok saat ini tanpa mengubah cart.
+// Stok dihitung tanpa reservasi baris itu sendiri, sama seperti saat quantity diubah.
+func (s *cartService) Validate(ctx context.Context, owner model.Owner) (*dto.CartValidationResponse, error) {
+	items, err := s.GetByOwner(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.CartValidationResponse{
+		Valid:     true,
+		Lines:     make([]dto.CartLineValidation, 0, len(items)),
+		CheckedAt: time.Now(),
+	}
 	for _, item := range items {
-		total += item.Price
+		line, err := s.validateLine(ctx, &item)
+		if err != nil {
+			return nil, err
+		}
+		if len(line.Issues) > 0 {
+			result.Valid = false
+		}
+		result.Lines = append(result.Lines, line)
 	}
 
-	return total, nil
+	slog.Info("Cart validated", "owner", owner, "lines", len(items), "valid", result.Valid)
+	return result, nil
+}
+
+// validateLine mengumpulkan masalah harga dan stok untuk satu baris cart
+func (s *cartService) validateLine(ctx context.Context, item *model.CartItem) (dto.CartLineValidation, error) {
+	line := dto.CartLineValidation{
+		CartItemID:     item.ID,
+		ProductID:      item.ProductID,
+		Name:           item.Name,
+		Quantity:       item.Quantity,
+		AddedUnitPrice: item.AddedUnitPrice(),
+		Issues:         []string{},
+	}
+
+	if item.CurrentUnitPrice == nil {
+		line.Issues = append(line.Issues, model.IssueProductUnavailable)
+		return line, nil
+	}
+
+	current := item.CurrentPrice()
+	line.CurrentUnitPrice = &current
+	if item.PriceChanged {
+		line.Issues = append(line.Issues, model.IssuePriceChanged)
+	}
+
+	product, err := s.productRepo.GetProductsByID(ctx, item.ProductID)
+	if err != nil {
+		return line, fmt.Errorf("failed to fetch product %d: %w", item.ProductID, err)
+	}
+	quantities, err := s.stockQuantities(ctx, product, 1)
+	if err != nil {
+		var httpErr *web.HTTPError
+		if errors.As(err, &httpErr) {
+			line.Issues = append(line.Issues, model.IssueProductUnavailable)
+			return line, nil
+		}
+		return line, err
+	}
+
+	available, err := s.availableUnits(ctx, quantities, item.ID)
+	if err != nil {
+		return line, err
+	}
+	line.AvailableQuantity = &available
+
+	switch {
+	case available == 0:
+		line.Issues = append(line.Issues, model.IssueOutOfStock)
+	case available < item.Quantity:
+		line.Issues = append(line.Issues, model.IssueInsufficientStock)
+	}
+	return line, nil
+}
+
+// availableUnits menghitung quantity terbesar yang bisa dipakai satu baris cart dari stok saat ini,
+// tanpa menghitung reservasi baris cartItemID sendiri. perUnit adalah hasil stockQuantities untuk
+// quantity 1; untuk bundle hasilnya jumlah bundle yang bisa dirakit dari komponen.
+func (s *cartService) availableUnits(ctx context.Context, perUnit map[uint]int, cartItemID uint) (int, error) {
+	available := -1
+	for productID, quantity := range perUnit {
+		stock, err := s.inventory.AvailableStock(ctx, productID, cartItemID)
+		if err != nil {
+			return 0, err
+		}
+		if units := stock / quantity; available < 0 || units < available {
+			available = units
+		}
+	}
+	if available < 0 {
+		available = 0
+	}
+	return available, nil
 }
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-fiber-api/internal/app/cart/model"
+	couponModel "go-fiber-api/internal/app/coupon/model"
+	taxModel "go-fiber-api/internal/app/tax/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+	"log/slog"
+	"net/http"
+)
+
+// ApplyCoupon memasang kupon ke cart, menggantikan kupon sebelumnya. Kupon harus berlaku untuk
+// isi cart saat ini; setelah terpasang, diskonnya dihitung ulang setiap kali total cart dibaca.
+func (s *cartService) ApplyCoupon(ctx context.Context, owner model.Owner, code string) error {
+	coupon, err := s.coupons.FindByCode(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	items, err := s.GetByOwner(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if _, _, err := sumLines(owner, items); err != nil {
+		return err
+	}
+
+	if _, err := s.coupons.Evaluate(ctx, coupon, owner.UserID, couponLines(items)); err != nil {
+		var rejection *couponModel.Rejection
+		if errors.As(err, &rejection) {
+			slog.Info("Coupon rejected", "owner", owner, "code", coupon.Code, "reason", rejection.Reason)
+			return web.NewHTTPError(http.StatusUnprocessableEntity, rejection.Message, web.ErrCouponNotApplicable).
+				WithData(map[string]string{"code": coupon.Code, "reason": rejection.Reason})
+		}
+		return err
+	}
+
+	if err := s.repo.SetCartCoupon(ctx, owner, coupon.ID); err != nil {
+		slog.Error("Failed to apply coupon", "owner", owner, "code", coupon.Code, "error", err)
+		return fmt.Errorf("failed to apply coupon: %w", err)
+	}
+
+	slog.Info("Coupon applied", "owner", owner, "code", coupon.Code)
+	return nil
+}
+
+// RemoveCoupon melepas kupon dari cart
+func (s *cartService) RemoveCoupon(ctx context.Context, owner model.Owner) error {
+	deleted, err := s.repo.DeleteCartCoupon(ctx, owner)
+	if err != nil {
+		slog.Error("Failed to remove coupon", "owner", owner, "error", err)
+		return fmt.Errorf("failed to remove coupon: %w", err)
+	}
+	if !deleted {
+		return web.NewHTTPError(http.StatusNotFound, "No coupon applied to this cart", web.ErrCouponNotFound)
+	}
+
+	slog.Info("Coupon removed", "owner", owner)
+	return nil
+}
+
+// RedeemCoupon mencatat pemakaian kupon yang terpasang di cart user saat cart di-checkout, lalu
+// melepasnya dari cart agar tidak dipakai dua kali. Pemakaian inilah yang dihitung oleh usage_limit
+// dan per_user_limit. Dikembalikan total cart beserta diskon yang ditebus.
+func (s *cartService) RedeemCoupon(ctx context.Context, owner model.Owner, region taxModel.Region) (*dto.CartTotalResponse, error) {
+	var total *dto.CartTotalResponse
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		couponID, err := s.repo.FindCartCoupon(ctx, owner)
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.NewHTTPError(http.StatusNotFound, "No coupon applied to this cart", web.ErrCouponNotFound)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to fetch cart coupon: %w", err)
+		}
+
+		if total, err = s.GetCartTotal(ctx, owner, region); err != nil {
+			return err
+		}
+		if total.Coupon == nil || !total.Coupon.Applied {
+			data := map[string]string{}
+			if total.Coupon != nil {
+				data["code"], data["reason"] = total.Coupon.Code, total.Coupon.Reason
+			}
+			return web.NewHTTPError(http.StatusUnprocessableEntity, "Coupon does not apply to this cart", web.ErrCouponNotApplicable).
+				WithData(data)
+		}
+
+		// Redeem mengunci kupon dan memeriksa ulang batas pemakaian sebelum mencatatnya
+		if err := s.coupons.Redeem(ctx, couponID, owner.UserID); err != nil {
+			return err
+		}
+
+		// Checkout paralel untuk cart yang sama hanya boleh menebus kupon sekali
+		deleted, err := s.repo.DeleteCartCoupon(ctx, owner)
+		if err != nil {
+			return fmt.Errorf("failed to remove coupon: %w", err)
+		}
+		if !deleted {
+			return web.NewHTTPError(http.StatusConflict, "Coupon was already redeemed for this cart", web.ErrConflict)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("Failed to redeem coupon", "owner", owner, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Coupon redeemed for cart", "owner", owner, "code", total.Coupon.Code)
+	return total, nil
+}
+
+// cartDiscounts menghitung ulang diskon kupon yang terpasang untuk isi cart saat ini. Kupon yang
+// tidak lagi berlaku tetap terpasang, tapi tidak menghasilkan diskon sampai syaratnya terpenuhi lagi.
+// couponID berasal dari loadCart; nil berarti cart tidak memakai kupon.
+func (s *cartService) cartDiscounts(ctx context.Context, owner model.Owner, items []model.CartItem, couponID *uint) ([]dto.CartDiscount, *dto.CartCoupon, error) {
+	discounts := []dto.CartDiscount{}
+	if couponID == nil {
+		return discounts, nil, nil
+	}
+
+	coupon, err := s.coupons.FindByID(ctx, *couponID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status := &dto.CartCoupon{Code: coupon.Code}
+	discount, err := s.coupons.Evaluate(ctx, coupon, owner.UserID, couponLines(items))
+	var rejection *couponModel.Rejection
+	if errors.As(err, &rejection) {
+		status.Reason = rejection.Reason
+		status.Message = rejection.Message
+		return discounts, status, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	status.Applied = true
+	discounts = append(discounts, dto.CartDiscount{
+		Code:        coupon.Code,
+		Description: coupon.Description,
+		Amount:      discount.Amount,
+		CartItemIDs: discount.CartItemIDs,
+	})
+	return discounts, status, nil
+}
+
+// applyDiscounts mengurangi subtotal dengan semua diskon; total tidak pernah di bawah nol
+func applyDiscounts(subtotal types.Money, discounts []dto.CartDiscount) (types.Money, types.Money, error) {
+	discountTotal := types.NewMoney(0, subtotal.Currency)
+	for _, discount := range discounts {
+		var err error
+		if discountTotal, err = discountTotal.Add(discount.Amount); err != nil {
+			return types.Money{}, types.Money{}, err
+		}
+	}
+	discountTotal.Amount = min(discountTotal.Amount, subtotal.Amount)
+
+	total, err := subtotal.Sub(discountTotal)
+	return discountTotal, total, err
+}
+
+// couponLines mengubah baris cart (yang sudah di-reprice) menjadi input evaluasi kupon
+func couponLines(items []model.CartItem) []couponModel.Line {
+	lines := make([]couponModel.Line, 0, len(items))
+	for _, item := range items {
+		line := couponModel.Line{
+			CartItemID: item.ID,
+			ProductID:  item.ProductID,
+			Amount:     item.LinePrice(),
+		}
+		if item.ProductCategory != nil {
+			line.Category = *item.ProductCategory
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
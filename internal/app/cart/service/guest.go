@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"go-fiber-api/internal/app/cart/model"
+	"go-fiber-api/internal/shared/dto"
+	utils "go-fiber-api/utils/jwt"
+	"go-fiber-api/utils/web"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// StartGuestCart membuat guest cart baru dan cart token untuk mengaksesnya
+func (s *cartService) StartGuestCart(ctx context.Context) (model.Owner, string, error) {
+	guest, err := s.repo.CreateGuestCart(ctx, time.Now().Add(s.config.GuestTTL))
+	if err != nil {
+		return model.Owner{}, "", fmt.Errorf("failed to create guest cart: %w", err)
+	}
+
+	token, err := utils.GenerateCartToken(guest.ID, guest.ExpiresAt)
+	if err != nil {
+		slog.Error("Failed to sign cart token", "guest_cart_id", guest.ID, "error", err)
+		return model.Owner{}, "", fmt.Errorf("failed to sign cart token: %w", err)
+	}
+
+	slog.Info("Guest cart created", "guest_cart_id", guest.ID, "expires_at", guest.ExpiresAt)
+	return model.GuestOwner(guest.ID), token, nil
+}
+
+// ResolveGuestCart memverifikasi cart token dan memastikan guest cart-nya masih ada.
+// Guest cart yang sudah digabung ke cart user atau kedaluwarsa dianggap token tidak valid.
+func (s *cartService) ResolveGuestCart(ctx context.Context, token string) (model.Owner, error) {
+	guestCartID, err := utils.ParseCartToken(token)
+	if err != nil {
+		return model.Owner{}, web.NewHTTPError(http.StatusUnauthorized, "Invalid or expired cart token", web.ErrAuthentication)
+	}
+
+	if _, err := s.repo.FindGuestCart(ctx, guestCartID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Owner{}, web.NewHTTPError(http.StatusUnauthorized, "Invalid or expired cart token", web.ErrAuthentication)
+		}
+		return model.Owner{}, fmt.Errorf("failed to fetch guest cart: %w", err)
+	}
+	return model.GuestOwner(guestCartID), nil
+}
+
+// MergeGuestCart memindahkan isi guest cart ke cart user lalu menghapus guest cart-nya.
+// Baris dengan produk, warna dan ukuran yang sama digabung seperti Create; quantity dibatasi
+// stok yang tersedia dan batas cart, dan baris yang produknya sudah tidak ada, stoknya habis
+// atau tidak muat lagi di cart user dilewati. Baris saved for later tetap saved for later.
+func (s *cartService) MergeGuestCart(ctx context.Context, userID uint, token string) (*dto.CartMergeResult, error) {
+	guest, err := s.ResolveGuestCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	user := model.UserOwner(userID)
+
+	result := &dto.CartMergeResult{Lines: []dto.CartMergeLine{}}
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		guestItems, err := s.repo.FindByOwner(ctx, guest)
+		if err != nil {
+			return fmt.Errorf("failed to fetch guest cart items: %w", err)
+		}
+		ownerItems, err := s.repo.FindByOwner(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to fetch cart items: %w", err)
+		}
+		userItems, userSaved := model.SplitSaved(ownerItems)
+
+		for i := range guestItems {
+			var line dto.CartMergeLine
+			if guestItems[i].SavedForLater {
+				line, err = s.mergeSavedLine(ctx, user, &guestItems[i], &userSaved)
+			} else {
+				line, err = s.mergeLine(ctx, user, &guestItems[i], &userItems)
+			}
+			if err != nil {
+				return err
+			}
+			if line.Quantity < line.Requested {
+				result.Adjusted++
+			}
+			result.Lines = append(result.Lines, line)
+		}
+
+		if err := s.repo.TransferGuestCoupon(ctx, guest.GuestCartID, userID); err != nil {
+			return fmt.Errorf("failed to move guest cart coupon: %w", err)
+		}
+		if err := s.repo.DeleteGuestCart(ctx, guest.GuestCartID); err != nil {
+			return fmt.Errorf("failed to delete guest cart: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to merge guest cart", "guest", guest, "user_id", userID, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Guest cart merged", "guest", guest, "user_id", userID, "lines", len(result.Lines), "adjusted", result.Adjusted)
+	return result, nil
+}
+
+// mergeLine memindahkan satu baris guest ke cart user. userItems ikut diperbarui agar baris
+// guest berikutnya bisa digabung ke baris yang baru dipindah.
+func (s *cartService) mergeLine(ctx context.Context, user model.Owner, item *model.CartItem, userItems *[]model.CartItem) (dto.CartMergeLine, error) {
+	line := dto.CartMergeLine{
+		ProductID: item.ProductID,
+		Color:     item.Color,
+		Size:      item.Size,
+		Requested: item.Quantity,
+	}
+
+	product, err := s.productRepo.GetProductsByID(ctx, item.ProductID)
+	if errors.Is(err, sql.ErrNoRows) {
+		line.Action = model.MergeDropped
+		return line, s.removeItem(ctx, item.ID)
+	}
+	if err != nil {
+		return line, fmt.Errorf("failed to fetch product %d: %w", item.ProductID, err)
+	}
+	perUnit, err := s.stockQuantities(ctx, product, 1)
+	if err != nil {
+		return line, err
+	}
+
+	if existing := model.FindLine(*userItems, item.ProductID, item.Color, item.Size); existing != nil {
+		// Reservasi baris guest dilepas dulu supaya stoknya bisa dipakai baris user
+		if err := s.removeItem(ctx, item.ID); err != nil {
+			return line, err
+		}
+		available, err := s.availableUnits(ctx, perUnit, existing.ID)
+		if err != nil {
+			return line, err
+		}
+
+		line.Action = model.MergeCombined
+		line.Requested = existing.Quantity + item.Quantity
+		limit := s.quantityCap(product, productQuantity(*userItems, product.ID, existing.ID))
+		if quantity := min(line.Requested, available, limit); quantity > existing.Quantity {
+			updated, err := s.updateItem(ctx, existing.ID, &dto.CartItemRequest{
+				ProductID: existing.ProductID,
+				Quantity:  quantity,
+				Color:     existing.Color,
+				Size:      existing.Size,
+			})
+			if err != nil {
+				return line, err
+			}
+			*existing = *updated
+		}
+		line.Quantity = existing.Quantity
+		return line, nil
+	}
+
+	available, err := s.availableUnits(ctx, perUnit, item.ID)
+	if err != nil {
+		return line, err
+	}
+	quantity := min(item.Quantity, available, s.quantityCap(product, productQuantity(*userItems, product.ID, 0)))
+	if quantity <= 0 || s.checkLineCount(len(*userItems)) != nil {
+		line.Action = model.MergeDropped
+		return line, s.removeItem(ctx, item.ID)
+	}
+
+	if err := s.repo.AssignToUser(ctx, item.ID, user.UserID); err != nil {
+		return line, fmt.Errorf("failed to move cart item %d: %w", item.ID, err)
+	}
+	item.UserID, item.GuestCartID = &user.UserID, nil
+
+	if quantity < item.Quantity {
+		updated, err := s.updateItem(ctx, item.ID, &dto.CartItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  quantity,
+			Color:     item.Color,
+			Size:      item.Size,
+		})
+		if err != nil {
+			return line, err
+		}
+		item = updated
+	} else {
+		// Reservasi diperbarui agar tercatat atas nama user dan masa berlakunya diperpanjang
+		quantities := make(map[uint]int, len(perUnit))
+		for productID, perBundle := range perUnit {
+			quantities[productID] = perBundle * quantity
+		}
+		if err := s.inventory.ReserveAll(ctx, item.ID, user.UserID, quantities); err != nil {
+			return line, err
+		}
+	}
+
+	line.Action = model.MergeMoved
+	line.Quantity = item.Quantity
+	*userItems = append(*userItems, *item)
+	return line, nil
+}
+
+// mergeSavedLine memindahkan satu baris saved for later guest ke daftar saved for later user.
+// Baris ini tidak memegang reservasi sehingga stok tidak diperiksa; quantity hanya dibatasi
+// MaxLineQuantity saat digabung dengan baris user yang sama.
+func (s *cartService) mergeSavedLine(ctx context.Context, user model.Owner, item *model.CartItem, userSaved *[]model.CartItem) (dto.CartMergeLine, error) {
+	line := dto.CartMergeLine{
+		ProductID:     item.ProductID,
+		Color:         item.Color,
+		Size:          item.Size,
+		SavedForLater: true,
+		Requested:     item.Quantity,
+	}
+
+	if existing := model.FindLine(*userSaved, item.ProductID, item.Color, item.Size); existing != nil {
+		if err := s.removeItem(ctx, item.ID); err != nil {
+			return line, err
+		}
+
+		line.Action = model.MergeCombined
+		line.Requested = existing.Quantity + item.Quantity
+		quantity := line.Requested
+		if limit := s.config.MaxLineQuantity; limit > 0 {
+			quantity = min(quantity, limit)
+		}
+		if quantity > existing.Quantity {
+			if _, err := s.combineSaved(ctx, existing, quantity); err != nil {
+				return line, err
+			}
+		}
+		line.Quantity = existing.Quantity
+		return line, nil
+	}
+
+	if s.checkLineCount(len(*userSaved)) != nil {
+		line.Action = model.MergeDropped
+		return line, s.removeItem(ctx, item.ID)
+	}
+	if err := s.repo.AssignToUser(ctx, item.ID, user.UserID); err != nil {
+		return line, fmt.Errorf("failed to move cart item %d: %w", item.ID, err)
+	}
+	item.UserID, item.GuestCartID = &user.UserID, nil
+
+	line.Action = model.MergeMoved
+	line.Quantity = item.Quantity
+	*userSaved = append(*userSaved, *item)
+	return line, nil
+}
+
+// RunGuestCartSweeper menghapus guest cart kedaluwarsa secara berkala sampai ctx selesai
+func (s *cartService) RunGuestCartSweeper(ctx context.Context, interval time.Duration) {
+	slog.Info("Guest cart sweeper started", "interval", interval, "ttl", s.config.GuestTTL)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Guest cart sweeper stopped")
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteExpiredGuestCarts(ctx)
+			if err != nil {
+				slog.Error("Failed to delete expired guest carts", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				slog.Info("Expired guest carts deleted", "count", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"fmt"
+	"go-fiber-api/internal/app/cart/model"
+	productModel "go-fiber-api/internal/app/product/model"
+	"go-fiber-api/utils/web"
+	"math"
+	"net/http"
+)
+
+// checkLineQuantity memastikan quantity satu baris lebih dari nol dan tidak melewati MaxLineQuantity
+func (s *cartService) checkLineQuantity(quantity int) error {
+	if quantity <= 0 {
+		return web.NewHTTPError(http.StatusBadRequest, "Quantity must be greater than 0", web.ErrInvalidQuantity)
+	}
+	if limit := s.config.MaxLineQuantity; limit > 0 && quantity > limit {
+		return web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Quantity cannot exceed %d per item", limit), web.ErrInvalidQuantity).
+			WithData(map[string]int{"max_line_quantity": limit})
+	}
+	return nil
+}
+
+// checkLineCount menolak baris baru jika cart sudah berisi MaxLines baris
+func (s *cartService) checkLineCount(lines int) error {
+	if limit := s.config.MaxLines; limit > 0 && lines >= limit {
+		return web.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("Cart cannot contain more than %d different items", limit), web.ErrCartLimitExceeded).
+			WithData(map[string]int{"max_lines": limit})
+	}
+	return nil
+}
+
+// checkProductLimit memastikan total quantity produk di cart tidak melewati max_per_order-nya.
+// others adalah quantity produk yang sama di baris lain (warna atau ukuran berbeda).
+func checkProductLimit(product *productModel.Product, quantity, others int) error {
+	if product.MaxPerOrder == nil || quantity+others <= *product.MaxPerOrder {
+		return nil
+	}
+	return web.NewHTTPError(http.StatusUnprocessableEntity,
+		fmt.Sprintf("Product %d is limited to %d per order", product.ID, *product.MaxPerOrder), web.ErrCartLimitExceeded).
+		WithData(map[string]any{"product_id": product.ID, "max_per_order": *product.MaxPerOrder, "in_cart": others})
+}
+
+// quantityCap adalah quantity terbesar yang diizinkan batas cart untuk satu baris. Dipakai saat
+// merge guest cart, yang memotong quantity alih-alih menolak barisnya.
+func (s *cartService) quantityCap(product *productModel.Product, others int) int {
+	limit := math.MaxInt
+	if s.config.MaxLineQuantity > 0 {
+		limit = s.config.MaxLineQuantity
+	}
+	if product.MaxPerOrder != nil {
+		limit = min(limit, *product.MaxPerOrder-others)
+	}
+	return max(limit, 0)
+}
+
+// productQuantity menjumlahkan quantity productID di baris cart selain excludeID
+func productQuantity(items []model.CartItem, productID, excludeID uint) int {
+	total := 0
+	for _, item := range items {
+		if item.ProductID == productID && item.ID != excludeID {
+			total += item.Quantity
+		}
+	}
+	return total
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"go-fiber-api/internal/app/cart/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"log/slog"
+)
+
+// GetSavedForLater mengembalikan baris saved for later yang sudah di-reprice beserta stok yang
+// tersedia saat ini, agar pelanggan tahu barang mana yang masih bisa dipindah ke cart
+func (s *cartService) GetSavedForLater(ctx context.Context, owner model.Owner) ([]model.CartItem, error) {
+	all, err := s.repo.FindByOwner(ctx, owner)
+	if err != nil {
+		slog.Error("Failed to fetch saved cart items", "owner", owner, "error", err)
+		return nil, fmt.Errorf("failed to fetch cart items: %w", err)
+	}
+
+	_, saved := model.SplitSaved(all)
+	for i := range saved {
+		item := &saved[i]
+		item.Reprice()
+
+		line, err := s.validateLine(ctx, item)
+		if err != nil {
+			return nil, err
+		}
+		item.AvailableQuantity = line.AvailableQuantity
+		item.Issues = line.Issues
+	}
+	return saved, nil
+}
+
+// SaveForLater memindahkan baris aktif ke daftar saved for later dan melepas reservasi stoknya.
+// Jika sudah ada baris saved for later dengan produk, warna dan ukuran yang sama, quantity-nya
+// digabung ke baris tersebut.
+func (s *cartService) SaveForLater(ctx context.Context, id uint) (*model.CartItem, error) {
+	var item *model.CartItem
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = s.saveForLater(ctx, id)
+		return err
+	})
+	if err != nil {
+		slog.Error("Failed to save cart item for later", "cart_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Cart item saved for later", "cart_id", item.ID, "quantity", item.Quantity)
+	return item, nil
+}
+
+func (s *cartService) saveForLater(ctx context.Context, id uint) (*model.CartItem, error) {
+	item, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("cart item tidak ditemukan: %w", err)
+	}
+	if item.SavedForLater {
+		item.Reprice()
+		return item, nil
+	}
+
+	ownerItems, err := s.repo.FindByOwner(ctx, item.Owner())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing cart items: %w", err)
+	}
+	_, saved := model.SplitSaved(ownerItems)
+
+	if existing := model.FindLine(saved, item.ProductID, item.Color, item.Size); existing != nil {
+		merged, err := s.combineSaved(ctx, existing, existing.Quantity+item.Quantity)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.removeItem(ctx, item.ID); err != nil {
+			return nil, err
+		}
+		return merged, nil
+	}
+
+	if err := s.checkLineCount(len(saved)); err != nil {
+		return nil, err
+	}
+	if err := s.inventory.Release(ctx, item.ID); err != nil {
+		return nil, fmt.Errorf("failed to release reservation: %w", err)
+	}
+	if err := s.repo.SetSavedForLater(ctx, item.ID, true); err != nil {
+		return nil, fmt.Errorf("failed to save cart item for later: %w", err)
+	}
+
+	item.SavedForLater = true
+	item.Reprice()
+	return item, nil
+}
+
+// combineSaved mengubah quantity baris saved for later. Harga baris dihitung dari snapshot harga
+// satuannya karena baris ini tidak di-reprice sampai dipindah ke cart.
+func (s *cartService) combineSaved(ctx context.Context, item *model.CartItem, quantity int) (*model.CartItem, error) {
+	if err := s.checkLineQuantity(quantity); err != nil {
+		return nil, err
+	}
+
+	line := types.NewMoney(item.UnitPrice, item.Currency).Mul(quantity)
+	item.Quantity = quantity
+	item.Price, item.Currency = line.Amount, line.Currency
+	if err := s.repo.Update(ctx, item); err != nil {
+		return nil, fmt.Errorf("failed to update cart item: %w", err)
+	}
+
+	item.Reprice()
+	return item, nil
+}
+
+// MoveToCart memindahkan baris saved for later kembali ke cart. Aturannya sama seperti menambah
+// item: batas cart dan batas per produk diperiksa, stok direservasi dan snapshot harga diperbarui.
+// Jika sudah ada baris aktif dengan produk, warna dan ukuran yang sama, quantity-nya digabung.
+func (s *cartService) MoveToCart(ctx context.Context, id uint) (*model.CartItem, error) {
+	var item *model.CartItem
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		item, err = s.moveToCart(ctx, id)
+		return err
+	})
+	if err != nil {
+		slog.Error("Failed to move cart item to cart", "cart_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Saved cart item moved to cart", "cart_id", item.ID, "quantity", item.Quantity)
+	return item, nil
+}
+
+func (s *cartService) moveToCart(ctx context.Context, id uint) (*model.CartItem, error) {
+	item, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("cart item tidak ditemukan: %w", err)
+	}
+	if !item.SavedForLater {
+		item.Reprice()
+		return item, nil
+	}
+
+	ownerItems, err := s.repo.FindByOwner(ctx, item.Owner())
+	if err != nil {
+		return nil, fmt.Errorf("failed to check existing cart items: %w", err)
+	}
+	active, _ := model.SplitSaved(ownerItems)
+
+	if existing := model.FindLine(active, item.ProductID, item.Color, item.Size); existing != nil {
+		merged, err := s.updateItem(ctx, existing.ID, &dto.CartItemRequest{
+			ProductID: existing.ProductID,
+			Quantity:  existing.Quantity + item.Quantity,
+			Color:     existing.Color,
+			Size:      existing.Size,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := s.removeItem(ctx, item.ID); err != nil {
+			return nil, err
+		}
+		return merged, nil
+	}
+
+	if err := s.checkLineCount(len(active)); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetSavedForLater(ctx, item.ID, false); err != nil {
+		return nil, fmt.Errorf("failed to move cart item to cart: %w", err)
+	}
+
+	// updateItem membaca ulang baris yang sudah aktif, sehingga reservasi dan batas per produk ikut diperiksa
+	return s.updateItem(ctx, item.ID, &dto.CartItemRequest{
+		ProductID: item.ProductID,
+		Quantity:  item.Quantity,
+		Color:     item.Color,
+		Size:      item.Size,
+	})
+}
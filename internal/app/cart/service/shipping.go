@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"go-fiber-api/internal/app/cart/model"
+	shippingModel "go-fiber-api/internal/app/shipping/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/utils/web"
+	"log/slog"
+	"net/http"
+)
+
+// QuoteShipping menghitung ongkos kirim isi cart ke alamat tujuan. Berat paket adalah jumlah
+// berat yang ditagih setiap unit; nilai paket untuk free shipping adalah total setelah diskon.
+func (s *cartService) QuoteShipping(ctx context.Context, owner model.Owner, req *dto.ShippingQuoteRequest) (*dto.ShippingQuoteResponse, error) {
+	items, couponID, err := s.loadCart(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Cart is empty", web.ErrCartEmpty)
+	}
+
+	subtotal, _, err := sumLines(owner, items)
+	if err != nil {
+		return nil, err
+	}
+	discounts, _, err := s.cartDiscounts(ctx, owner, items, couponID)
+	if err != nil {
+		return nil, err
+	}
+	_, value, err := applyDiscounts(subtotal, discounts)
+	if err != nil {
+		return nil, err
+	}
+
+	parcel := shippingModel.Parcel{WeightGrams: cartWeight(items), Value: value}
+	destination := shippingModel.Destination{Country: req.Country, PostalCode: req.PostalCode}
+	zone, quotes, err := s.shipping.Quote(ctx, destination, parcel)
+	if err != nil {
+		slog.Error("Failed to quote shipping", "owner", owner, "country", req.Country, "postal_code", req.PostalCode, "error", err)
+		return nil, err
+	}
+
+	methods := make([]dto.ShippingMethodQuote, 0, len(quotes))
+	for _, quote := range quotes {
+		methods = append(methods, dto.ShippingMethodQuote{
+			Code:    quote.Code,
+			Name:    quote.Name,
+			Price:   quote.Price,
+			Free:    quote.Free,
+			MinDays: quote.MinDays,
+			MaxDays: quote.MaxDays,
+		})
+	}
+
+	slog.Info("Shipping quoted", "owner", owner, "zone_id", zone.ID, "weight_grams", parcel.WeightGrams, "methods", len(methods))
+	return &dto.ShippingQuoteResponse{
+		Country:      zone.Country,
+		PostalCode:   req.PostalCode,
+		Zone:         zone.Name,
+		WeightGrams:  parcel.WeightGrams,
+		Methods:      methods,
+		FreeShipping: toFreeShippingProgress(s.shipping.FreeShippingProgress(value)),
+	}, nil
+}
+
+// cartWeight menjumlahkan berat yang ditagih semua baris. Bundle memakai berat kemasan bundle itu sendiri.
+func cartWeight(items []model.CartItem) int {
+	weight := 0
+	for _, item := range items {
+		unit := shippingModel.ChargeableWeight(item.ProductWeightGrams, item.ProductLengthMM, item.ProductWidthMM, item.ProductHeightMM)
+		weight += unit * item.Quantity
+	}
+	return weight
+}
+
+func toFreeShippingProgress(progress *shippingModel.FreeShippingProgress) *dto.FreeShippingProgress {
+	if progress == nil {
+		return nil
+	}
+	return &dto.FreeShippingProgress{
+		Threshold: progress.Threshold,
+		Remaining: progress.Remaining,
+		Qualified: progress.Qualified,
+	}
+}
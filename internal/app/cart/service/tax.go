@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"go-fiber-api/internal/app/cart/model"
+	taxModel "go-fiber-api/internal/app/tax/model"
+	"go-fiber-api/internal/shared/dto"
+	"math/big"
+	"strings"
+)
+
+// taxRegion melengkapi wilayah pajak dari request; tanpa negara, DefaultTaxCountry yang dipakai
+func (s *cartService) taxRegion(region taxModel.Region) taxModel.Region {
+	region.Country = strings.ToUpper(strings.TrimSpace(region.Country))
+	region.Region = strings.ToUpper(strings.TrimSpace(region.Region))
+	if region.Country == "" {
+		region = taxModel.Region{Country: s.config.DefaultTaxCountry}
+	}
+	return region
+}
+
+// cartTax menghitung pajak setiap baris dari harga baris setelah bagian diskonnya dikurangi
+func (s *cartService) cartTax(ctx context.Context, region taxModel.Region, items []model.CartItem, discounts []dto.CartDiscount) (*taxModel.Result, error) {
+	allocated := allocateDiscounts(items, discounts)
+
+	lines := make([]taxModel.Line, 0, len(items))
+	for _, item := range items {
+		amount := item.LinePrice()
+		amount.Amount -= allocated[item.ID]
+
+		taxClass := taxModel.DefaultClass
+		if item.ProductTaxClass != nil {
+			taxClass = *item.ProductTaxClass
+		}
+		lines = append(lines, taxModel.Line{ID: item.ID, TaxClass: taxClass, Amount: amount})
+	}
+	return s.tax.Calculate(ctx, region, lines)
+}
+
+// allocateDiscounts membagi setiap diskon ke baris yang eligible sebanding harga barisnya.
+// Sisa pembagian diberikan satu minor unit per baris sesuai urutan, sehingga jumlah bagian
+// selalu sama dengan diskonnya.
+func allocateDiscounts(items []model.CartItem, discounts []dto.CartDiscount) map[uint]int64 {
+	prices := make(map[uint]int64, len(items))
+	for _, item := range items {
+		prices[item.ID] = item.LinePrice().Amount
+	}
+
+	allocated := make(map[uint]int64, len(items))
+	for _, discount := range discounts {
+		var base int64
+		for _, id := range discount.CartItemIDs {
+			base += prices[id] - allocated[id]
+		}
+		if base <= 0 {
+			continue
+		}
+
+		amount := min(discount.Amount.Amount, base)
+		shares := make([]int64, len(discount.CartItemIDs))
+		remaining := amount
+		for i, id := range discount.CartItemIDs {
+			shares[i] = mulDiv(amount, prices[id]-allocated[id], base)
+			remaining -= shares[i]
+		}
+		for i, id := range discount.CartItemIDs {
+			if remaining > 0 && shares[i] < prices[id]-allocated[id] {
+				shares[i]++
+				remaining--
+			}
+			allocated[id] += shares[i]
+		}
+	}
+	return allocated
+}
+
+// mulDiv menghitung a * b / c (dibulatkan ke bawah) untuk nilai non-negatif. Perkaliannya memakai
+// big.Int karena a * b bisa melewati int64 untuk mata uang dengan minor unit kecil, mis. IDR.
+func mulDiv(a, b, c int64) int64 {
+	product := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	return product.Quo(product, big.NewInt(c)).Int64()
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+
+	"go-fiber-api/internal/app/cart/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+)
+
+func TestAllocateDiscounts(t *testing.T) {
+	line := func(id uint, price int64) model.CartItem {
+		return model.CartItem{ID: id, Price: price, Currency: "IDR"}
+	}
+	discount := func(amount int64, ids ...uint) dto.CartDiscount {
+		return dto.CartDiscount{Amount: types.NewMoney(amount, "IDR"), CartItemIDs: ids}
+	}
+
+	tests := []struct {
+		name      string
+		items     []model.CartItem
+		discounts []dto.CartDiscount
+		want      map[uint]int64
+	}{
+		{
+			name:      "single line takes the whole discount",
+			items:     []model.CartItem{line(1, 10000)},
+			discounts: []dto.CartDiscount{discount(2500, 1)},
+			want:      map[uint]int64{1: 2500},
+		},
+		{
+			name:      "proportional to line price",
+			items:     []model.CartItem{line(1, 3000), line(2, 1000)},
+			discounts: []dto.CartDiscount{discount(400, 1, 2)},
+			want:      map[uint]int64{1: 300, 2: 100},
+		},
+		{
+			name:      "remainder goes one unit per line in order",
+			items:     []model.CartItem{line(1, 100), line(2, 100), line(3, 100)},
+			discounts: []dto.CartDiscount{discount(100, 1, 2, 3)},
+			want:      map[uint]int64{1: 34, 2: 33, 3: 33},
+		},
+		{
+			name:      "only eligible lines share the discount",
+			items:     []model.CartItem{line(1, 5000), line(2, 5000)},
+			discounts: []dto.CartDiscount{discount(1000, 2)},
+			want:      map[uint]int64{2: 1000},
+		},
+		{
+			name:      "discount is capped at the eligible amount",
+			items:     []model.CartItem{line(1, 700)},
+			discounts: []dto.CartDiscount{discount(1000, 1)},
+			want:      map[uint]int64{1: 700},
+		},
+		{
+			name:      "second discount uses what is left of each line",
+			items:     []model.CartItem{line(1, 1000), line(2, 1000)},
+			discounts: []dto.CartDiscount{discount(1000, 1), discount(500, 1, 2)},
+			want:      map[uint]int64{1: 1000, 2: 500},
+		},
+		{
+			// Rp100.000.000 dan Rp50.000.000 dengan kupon Rp10.000.000: 1e10 x 1e9 melewati int64
+			name:      "large IDR amounts do not overflow",
+			items:     []model.CartItem{line(1, 10_000_000_000), line(2, 5_000_000_000)},
+			discounts: []dto.CartDiscount{discount(1_000_000_000, 1, 2)},
+			want:      map[uint]int64{1: 666_666_667, 2: 333_333_333},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateDiscounts(tt.items, tt.discounts)
+
+			var total, want int64
+			for id, amount := range tt.want {
+				if got[id] != amount {
+					t.Errorf("line %d: got %d, want %d", id, got[id], amount)
+				}
+				want += amount
+			}
+			for _, amount := range got {
+				total += amount
+			}
+			if total != want {
+				t.Errorf("allocated %d in total, want %d", total, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/coupon/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type coupon struct {
+	couponService service.Coupon
+}
+
+// NewCouponController mendaftarkan route admin untuk mengelola kupon. Pemasangan kupon ke cart
+// dan penebusannya saat checkout ada di controller cart (POST/DELETE /v1/cart/coupon dan
+// POST /v1/cart/coupon/redeem).
+func NewCouponController(mux *http.ServeMux, couponService service.Coupon) {
+	c := &coupon{couponService: couponService}
+
+	mux.HandleFunc("GET /v1/admin/coupons", middleware.ValidateRole(types.RoleAdmin)(c.GetAll))
+	mux.HandleFunc("POST /v1/admin/coupons", middleware.ValidateRole(types.RoleAdmin)(c.Create))
+	mux.HandleFunc("GET /v1/admin/coupons/{id}", middleware.ValidateRole(types.RoleAdmin)(c.GetByID))
+	mux.HandleFunc("PUT /v1/admin/coupons/{id}", middleware.ValidateRole(types.RoleAdmin)(c.Update))
+	mux.HandleFunc("DELETE /v1/admin/coupons/{id}", middleware.ValidateRole(types.RoleAdmin)(c.Delete))
+}
+
+func (c *coupon) GetAll(w http.ResponseWriter, r *http.Request) {
+	coupons, err := c.couponService.GetAll(r.Context())
+	if err != nil {
+		slog.Error("GetAllCoupons failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, coupons)
+}
+
+func (c *coupon) GetByID(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "coupon")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	coupon, err := c.couponService.GetByID(r.Context(), id)
+	if err != nil {
+		slog.Error("GetCoupon failed", "coupon_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, coupon)
+}
+
+func (c *coupon) Create(w http.ResponseWriter, r *http.Request) {
+	var req dto.CouponRequest
+	if !decodeCouponRequest(w, r, &req) {
+		return
+	}
+
+	coupon, err := c.couponService.Create(r.Context(), &req)
+	if err != nil {
+		slog.Error("CreateCoupon failed", "code", req.Code, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("CreateCoupon success", "coupon_id", coupon.ID)
+	web.OK(w, http.StatusCreated, coupon)
+}
+
+func (c *coupon) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "coupon")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.CouponRequest
+	if !decodeCouponRequest(w, r, &req) {
+		return
+	}
+
+	coupon, err := c.couponService.Update(r.Context(), id, &req)
+	if err != nil {
+		slog.Error("UpdateCoupon failed", "coupon_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, coupon)
+}
+
+func (c *coupon) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "coupon")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := c.couponService.Delete(r.Context(), id); err != nil {
+		slog.Error("DeleteCoupon failed", "coupon_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OKNoContent(w, http.StatusOK)
+}
+
+func decodeCouponRequest(w http.ResponseWriter, r *http.Request, req *dto.CouponRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		slog.Error("Invalid coupon request body", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return false
+	}
+	if err := web.Validator().Struct(req); err != nil {
+		slog.Error("Coupon request validation failed", "error", err)
+		web.Err(w, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,101 @@
+package model
+
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+
+	"github.com/lib/pq"
+)
+
+// Jenis diskon kupon
+const (
+	TypePercentage = "percentage" // Value berisi persen dari subtotal baris yang eligible
+	TypeFixed      = "fixed"      // Value berisi potongan dalam minor unit Currency
+)
+
+// Alasan kupon tidak bisa dipakai untuk cart saat ini
+const (
+	ReasonInactive        = "inactive"
+	ReasonNotStarted      = "not_started"
+	ReasonExpired         = "expired"
+	ReasonUsageLimit      = "usage_limit_reached"
+	ReasonUserLimit       = "user_limit_reached"
+	ReasonMinSpend        = "min_spend_not_met"
+	ReasonNoEligibleItems = "no_eligible_items"
+	ReasonCurrency        = "currency_mismatch"
+)
+
+type Coupon struct {
+	ID           uint           `db:"id" json:"id"`
+	Code         string         `db:"code" json:"code"` // Selalu huruf besar
+	Description  string         `db:"description" json:"description"`
+	DiscountType string         `db:"discount_type" json:"discount_type"`
+	Value        int64          `db:"value" json:"value"`
+	Currency     *string        `db:"currency" json:"currency,omitempty"`   // Wajib untuk fixed dan min_spend
+	MinSpend     *int64         `db:"min_spend" json:"min_spend,omitempty"` // Subtotal cart minimum dalam minor unit
+	ProductIDs   pq.Int64Array  `db:"product_ids" json:"product_ids"`
+	Categories   pq.StringArray `db:"categories" json:"categories"`
+	UsageLimit   *int           `db:"usage_limit" json:"usage_limit,omitempty"`
+	PerUserLimit *int           `db:"per_user_limit" json:"per_user_limit,omitempty"`
+	StartsAt     time.Time      `db:"starts_at" json:"starts_at"`
+	EndsAt       *time.Time     `db:"ends_at" json:"ends_at,omitempty"`
+	IsActive     bool           `db:"is_active" json:"is_active"`
+	CreatedAt    time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time      `db:"updated_at" json:"updated_at"`
+
+	// Jumlah redemption, hasil subquery saat kupon dibaca
+	RedemptionCount int `db:"redemption_count" json:"redemption_count"`
+}
+
+// Eligible bernilai true jika produk termasuk cakupan kupon. Tanpa batasan produk
+// maupun kategori, semua produk eligible.
+func (c *Coupon) Eligible(productID uint, category string) bool {
+	if len(c.ProductIDs) == 0 && len(c.Categories) == 0 {
+		return true
+	}
+	for _, id := range c.ProductIDs {
+		if uint(id) == productID {
+			return true
+		}
+	}
+	for _, eligible := range c.Categories {
+		if category != "" && eligible == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Redemption adalah satu pemakaian kupon yang sudah dibayar
+type Redemption struct {
+	ID        uint      `db:"id" json:"id"`
+	CouponID  uint      `db:"coupon_id" json:"coupon_id"`
+	UserID    uint      `db:"user_id" json:"user_id"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// Line adalah satu baris cart yang dinilai saat menghitung diskon
+type Line struct {
+	CartItemID uint
+	ProductID  uint
+	Category   string
+	Amount     types.Money // Harga baris setelah reprice
+}
+
+// Discount adalah hasil penerapan kupon ke cart
+type Discount struct {
+	Coupon      *Coupon
+	Amount      types.Money
+	CartItemIDs []uint // Baris yang eligible
+}
+
+// Rejection menjelaskan kenapa kupon tidak bisa dipakai untuk cart saat ini
+type Rejection struct {
+	Reason  string
+	Message string
+}
+
+func (r *Rejection) Error() string {
+	return r.Message
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/coupon/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Coupon interface {
+	GetAll(ctx context.Context) ([]model.Coupon, error)
+	GetByID(ctx context.Context, id uint) (*model.Coupon, error)
+	GetByCode(ctx context.Context, code string) (*model.Coupon, error)
+	Create(ctx context.Context, coupon *model.Coupon) error
+	Update(ctx context.Context, coupon *model.Coupon) error
+	Delete(ctx context.Context, id uint) error
+	Lock(ctx context.Context, id uint) error
+	CountRedemptions(ctx context.Context, couponID, userID uint) (total int, byUser int, err error)
+	CreateRedemption(ctx context.Context, redemption *model.Redemption) error
+}
+
+type couponRepo struct {
+	db *sqlx.DB
+}
+
+func NewCouponRepository(db *sqlx.DB) Coupon {
+	return &couponRepo{db: db}
+}
+
+// selectCoupons menyertakan jumlah redemption setiap kupon
+const selectCoupons = `
+	SELECT c.*, (SELECT COUNT(*) FROM coupon_redemptions cr WHERE cr.coupon_id = c.id) AS redemption_count
+	FROM coupons c`
+
+func (r *couponRepo) GetAll(ctx context.Context) ([]model.Coupon, error) {
+	var coupons []model.Coupon
+	query := selectCoupons + ` ORDER BY c.created_at DESC, c.id DESC`
+
+	slog.Info("Executing query GetAllCoupons", "query", query)
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &coupons, query); err != nil {
+		slog.Error("Failed to get coupons", "error", err)
+		return nil, err
+	}
+	return coupons, nil
+}
+
+func (r *couponRepo) GetByID(ctx context.Context, id uint) (*model.Coupon, error) {
+	var coupon model.Coupon
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &coupon, selectCoupons+` WHERE c.id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+// GetByCode mencari kupon berdasarkan kode; kode disimpan dalam huruf besar
+func (r *couponRepo) GetByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	var coupon model.Coupon
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &coupon, selectCoupons+` WHERE c.code = $1`, code); err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (r *couponRepo) Create(ctx context.Context, coupon *model.Coupon) error {
+	query := `
+		INSERT INTO coupons (code, description, discount_type, value, currency, min_spend, product_ids, categories,
+			usage_limit, per_user_limit, starts_at, ends_at, is_active)
+		VALUES (:code, :description, :discount_type, :value, :currency, :min_spend, :product_ids, :categories,
+			:usage_limit, :per_user_limit, :starts_at, :ends_at, :is_active)
+		RETURNING id, created_at, updated_at
+	`
+
+	slog.Info("Executing query CreateCoupon", "query", query, "code", coupon.Code)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, coupon)
+	if err != nil {
+		slog.Error("Failed to create coupon", "code", coupon.Code, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&coupon.ID, &coupon.CreatedAt, &coupon.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+func (r *couponRepo) Update(ctx context.Context, coupon *model.Coupon) error {
+	query := `
+		UPDATE coupons
+		SET code = :code, description = :description, discount_type = :discount_type, value = :value,
+			currency = :currency, min_spend = :min_spend, product_ids = :product_ids, categories = :categories,
+			usage_limit = :usage_limit, per_user_limit = :per_user_limit, starts_at = :starts_at,
+			ends_at = :ends_at, is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+		RETURNING updated_at
+	`
+
+	slog.Info("Executing query UpdateCoupon", "query", query, "coupon_id", coupon.ID)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, coupon)
+	if err != nil {
+		slog.Error("Failed to update coupon", "coupon_id", coupon.ID, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&coupon.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+// Delete menghapus kupon; redemption dan pemasangannya di cart ikut terhapus lewat ON DELETE CASCADE
+func (r *couponRepo) Delete(ctx context.Context, id uint) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM coupons WHERE id = $1`, id)
+	return err
+}
+
+// Lock mengunci baris kupon sampai transaksi selesai, agar pengecekan batas pemakaian dan
+// pencatatan redemption tidak balapan dengan checkout lain
+func (r *couponRepo) Lock(ctx context.Context, id uint) error {
+	var locked uint
+	return database.Conn(ctx, r.db).GetContext(ctx, &locked, `SELECT id FROM coupons WHERE id = $1 FOR UPDATE`, id)
+}
+
+// CountRedemptions menghitung pemakaian kupon secara total dan oleh satu user.
+// userID 0 (guest) selalu menghasilkan byUser 0.
+func (r *couponRepo) CountRedemptions(ctx context.Context, couponID, userID uint) (int, int, error) {
+	var counts struct {
+		Total  int `db:"total"`
+		ByUser int `db:"by_user"`
+	}
+	query := `
+		SELECT COUNT(*) AS total, COUNT(*) FILTER (WHERE user_id = $2) AS by_user
+		FROM coupon_redemptions
+		WHERE coupon_id = $1
+	`
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &counts, query, couponID, userID); err != nil {
+		return 0, 0, err
+	}
+	return counts.Total, counts.ByUser, nil
+}
+
+func (r *couponRepo) CreateRedemption(ctx context.Context, redemption *model.Redemption) error {
+	query := `INSERT INTO coupon_redemptions (coupon_id, user_id) VALUES ($1, $2) RETURNING id, created_at`
+	return database.Conn(ctx, r.db).QueryRowxContext(ctx, query, redemption.CouponID, redemption.UserID).
+		Scan(&redemption.ID, &redemption.CreatedAt)
+}
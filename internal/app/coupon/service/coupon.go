@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/coupon/model"
+	"go-fiber-api/internal/app/coupon/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+
+	"github.com/lib/pq"
+)
+
+type Coupon interface {
+	GetAll(ctx context.Context) ([]*dto.CouponResponse, error)
+	GetByID(ctx context.Context, id uint) (*dto.CouponResponse, error)
+	Create(ctx context.Context, req *dto.CouponRequest) (*dto.CouponResponse, error)
+	Update(ctx context.Context, id uint, req *dto.CouponRequest) (*dto.CouponResponse, error)
+	Delete(ctx context.Context, id uint) error
+	FindByCode(ctx context.Context, code string) (*model.Coupon, error)
+	FindByID(ctx context.Context, id uint) (*model.Coupon, error)
+	Evaluate(ctx context.Context, coupon *model.Coupon, userID uint, lines []model.Line) (*model.Discount, error)
+	Redeem(ctx context.Context, couponID, userID uint) error
+}
+
+type couponService struct {
+	repo repository.Coupon
+	tx   database.Transactor
+}
+
+func NewCouponService(repo repository.Coupon, tx database.Transactor) Coupon {
+	return &couponService{repo: repo, tx: tx}
+}
+
+func (s *couponService) GetAll(ctx context.Context) ([]*dto.CouponResponse, error) {
+	coupons, err := s.repo.GetAll(ctx)
+	if err != nil {
+		slog.Error("Failed to fetch coupons", "error", err)
+		return nil, err
+	}
+
+	responses := make([]*dto.CouponResponse, 0, len(coupons))
+	for i := range coupons {
+		responses = append(responses, toCouponResponse(&coupons[i]))
+	}
+	return responses, nil
+}
+
+func (s *couponService) GetByID(ctx context.Context, id uint) (*dto.CouponResponse, error) {
+	coupon, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return toCouponResponse(coupon), nil
+}
+
+func (s *couponService) Create(ctx context.Context, req *dto.CouponRequest) (*dto.CouponResponse, error) {
+	coupon := &model.Coupon{StartsAt: time.Now(), IsActive: true}
+	if err := applyCouponRequest(coupon, req); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetByCode(ctx, coupon.Code); err == nil {
+		return nil, web.NewHTTPError(http.StatusConflict, fmt.Sprintf("Coupon %s already exists", coupon.Code), web.ErrConflict)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, coupon); err != nil {
+		slog.Error("Failed to create coupon", "code", coupon.Code, "error", err)
+		return nil, codeConflict(coupon.Code, err)
+	}
+
+	slog.Info("Coupon created", "coupon_id", coupon.ID, "code", coupon.Code)
+	return toCouponResponse(coupon), nil
+}
+
+// Update mengganti seluruh pengaturan kupon. Perubahan langsung berlaku untuk cart yang
+// sedang memakainya, karena diskon dihitung ulang setiap kali cart dibaca.
+func (s *couponService) Update(ctx context.Context, id uint, req *dto.CouponRequest) (*dto.CouponResponse, error) {
+	coupon, err := s.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyCouponRequest(coupon, req); err != nil {
+		return nil, err
+	}
+
+	if existing, err := s.repo.GetByCode(ctx, coupon.Code); err == nil && existing.ID != coupon.ID {
+		return nil, web.NewHTTPError(http.StatusConflict, fmt.Sprintf("Coupon %s already exists", coupon.Code), web.ErrConflict)
+	} else if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, coupon); err != nil {
+		slog.Error("Failed to update coupon", "coupon_id", id, "error", err)
+		return nil, codeConflict(coupon.Code, err)
+	}
+
+	slog.Info("Coupon updated", "coupon_id", id, "code", coupon.Code)
+	return toCouponResponse(coupon), nil
+}
+
+func (s *couponService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.FindByID(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		slog.Error("Failed to delete coupon", "coupon_id", id, "error", err)
+		return err
+	}
+
+	slog.Info("Coupon deleted", "coupon_id", id)
+	return nil
+}
+
+// FindByCode mencari kupon berdasarkan kode tanpa membedakan huruf besar dan kecil
+func (s *couponService) FindByCode(ctx context.Context, code string) (*model.Coupon, error) {
+	coupon, err := s.repo.GetByCode(ctx, normalizeCode(code))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Coupon not found", web.ErrCouponNotFound)
+	}
+	return coupon, err
+}
+
+func (s *couponService) FindByID(ctx context.Context, id uint) (*model.Coupon, error) {
+	coupon, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Coupon not found", web.ErrCouponNotFound)
+	}
+	return coupon, err
+}
+
+// Evaluate menghitung diskon kupon untuk baris cart saat ini. Jika kupon tidak berlaku,
+// error-nya berupa *model.Rejection beserta alasannya. Batas per user hanya diperiksa untuk
+// user yang login (userID bukan 0); guest cart diperiksa lagi setelah digabung saat login.
+func (s *couponService) Evaluate(ctx context.Context, coupon *model.Coupon, userID uint, lines []model.Line) (*model.Discount, error) {
+	now := time.Now()
+	switch {
+	case !coupon.IsActive:
+		return nil, reject(model.ReasonInactive, "Coupon is no longer active")
+	case now.Before(coupon.StartsAt):
+		return nil, reject(model.ReasonNotStarted, "Coupon is not valid yet")
+	case coupon.EndsAt != nil && !now.Before(*coupon.EndsAt):
+		return nil, reject(model.ReasonExpired, "Coupon has expired")
+	}
+
+	if err := s.checkUsage(ctx, coupon, userID); err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 {
+		return nil, reject(model.ReasonNoEligibleItems, "Cart is empty")
+	}
+	currency := lines[0].Amount.Currency
+	if coupon.Currency != nil && *coupon.Currency != currency {
+		return nil, reject(model.ReasonCurrency, fmt.Sprintf("Coupon can only be used for carts in %s", *coupon.Currency))
+	}
+
+	subtotal := types.NewMoney(0, currency)
+	eligible := types.NewMoney(0, currency)
+	var eligibleIDs []uint
+	for _, line := range lines {
+		var err error
+		if subtotal, err = subtotal.Add(line.Amount); err != nil {
+			return nil, err
+		}
+		if coupon.Eligible(line.ProductID, line.Category) {
+			if eligible, err = eligible.Add(line.Amount); err != nil {
+				return nil, err
+			}
+			eligibleIDs = append(eligibleIDs, line.CartItemID)
+		}
+	}
+
+	if coupon.MinSpend != nil && subtotal.Amount < *coupon.MinSpend {
+		minSpend := types.NewMoney(*coupon.MinSpend, currency)
+		return nil, reject(model.ReasonMinSpend, fmt.Sprintf("Spend at least %s to use this coupon", minSpend))
+	}
+	if len(eligibleIDs) == 0 {
+		return nil, reject(model.ReasonNoEligibleItems, "No items in the cart are eligible for this coupon")
+	}
+
+	// Diskon tidak pernah melebihi subtotal baris yang eligible
+	amount := min(coupon.Value, eligible.Amount)
+	if coupon.DiscountType == model.TypePercentage {
+		amount = eligible.MulRat(big.NewRat(coupon.Value, 100)).Amount
+	}
+
+	return &model.Discount{
+		Coupon:      coupon,
+		Amount:      types.NewMoney(amount, currency),
+		CartItemIDs: eligibleIDs,
+	}, nil
+}
+
+// checkUsage memeriksa batas pemakaian global dan per user
+func (s *couponService) checkUsage(ctx context.Context, coupon *model.Coupon, userID uint) error {
+	checkUser := coupon.PerUserLimit != nil && userID != 0
+	if coupon.UsageLimit == nil && !checkUser {
+		return nil
+	}
+
+	total, byUser, err := s.repo.CountRedemptions(ctx, coupon.ID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to count coupon redemptions: %w", err)
+	}
+	if coupon.UsageLimit != nil && total >= *coupon.UsageLimit {
+		return reject(model.ReasonUsageLimit, "Coupon usage limit has been reached")
+	}
+	if checkUser && byUser >= *coupon.PerUserLimit {
+		return reject(model.ReasonUserLimit, "You have already used this coupon the maximum number of times")
+	}
+	return nil
+}
+
+// Redeem mencatat pemakaian kupon oleh user. Dipanggil cart saat checkout, di dalam transaksi
+// yang sama dengan pelepasan kupon dari cart; baris kupon dikunci agar batas pemakaian tidak
+// terlampaui oleh checkout yang berjalan bersamaan.
+func (s *couponService) Redeem(ctx context.Context, couponID, userID uint) error {
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Lock(ctx, couponID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return web.NewHTTPError(http.StatusNotFound, "Coupon not found", web.ErrCouponNotFound)
+			}
+			return err
+		}
+		coupon, err := s.repo.GetByID(ctx, couponID)
+		if err != nil {
+			return err
+		}
+
+		if err := s.checkUsage(ctx, coupon, userID); err != nil {
+			var rejection *model.Rejection
+			if errors.As(err, &rejection) {
+				return web.NewHTTPError(http.StatusUnprocessableEntity, rejection.Message, web.ErrCouponNotApplicable).
+					WithData(map[string]string{"reason": rejection.Reason})
+			}
+			return err
+		}
+
+		redemption := &model.Redemption{CouponID: couponID, UserID: userID}
+		if err := s.repo.CreateRedemption(ctx, redemption); err != nil {
+			return fmt.Errorf("failed to record coupon redemption: %w", err)
+		}
+		slog.Info("Coupon redeemed", "coupon_id", couponID, "user_id", userID, "redemption_id", redemption.ID)
+		return nil
+	})
+}
+
+// codeConflict memetakan bentrok unique index kode kupon menjadi 409. Bentrok ini terjadi jika
+// kupon dengan kode yang sama disimpan bersamaan di antara GetByCode dan insert/update.
+func codeConflict(code string, err error) error {
+	if database.IsUniqueViolation(err, "coupons_code_key") {
+		return web.NewHTTPError(http.StatusConflict, fmt.Sprintf("Coupon %s already exists", code), web.ErrConflict)
+	}
+	return err
+}
+
+func reject(reason, message string) error {
+	return &model.Rejection{Reason: reason, Message: message}
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// applyCouponRequest menyalin request ke kupon dan memvalidasi aturan yang tidak bisa
+// dinyatakan lewat tag validate
+func applyCouponRequest(coupon *model.Coupon, req *dto.CouponRequest) error {
+	code := normalizeCode(req.Code)
+	if strings.ContainsAny(code, " \t\r\n") {
+		return web.NewHTTPError(http.StatusBadRequest, "Coupon code cannot contain whitespace", web.ErrValidation)
+	}
+
+	coupon.Code = code
+	coupon.Description = req.Description
+	coupon.DiscountType = req.DiscountType
+	coupon.Currency = nil
+	coupon.MinSpend = nil
+
+	switch req.DiscountType {
+	case model.TypePercentage:
+		coupon.Value = req.Percent
+	case model.TypeFixed:
+		coupon.Value = req.Amount.Amount
+		coupon.Currency = &req.Amount.Currency
+	}
+
+	if req.MinSpend != nil {
+		if coupon.Currency != nil && *coupon.Currency != req.MinSpend.Currency {
+			return web.NewHTTPError(http.StatusBadRequest, "Minimum spend must use the same currency as the discount amount", web.ErrValidation)
+		}
+		coupon.Currency = &req.MinSpend.Currency
+		coupon.MinSpend = &req.MinSpend.Amount
+	}
+	if coupon.Currency != nil {
+		if _, err := types.CurrencyExponent(*coupon.Currency); err != nil {
+			return web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported currency code %q", *coupon.Currency), web.ErrValidation)
+		}
+	}
+
+	coupon.ProductIDs = make(pq.Int64Array, 0, len(req.ProductIDs))
+	for _, id := range req.ProductIDs {
+		coupon.ProductIDs = append(coupon.ProductIDs, int64(id))
+	}
+	coupon.Categories = pq.StringArray(req.Categories)
+	if coupon.Categories == nil {
+		coupon.Categories = pq.StringArray{}
+	}
+
+	coupon.UsageLimit = req.UsageLimit
+	coupon.PerUserLimit = req.PerUserLimit
+	if req.StartsAt != nil {
+		coupon.StartsAt = *req.StartsAt
+	}
+	coupon.EndsAt = req.EndsAt
+	if coupon.EndsAt != nil && !coupon.EndsAt.After(coupon.StartsAt) {
+		return web.NewHTTPError(http.StatusBadRequest, "ends_at must be after starts_at", web.ErrValidation)
+	}
+	if req.IsActive != nil {
+		coupon.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+func toCouponResponse(coupon *model.Coupon) *dto.CouponResponse {
+	resp := &dto.CouponResponse{
+		ID:           coupon.ID,
+		Code:         coupon.Code,
+		Description:  coupon.Description,
+		DiscountType: coupon.DiscountType,
+		ProductIDs:   make([]uint, 0, len(coupon.ProductIDs)),
+		Categories:   []string(coupon.Categories),
+		UsageLimit:   coupon.UsageLimit,
+		PerUserLimit: coupon.PerUserLimit,
+		Redemptions:  coupon.RedemptionCount,
+		StartsAt:     coupon.StartsAt,
+		EndsAt:       coupon.EndsAt,
+		IsActive:     coupon.IsActive,
+		CreatedAt:    coupon.CreatedAt,
+		UpdatedAt:    coupon.UpdatedAt,
+	}
+	for _, id := range coupon.ProductIDs {
+		resp.ProductIDs = append(resp.ProductIDs, uint(id))
+	}
+	if resp.Categories == nil {
+		resp.Categories = []string{}
+	}
+
+	switch coupon.DiscountType {
+	case model.TypePercentage:
+		resp.Percent = coupon.Value
+	case model.TypeFixed:
+		amount := types.NewMoney(coupon.Value, *coupon.Currency)
+		resp.Amount = &amount
+	}
+	if coupon.MinSpend != nil {
+		minSpend := types.NewMoney(*coupon.MinSpend, *coupon.Currency)
+		resp.MinSpend = &minSpend
+	}
+	return resp
+}
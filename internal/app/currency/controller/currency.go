@@ -0,0 +1,99 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/currency/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type currency struct {
+	currencyService service.Currency
+}
+
+func NewCurrencyController(mux *http.ServeMux, currencyService service.Currency) {
+	c := &currency{currencyService: currencyService}
+
+	mux.HandleFunc("GET /v1/currencies", c.GetAllCurrencies)
+	mux.HandleFunc("PUT /v1/admin/currencies/{code}", middleware.ValidateRole(types.RoleAdmin)(c.SaveCurrency))
+	mux.HandleFunc("GET /v1/admin/exchange-rates", middleware.ValidateRole(types.RoleAdmin)(c.GetRates))
+	mux.HandleFunc("POST /v1/admin/exchange-rates", middleware.ValidateRole(types.RoleAdmin)(c.CreateRate))
+}
+
+func (c *currency) GetAllCurrencies(w http.ResponseWriter, r *http.Request) {
+	currencies, err := c.currencyService.GetAllCurrencies(r.Context())
+	if err != nil {
+		slog.Error("GetAllCurrencies failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, currencies)
+}
+
+func (c *currency) SaveCurrency(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	slog.Info("SaveCurrency called", "code", code)
+
+	var req dto.CurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("SaveCurrency failed - invalid JSON", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		slog.Error("SaveCurrency failed - validation error", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	saved, err := c.currencyService.SaveCurrency(r.Context(), code, &req)
+	if err != nil {
+		slog.Error("SaveCurrency failed", "code", code, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, saved)
+}
+
+func (c *currency) GetRates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rates, err := c.currencyService.GetRates(r.Context(), q.Get("base"), q.Get("quote"))
+	if err != nil {
+		slog.Error("GetRates failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, rates)
+}
+
+func (c *currency) CreateRate(w http.ResponseWriter, r *http.Request) {
+	var req dto.ExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("CreateRate failed - invalid JSON", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		slog.Error("CreateRate failed - validation error", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	rate, err := c.currencyService.CreateRate(r.Context(), &req)
+	if err != nil {
+		slog.Error("CreateRate failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("CreateRate success", "rate_id", rate.ID)
+	web.OK(w, http.StatusCreated, rate)
+}
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+// Currency adalah mata uang yang bisa dipakai untuk menampilkan harga.
+// RoundingIncrement dalam minor unit, mis. 100 untuk membulatkan IDR ke rupiah utuh.
+type Currency struct {
+	Code              string    `db:"code" json:"code"`
+	Name              string    `db:"name" json:"name"`
+	Exponent          int       `db:"exponent" json:"exponent"`
+	RoundingMode      string    `db:"rounding_mode" json:"rounding_mode"`
+	RoundingIncrement int64     `db:"rounding_increment" json:"rounding_increment"`
+	IsActive          bool      `db:"is_active" json:"is_active"`
+	CreatedAt         time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt         time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// ExchangeRate: 1 unit BaseCurrency = Rate unit QuoteCurrency.
+// Rate disimpan sebagai NUMERIC dan dibaca sebagai string agar presisinya tidak hilang.
+type ExchangeRate struct {
+	ID            uint      `db:"id" json:"id"`
+	BaseCurrency  string    `db:"base_currency" json:"base_currency"`
+	QuoteCurrency string    `db:"quote_currency" json:"quote_currency"`
+	Rate          string    `db:"rate" json:"rate"`
+	EffectiveAt   time.Time `db:"effective_at" json:"effective_at"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/internal/app/currency/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Currency interface {
+	GetAllCurrencies(ctx context.Context) ([]model.Currency, error)
+	GetCurrencyByCode(ctx context.Context, code string) (*model.Currency, error)
+	UpsertCurrency(ctx context.Context, c *model.Currency) error
+	CreateRate(ctx context.Context, rate *model.ExchangeRate) error
+	GetLatestRate(ctx context.Context, base, quote string) (*model.ExchangeRate, error)
+	GetRates(ctx context.Context, base, quote string, limit int) ([]model.ExchangeRate, error)
+}
+
+type currencyRepo struct {
+	db *sqlx.DB
+}
+
+func NewCurrencyRepository(db *sqlx.DB) Currency {
+	return &currencyRepo{db: db}
+}
+
+func (r *currencyRepo) GetAllCurrencies(ctx context.Context) ([]model.Currency, error) {
+	var currencies []model.Currency
+	query := `SELECT * FROM currencies ORDER BY code`
+
+	slog.Info("Executing query GetAllCurrencies", "query", query)
+	if err := r.db.SelectContext(ctx, &currencies, query); err != nil {
+		slog.Error("Failed to get currencies", "error", err)
+		return nil, err
+	}
+	return currencies, nil
+}
+
+func (r *currencyRepo) GetCurrencyByCode(ctx context.Context, code string) (*model.Currency, error) {
+	var currency model.Currency
+	query := `SELECT * FROM currencies WHERE code = $1`
+
+	if err := r.db.GetContext(ctx, &currency, query, code); err != nil {
+		slog.Error("Failed to get currency", "code", code, "error", err)
+		return nil, err
+	}
+	return &currency, nil
+}
+
+func (r *currencyRepo) UpsertCurrency(ctx context.Context, c *model.Currency) error {
+	query := `
+		INSERT INTO currencies (code, name, exponent, rounding_mode, rounding_increment, is_active)
+		VALUES (:code, :name, :exponent, :rounding_mode, :rounding_increment, :is_active)
+		ON CONFLICT (code) DO UPDATE
+		SET name = EXCLUDED.name, exponent = EXCLUDED.exponent, rounding_mode = EXCLUDED.rounding_mode,
+			rounding_increment = EXCLUDED.rounding_increment, is_active = EXCLUDED.is_active, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+
+	slog.Info("Executing query UpsertCurrency", "query", query, "code", c.Code)
+	rows, err := r.db.NamedQueryContext(ctx, query, c)
+	if err != nil {
+		slog.Error("Failed to upsert currency", "code", c.Code, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&c.CreatedAt, &c.UpdatedAt)
+	}
+	return nil
+}
+
+func (r *currencyRepo) CreateRate(ctx context.Context, rate *model.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (base_currency, quote_currency, rate, effective_at)
+		VALUES (:base_currency, :quote_currency, :rate, :effective_at)
+		RETURNING id, created_at
+	`
+
+	slog.Info("Executing query CreateRate", "query", query, "base", rate.BaseCurrency, "quote", rate.QuoteCurrency)
+	rows, err := r.db.NamedQueryContext(ctx, query, rate)
+	if err != nil {
+		slog.Error("Failed to create exchange rate", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&rate.ID, &rate.CreatedAt)
+	}
+	return nil
+}
+
+// GetLatestRate mengambil kurs base -> quote terbaru yang sudah berlaku
+func (r *currencyRepo) GetLatestRate(ctx context.Context, base, quote string) (*model.ExchangeRate, error) {
+	var rate model.ExchangeRate
+	query := `
+		SELECT * FROM exchange_rates
+		WHERE base_currency = $1 AND quote_currency = $2 AND effective_at <= NOW()
+		ORDER BY effective_at DESC, id DESC
+		LIMIT 1
+	`
+
+	if err := r.db.GetContext(ctx, &rate, query, base, quote); err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+// GetRates mengambil riwayat kurs, bisa difilter per pasangan mata uang
+func (r *currencyRepo) GetRates(ctx context.Context, base, quote string, limit int) ([]model.ExchangeRate, error) {
+	var rates []model.ExchangeRate
+	query := `
+		SELECT * FROM exchange_rates
+		WHERE ($1 = '' OR base_currency = $1) AND ($2 = '' OR quote_currency = $2)
+		ORDER BY effective_at DESC, id DESC
+		LIMIT $3
+	`
+
+	slog.Info("Executing query GetRates", "query", query, "base", base, "quote", quote)
+	if err := r.db.SelectContext(ctx, &rates, query, base, quote, limit); err != nil {
+		slog.Error("Failed to get exchange rates", "error", err)
+		return nil, err
+	}
+	return rates, nil
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-fiber-api/internal/app/currency/model"
+	"go-fiber-api/internal/app/currency/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+)
+
+// maxRateHistory membatasi jumlah baris riwayat kurs yang dikembalikan
+const maxRateHistory = 200
+
+type Currency interface {
+	GetAllCurrencies(ctx context.Context) ([]model.Currency, error)
+	SaveCurrency(ctx context.Context, code string, req *dto.CurrencyRequest) (*model.Currency, error)
+	CreateRate(ctx context.Context, req *dto.ExchangeRateRequest) (*model.ExchangeRate, error)
+	GetRates(ctx context.Context, base, quote string) ([]model.ExchangeRate, error)
+	NewConverter(ctx context.Context, target string) (*Converter, error)
+}
+
+type currencyService struct {
+	repo repository.Currency
+}
+
+func NewCurrencyService(repo repository.Currency) Currency {
+	return &currencyService{repo: repo}
+}
+
+func (s *currencyService) GetAllCurrencies(ctx context.Context) ([]model.Currency, error) {
+	currencies, err := s.repo.GetAllCurrencies(ctx)
+	if err != nil {
+		slog.Error("Failed to fetch currencies", "error", err)
+		return nil, err
+	}
+	if currencies == nil {
+		currencies = []model.Currency{}
+	}
+	return currencies, nil
+}
+
+// SaveCurrency menambah atau mengubah mata uang. Kode harus dikenal oleh types.Money
+// agar presisi (exponent) di tabel selalu sama dengan yang dipakai saat parsing harga.
+func (s *currencyService) SaveCurrency(ctx context.Context, code string, req *dto.CurrencyRequest) (*model.Currency, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	exponent, err := types.CurrencyExponent(code)
+	if err != nil {
+		return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported currency code %q", code), web.ErrValidation)
+	}
+
+	currency := &model.Currency{
+		Code:              code,
+		Name:              req.Name,
+		Exponent:          exponent,
+		RoundingMode:      string(types.RoundHalfUp),
+		RoundingIncrement: 1,
+		IsActive:          true,
+	}
+	if req.RoundingMode != "" {
+		currency.RoundingMode = req.RoundingMode
+	}
+	if req.RoundingIncrement > 0 {
+		currency.RoundingIncrement = req.RoundingIncrement
+	}
+	if req.IsActive != nil {
+		currency.IsActive = *req.IsActive
+	}
+
+	if err := s.repo.UpsertCurrency(ctx, currency); err != nil {
+		slog.Error("Failed to save currency", "code", code, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Currency saved", "code", code)
+	return currency, nil
+}
+
+func (s *currencyService) CreateRate(ctx context.Context, req *dto.ExchangeRateRequest) (*model.ExchangeRate, error) {
+	base := strings.ToUpper(req.BaseCurrency)
+	quote := strings.ToUpper(req.QuoteCurrency)
+
+	rate, ok := new(big.Rat).SetString(req.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "Rate must be a positive decimal number", web.ErrValidation)
+	}
+
+	for _, code := range []string{base, quote} {
+		if _, err := s.repo.GetCurrencyByCode(ctx, code); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Currency %s is not configured", code), web.ErrValidation)
+			}
+			return nil, err
+		}
+	}
+
+	exchangeRate := &model.ExchangeRate{
+		BaseCurrency:  base,
+		QuoteCurrency: quote,
+		Rate:          req.Rate,
+		EffectiveAt:   time.Now(),
+	}
+	if req.EffectiveAt != nil {
+		exchangeRate.EffectiveAt = *req.EffectiveAt
+	}
+
+	if err := s.repo.CreateRate(ctx, exchangeRate); err != nil {
+		slog.Error("Failed to create exchange rate", "base", base, "quote", quote, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Exchange rate created", "rate_id", exchangeRate.ID, "base", base, "quote", quote, "rate", req.Rate)
+	return exchangeRate, nil
+}
+
+func (s *currencyService) GetRates(ctx context.Context, base, quote string) ([]model.ExchangeRate, error) {
+	rates, err := s.repo.GetRates(ctx, strings.ToUpper(base), strings.ToUpper(quote), maxRateHistory)
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		rates = []model.ExchangeRate{}
+	}
+	return rates, nil
+}
+
+// NewConverter membuat Converter untuk satu request ke mata uang target
+func (s *currencyService) NewConverter(ctx context.Context, target string) (*Converter, error) {
+	target = strings.ToUpper(strings.TrimSpace(target))
+
+	currency, err := s.repo.GetCurrencyByCode(ctx, target)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !currency.IsActive) {
+		return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Currency %s is not supported", target), web.ErrValidation)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Converter{
+		repo:   s.repo,
+		target: currency,
+		rates:  map[string]*conversion{},
+	}, nil
+}
+
+// conversion adalah kurs yang sudah di-resolve untuk satu mata uang sumber
+type conversion struct {
+	rate *big.Rat
+	info dto.ExchangeInfo
+}
+
+// Converter mengonversi harga ke satu mata uang target memakai kurs terbaru dan aturan
+// pembulatan mata uang target. Kurs di-cache per mata uang sumber, jadi satu Converter
+// cukup dipakai untuk satu request (satu snapshot kurs).
+type Converter struct {
+	repo   repository.Currency
+	target *model.Currency
+	rates  map[string]*conversion
+}
+
+// Currency mengembalikan kode mata uang target
+func (c *Converter) Currency() string {
+	return c.target.Code
+}
+
+// Convert mengonversi m ke mata uang target. Info kurs bernilai nil jika m sudah dalam mata uang target.
+func (c *Converter) Convert(ctx context.Context, m types.Money) (types.Money, *dto.ExchangeInfo, error) {
+	if m.Currency == c.target.Code {
+		return m, nil, nil
+	}
+
+	conv, err := c.resolve(ctx, m.Currency)
+	if err != nil {
+		return types.Money{}, nil, err
+	}
+
+	fromExp, err := types.CurrencyExponent(m.Currency)
+	if err != nil {
+		return types.Money{}, nil, err
+	}
+
+	// amount(minor sumber) * kurs * 10^(exp target - exp sumber) = amount(minor target)
+	value := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), conv.rate)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(c.target.Exponent-fromExp))), nil))
+	if c.target.Exponent >= fromExp {
+		value.Mul(value, scale)
+	} else {
+		value.Quo(value, scale)
+	}
+
+	amount := types.RoundRatTo(value, types.RoundingMode(c.target.RoundingMode), c.target.RoundingIncrement)
+	info := conv.info
+	return types.NewMoney(amount, c.target.Code), &info, nil
+}
+
+// RatesUsed mengembalikan semua kurs yang sudah dipakai oleh Converter ini
+func (c *Converter) RatesUsed() []dto.ExchangeInfo {
+	infos := make([]dto.ExchangeInfo, 0, len(c.rates))
+	for _, conv := range c.rates {
+		infos = append(infos, conv.info)
+	}
+	return infos
+}
+
+// resolve mencari kurs from -> target. Jika hanya ada kurs target -> from, kursnya dibalik.
+func (c *Converter) resolve(ctx context.Context, from string) (*conversion, error) {
+	if conv, ok := c.rates[from]; ok {
+		return conv, nil
+	}
+
+	inverted := false
+	rate, err := c.repo.GetLatestRate(ctx, from, c.target.Code)
+	if errors.Is(err, sql.ErrNoRows) {
+		inverted = true
+		rate, err = c.repo.GetLatestRate(ctx, c.target.Code, from)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity,
+			fmt.Sprintf("No exchange rate configured from %s to %s", from, c.target.Code), web.ErrProcessing)
+	}
+	if err != nil {
+		slog.Error("Failed to fetch exchange rate", "from", from, "to", c.target.Code, "error", err)
+		return nil, err
+	}
+
+	value, ok := new(big.Rat).SetString(rate.Rate)
+	if !ok || value.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %q (id %d)", rate.Rate, rate.ID)
+	}
+	if inverted {
+		value.Inv(value)
+	}
+
+	conv := &conversion{
+		rate: value,
+		info: dto.ExchangeInfo{
+			From:        from,
+			To:          c.target.Code,
+			Rate:        value.FloatString(12),
+			RateID:      rate.ID,
+			EffectiveAt: rate.EffectiveAt,
+		},
+	}
+	c.rates[from] = conv
+	return conv, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"go-fiber-api/internal/app/currency/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+)
+
+// newTestConverter membuat Converter dengan kurs yang sudah ada di cache, sehingga repository tidak dipakai
+func newTestConverter(target model.Currency, from, rate string) *Converter {
+	r, _ := new(big.Rat).SetString(rate)
+	return &Converter{
+		target: &target,
+		rates: map[string]*conversion{
+			from: {rate: r, info: dto.ExchangeInfo{From: from, To: target.Code, Rate: rate}},
+		},
+	}
+}
+
+func TestConverterConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		target model.Currency
+		rate   string
+		from   types.Money
+		want   int64
+	}{
+		{
+			name:   "same exponent",
+			target: model.Currency{Code: "IDR", Exponent: 2, RoundingMode: "half_up", RoundingIncrement: 1},
+			rate:   "15000",
+			from:   types.NewMoney(1999, "USD"),
+			want:   29_985_000,
+		},
+		{
+			name:   "to fewer decimals rounds half_up",
+			target: model.Currency{Code: "JPY", Exponent: 0, RoundingMode: "half_up", RoundingIncrement: 1},
+			rate:   "150",
+			from:   types.NewMoney(1999, "USD"),
+			want:   2999,
+		},
+		{
+			name:   "to fewer decimals rounds half_even",
+			target: model.Currency{Code: "JPY", Exponent: 0, RoundingMode: "half_even", RoundingIncrement: 1},
+			rate:   "150",
+			from:   types.NewMoney(1999, "USD"),
+			want:   2998,
+		},
+		{
+			name:   "to more decimals",
+			target: model.Currency{Code: "KWD", Exponent: 3, RoundingMode: "half_up", RoundingIncrement: 1},
+			rate:   "0.307",
+			from:   types.NewMoney(1999, "USD"),
+			want:   6137,
+		},
+		{
+			name:   "rounding increment",
+			target: model.Currency{Code: "IDR", Exponent: 2, RoundingMode: "up", RoundingIncrement: 10000},
+			rate:   "15432.1",
+			from:   types.NewMoney(1000, "USD"),
+			want:   15_440_000,
+		},
+		{
+			name:   "round down",
+			target: model.Currency{Code: "USD", Exponent: 2, RoundingMode: "down", RoundingIncrement: 1},
+			rate:   "0.0000648",
+			from:   types.NewMoney(10_000_000, "IDR"),
+			want:   648,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter := newTestConverter(tt.target, tt.from.Currency, tt.rate)
+			got, info, err := converter.Convert(context.Background(), tt.from)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Amount != tt.want || got.Currency != tt.target.Code {
+				t.Errorf("got %s (%d), want %d %s", got, got.Amount, tt.want, tt.target.Code)
+			}
+			if info == nil || info.Rate != tt.rate {
+				t.Errorf("exchange info %+v, want rate %s", info, tt.rate)
+			}
+		})
+	}
+}
+
+func TestConverterConvertSameCurrency(t *testing.T) {
+	converter := newTestConverter(model.Currency{Code: "USD", Exponent: 2}, "IDR", "0.0000648")
+	m := types.NewMoney(1999, "USD")
+
+	got, info, err := converter.Convert(context.Background(), m)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != m || info != nil {
+		t.Errorf("got %s with info %+v, want %s unchanged", got, info, m)
+	}
+}
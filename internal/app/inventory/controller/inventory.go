@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/inventory/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type inventory struct {
+	inventoryService service.Inventory
+}
+
+func NewInventoryController(mux *http.ServeMux, inventoryService service.Inventory) {
+	i := &inventory{inventoryService: inventoryService}
+
+	mux.HandleFunc("POST /v1/products/{id}/stock-adjustments", middleware.ValidateRole(types.RoleAdmin)(i.AdjustStock))
+	mux.HandleFunc("GET /v1/products/{id}/stock-movements", middleware.ValidateRole(types.RoleAdmin)(i.GetMovements))
+	mux.HandleFunc("GET /v1/admin/inventory/low-stock", middleware.ValidateRole(types.RoleAdmin)(i.GetLowStock))
+}
+
+func (i *inventory) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	productID, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.StockAdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("AdjustStock failed - invalid JSON", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	movement, err := i.inventoryService.AdjustStock(r.Context(), productID, web.GetUserID(r), &req)
+	if err != nil {
+		slog.Error("AdjustStock failed", "product_id", productID, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("AdjustStock success", "product_id", productID, "movement_id", movement.ID)
+	web.OK(w, http.StatusCreated, movement)
+}
+
+func (i *inventory) GetMovements(w http.ResponseWriter, r *http.Request) {
+	productID, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	params := web.NewPaginationParams(r)
+	movements, total, err := i.inventoryService.GetMovements(r.Context(), productID, params.PageSize, params.CalculateOffset())
+	if err != nil {
+		slog.Error("GetMovements failed", "product_id", productID, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, movements, params.GetPaginationResponse(r, total))
+}
+
+func (i *inventory) GetLowStock(w http.ResponseWriter, r *http.Request) {
+	params := web.NewPaginationParams(r)
+	items, total, err := i.inventoryService.GetLowStock(r.Context(), params.PageSize, params.CalculateOffset())
+	if err != nil {
+		slog.Error("GetLowStock failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, items, params.GetPaginationResponse(r, total))
+}
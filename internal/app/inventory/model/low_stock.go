@@ -0,0 +1,13 @@
+package model
+
+// LowStockItem adalah satu baris laporan produk yang stoknya sudah di bawah reorder point
+type LowStockItem struct {
+	ProductID    uint    `db:"product_id" json:"product_id"`
+	SKU          *string `db:"sku" json:"sku,omitempty"`
+	Name         string  `db:"name" json:"name"`
+	Quantity     int     `db:"quantity" json:"quantity"`
+	Reserved     int     `db:"reserved" json:"reserved"`
+	Available    int     `db:"available" json:"available"`
+	ReorderPoint int     `db:"reorder_point" json:"reorder_point"`
+	Shortfall    int     `db:"shortfall" json:"shortfall"` // Selisih reorder point dengan stok
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"errors"
+	"time"
+)
+
+// Alasan perubahan stok yang dicatat di ledger
+const (
+	ReasonRestock    = "restock"
+	ReasonSale       = "sale"
+	ReasonAdjustment = "adjustment"
+	ReasonReturn     = "return"
+)
+
+// ErrNegativeStock dikembalikan repository jika movement membuat stok menjadi negatif
+var ErrNegativeStock = errors.New("stock cannot be negative")
+
+// ErrBundleStock dikembalikan repository jika movement ditujukan ke produk bundle, yang stoknya
+// dihitung dari komponen
+var ErrBundleStock = errors.New("bundle stock is derived from its components")
+
+// Movement adalah satu baris ledger stok. Baris tidak pernah diubah atau dihapus.
+type Movement struct {
+	ID            uint      `db:"id" json:"id"`
+	ProductID     uint      `db:"product_id" json:"product_id"`
+	Delta         int       `db:"delta" json:"delta"`
+	QuantityAfter int       `db:"quantity_after" json:"quantity_after"`
+	Reason        string    `db:"reason" json:"reason"`
+	ActorID       *uint     `db:"actor_id" json:"actor_id,omitempty"`
+	Reference     *string   `db:"reference" json:"reference,omitempty"`
+	Note          *string   `db:"note" json:"note,omitempty"`
+	CreatedAt     time.Time `db:"created_at" json:"created_at"`
+
+	// ReorderPoint dan versi produk saat movement diterapkan, tidak disimpan di ledger
+	ReorderPoint   *int `db:"-" json:"-"`
+	ProductVersion int  `db:"-" json:"-"`
+}
+
+// CrossedReorderPoint bernilai true jika movement menurunkan stok dari atas ke
+// sama dengan atau di bawah reorder point
+func (m *Movement) CrossedReorderPoint() bool {
+	if m.ReorderPoint == nil || m.Delta >= 0 {
+		return false
+	}
+	before := m.QuantityAfter - m.Delta
+	return before > *m.ReorderPoint && m.QuantityAfter <= *m.ReorderPoint
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInsufficientStock dikembalikan repository jika stok yang tersedia tidak cukup
+var ErrInsufficientStock = errors.New("insufficient stock")
+
+// StockShortageError menjelaskan produk mana yang stoknya tidak cukup saat reservasi beberapa produk
+// sekaligus (misalnya komponen bundle). errors.Is(err, ErrInsufficientStock) tetap bernilai true.
+type StockShortageError struct {
+	ProductID uint
+	Requested int
+	Available int
+}
+
+func (e *StockShortageError) Error() string {
+	return fmt.Sprintf("insufficient stock for product %d: requested %d, available %d", e.ProductID, e.Requested, e.Available)
+}
+
+func (e *StockShortageError) Unwrap() error {
+	return ErrInsufficientStock
+}
+
+// Reservation menahan sejumlah stok produk untuk satu baris cart sampai ExpiresAt
+type Reservation struct {
+	ID         uint      `db:"id" json:"id"`
+	ProductID  uint      `db:"product_id" json:"product_id"`
+	CartItemID uint      `db:"cart_item_id" json:"cart_item_id"`
+	UserID     uint      `db:"user_id" json:"user_id"`
+	Quantity   int       `db:"quantity" json:"quantity"`
+	ExpiresAt  time.Time `db:"expires_at" json:"expires_at"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
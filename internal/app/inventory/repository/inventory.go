@@ -0,0 +1,277 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/inventory/model"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Inventory interface {
+	AvailableStock(ctx context.Context, productID, excludeCartItemID uint) (int, error)
+	Reserve(ctx context.Context, reservations []model.Reservation) error
+	LockProducts(ctx context.Context, productIDs []uint) error
+	Release(ctx context.Context, cartItemIDs []uint) error
+	DeleteExpired(ctx context.Context) (int64, error)
+	ApplyMovement(ctx context.Context, m *model.Movement) error
+	GetMovements(ctx context.Context, productID uint, limit, offset int) ([]model.Movement, int64, error)
+	GetLowStock(ctx context.Context, limit, offset int) ([]model.LowStockItem, int64, error)
+}
+
+type inventoryRepo struct {
+	db *sqlx.DB
+}
+
+func NewInventoryRepository(db *sqlx.DB) Inventory {
+	return &inventoryRepo{db: db}
+}
+
+// reservedByOthers menjumlahkan reservasi aktif untuk produk, kecuali milik baris cart yang sedang diproses
+const reservedByOthers = `
+	SELECT COALESCE(SUM(quantity), 0) FROM stock_reservations
+	WHERE product_id = $1 AND expires_at > NOW() AND cart_item_id <> $2`
+
+// AvailableStock mengembalikan stok produk dikurangi reservasi aktif milik baris cart lain
+func (r *inventoryRepo) AvailableStock(ctx context.Context, productID, excludeCartItemID uint) (int, error) {
+	var available int
+	query := `SELECT COALESCE(quantity, 0) - (` + reservedByOthers + `) FROM products WHERE id = $1`
+
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &available, query, productID, excludeCartItemID); err != nil {
+		slog.Error("Failed to get available stock", "product_id", productID, "error", err)
+		return 0, err
+	}
+	return available, nil
+}
+
+// Reserve mengunci baris produk (SELECT ... FOR UPDATE) agar request paralel tidak bisa
+// mereservasi stok yang sama, lalu membuat atau memperbarui reservasi untuk baris cart.
+// Semua reservasi harus milik baris cart yang sama (satu produk, atau semua komponen bundle);
+// reservasi lama baris itu untuk produk lain dihapus. Jika salah satu produk tidak cukup,
+// tidak ada yang disimpan dan dikembalikan *model.StockShortageError.
+func (r *inventoryRepo) Reserve(ctx context.Context, reservations []model.Reservation) error {
+	if len(reservations) == 0 {
+		return nil
+	}
+
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		// Kunci produk berurutan berdasarkan ID agar dua cart dengan bundle yang berbeda tidak deadlock.
+		// Urutan ini hanya berlaku per panggilan; transaksi yang mereservasi beberapa baris cart
+		// harus mengunci semua produknya lebih dulu lewat LockProducts.
+		sorted := make([]model.Reservation, len(reservations))
+		copy(sorted, reservations)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i].ProductID < sorted[j].ProductID })
+
+		cartItemID := sorted[0].CartItemID
+		productIDs := make([]uint, 0, len(sorted))
+		for _, res := range sorted {
+			var stock int
+			if err := tx.GetContext(ctx, &stock, `SELECT COALESCE(quantity, 0) FROM products WHERE id = $1 FOR UPDATE`, res.ProductID); err != nil {
+				slog.Error("Failed to lock product for reservation", "product_id", res.ProductID, "error", err)
+				return err
+			}
+
+			var reserved int
+			if err := tx.GetContext(ctx, &reserved, reservedByOthers, res.ProductID, res.CartItemID); err != nil {
+				slog.Error("Failed to sum reservations", "product_id", res.ProductID, "error", err)
+				return err
+			}
+
+			if available := stock - reserved; res.Quantity > available {
+				return &model.StockShortageError{ProductID: res.ProductID, Requested: res.Quantity, Available: available}
+			}
+			productIDs = append(productIDs, res.ProductID)
+		}
+
+		query := `
+			INSERT INTO stock_reservations (product_id, cart_item_id, user_id, quantity, expires_at)
+			VALUES (:product_id, :cart_item_id, NULLIF(:user_id, 0), :quantity, :expires_at)
+			ON CONFLICT (cart_item_id, product_id) DO UPDATE
+			SET quantity = EXCLUDED.quantity, expires_at = EXCLUDED.expires_at, updated_at = NOW()
+		`
+		slog.Info("Executing query Reserve", "query", query, "cart_item_id", cartItemID, "products", len(sorted))
+		for _, res := range sorted {
+			if _, err := tx.NamedExecContext(ctx, query, res); err != nil {
+				slog.Error("Failed to reserve stock", "product_id", res.ProductID, "error", err)
+				return err
+			}
+		}
+
+		// Produk yang tidak lagi dipakai baris ini (produk diganti atau komponen bundle berubah)
+		if _, err := tx.ExecContext(ctx, `DELETE FROM stock_reservations WHERE cart_item_id = $1 AND NOT (product_id = ANY($2))`,
+			cartItemID, uintArray(productIDs)); err != nil {
+			slog.Error("Failed to remove stale reservations", "cart_item_id", cartItemID, "error", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// LockProducts mengunci produk beserta komponen bundle-nya (SELECT ... FOR UPDATE) berurutan
+// berdasarkan ID. Harus dipanggil di dalam transaksi; kunci dilepas saat transaksi selesai.
+func (r *inventoryRepo) LockProducts(ctx context.Context, productIDs []uint) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	query := `
+		SELECT id FROM products
+		WHERE id = ANY($1) OR id IN (SELECT component_id FROM product_bundle_items WHERE bundle_id = ANY($1))
+		ORDER BY id
+		FOR UPDATE
+	`
+	var locked []int64
+	slog.Info("Executing query LockProducts", "query", query, "product_ids", productIDs)
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &locked, query, uintArray(productIDs)); err != nil {
+		slog.Error("Failed to lock products", "product_ids", productIDs, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *inventoryRepo) Release(ctx context.Context, cartItemIDs []uint) error {
+	query := `DELETE FROM stock_reservations WHERE cart_item_id = ANY($1)`
+
+	slog.Info("Executing query Release", "query", query, "cart_item_ids", cartItemIDs)
+	if _, err := database.Conn(ctx, r.db).ExecContext(ctx, query, uintArray(cartItemIDs)); err != nil {
+		slog.Error("Failed to release reservations", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *inventoryRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM stock_reservations WHERE expires_at <= $1`, time.Now())
+	if err != nil {
+		slog.Error("Failed to delete expired reservations", "error", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ApplyMovement mencatat movement ke ledger dan mengubah products.quantity dalam satu transaksi.
+// Baris produk dikunci agar saldo quantity_after konsisten dengan urutan movement.
+// Mengembalikan model.ErrNegativeStock jika stok hasilnya negatif.
+func (r *inventoryRepo) ApplyMovement(ctx context.Context, m *model.Movement) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		var current struct {
+			Quantity     int    `db:"quantity"`
+			ReorderPoint *int   `db:"reorder_point"`
+			Type         string `db:"type"`
+		}
+		query := `SELECT COALESCE(quantity, 0) AS quantity, reorder_point, type FROM products WHERE id = $1 FOR UPDATE`
+		if err := tx.GetContext(ctx, &current, query, m.ProductID); err != nil {
+			slog.Error("Failed to lock product for movement", "product_id", m.ProductID, "error", err)
+			return err
+		}
+		if current.Type == "bundle" {
+			return model.ErrBundleStock
+		}
+
+		m.ReorderPoint = current.ReorderPoint
+		m.QuantityAfter = current.Quantity + m.Delta
+		if m.QuantityAfter < 0 {
+			return model.ErrNegativeStock
+		}
+
+		// Perubahan stok juga menaikkan versi produk agar ETag lama tidak berlaku lagi
+		if err := tx.GetContext(ctx, &m.ProductVersion, `UPDATE products SET quantity = $2, version = version + 1, updated_at = NOW() WHERE id = $1 RETURNING version`, m.ProductID, m.QuantityAfter); err != nil {
+			slog.Error("Failed to update product quantity", "product_id", m.ProductID, "error", err)
+			return err
+		}
+
+		query = `
+			INSERT INTO inventory_movements (product_id, delta, quantity_after, reason, actor_id, reference, note)
+			VALUES (:product_id, :delta, :quantity_after, :reason, :actor_id, :reference, :note)
+			RETURNING id, created_at
+		`
+		slog.Info("Executing query ApplyMovement", "query", query, "product_id", m.ProductID, "delta", m.Delta, "reason", m.Reason)
+		rows, err := sqlx.NamedQueryContext(ctx, tx, query, m)
+		if err != nil {
+			slog.Error("Failed to insert inventory movement", "product_id", m.ProductID, "error", err)
+			return err
+		}
+		if rows.Next() {
+			if err := rows.Scan(&m.ID, &m.CreatedAt); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+
+		return nil
+	})
+}
+
+func (r *inventoryRepo) GetMovements(ctx context.Context, productID uint, limit, offset int) ([]model.Movement, int64, error) {
+	var total int64
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &total, `SELECT COUNT(*) FROM inventory_movements WHERE product_id = $1`, productID); err != nil {
+		slog.Error("Failed to count inventory movements", "product_id", productID, "error", err)
+		return nil, 0, err
+	}
+
+	var movements []model.Movement
+	query := `
+		SELECT * FROM inventory_movements
+		WHERE product_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+	slog.Info("Executing query GetMovements", "query", query, "product_id", productID)
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &movements, query, productID, limit, offset); err != nil {
+		slog.Error("Failed to get inventory movements", "product_id", productID, "error", err)
+		return nil, 0, err
+	}
+	return movements, total, nil
+}
+
+// lowStockWhere memilih produk aktif yang stoknya sudah mencapai reorder point
+const lowStockWhere = `
+	WHERE p.deleted_at IS NULL AND p.reorder_point IS NOT NULL
+	  AND COALESCE(p.quantity, 0) <= p.reorder_point`
+
+func (r *inventoryRepo) GetLowStock(ctx context.Context, limit, offset int) ([]model.LowStockItem, int64, error) {
+	var total int64
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &total, `SELECT COUNT(*) FROM products p`+lowStockWhere); err != nil {
+		slog.Error("Failed to count low stock products", "error", err)
+		return nil, 0, err
+	}
+
+	var items []model.LowStockItem
+	query := `
+		SELECT p.id AS product_id, p.sku, p.name, COALESCE(p.quantity, 0) AS quantity,
+			COALESCE(res.reserved, 0) AS reserved,
+			COALESCE(p.quantity, 0) - COALESCE(res.reserved, 0) AS available,
+			p.reorder_point, p.reorder_point - COALESCE(p.quantity, 0) AS shortfall
+		FROM products p
+		LEFT JOIN (
+			SELECT product_id, SUM(quantity) AS reserved
+			FROM stock_reservations
+			WHERE expires_at > NOW()
+			GROUP BY product_id
+		) res ON res.product_id = p.id` + lowStockWhere + `
+		ORDER BY shortfall DESC, p.id
+		LIMIT $1 OFFSET $2
+	`
+
+	slog.Info("Executing query GetLowStock", "query", query)
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &items, query, limit, offset); err != nil {
+		slog.Error("Failed to get low stock products", "error", err)
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// uintArray mengubah []uint menjadi parameter array Postgres
+func uintArray(ids []uint) interface{} {
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id)
+	}
+	return pq.Array(values)
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/inventory/model"
+	"go-fiber-api/internal/app/inventory/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/notifier"
+	"go-fiber-api/utils/web"
+)
+
+type Inventory interface {
+	CheckAvailability(ctx context.Context, productID, cartItemID uint, quantity int) error
+	AvailableStock(ctx context.Context, productID, cartItemID uint) (int, error)
+	Reserve(ctx context.Context, productID, cartItemID, userID uint, quantity int) error
+	ReserveAll(ctx context.Context, cartItemID, userID uint, quantities map[uint]int) error
+	LockProducts(ctx context.Context, productIDs ...uint) error
+	Release(ctx context.Context, cartItemIDs ...uint) error
+	ReleaseExpired(ctx context.Context) (int64, error)
+	RunReservationSweeper(ctx context.Context, interval time.Duration)
+	AdjustStock(ctx context.Context, productID, actorID uint, req *dto.StockAdjustmentRequest) (*model.Movement, error)
+	GetMovements(ctx context.Context, productID uint, limit, offset int) ([]model.Movement, int64, error)
+	GetLowStock(ctx context.Context, limit, offset int) ([]model.LowStockItem, int64, error)
+}
+
+type inventoryService struct {
+	repo           repository.Inventory
+	reservationTTL time.Duration
+	notifier       notifier.Notifier
+}
+
+// NewInventoryService membuat service inventory. Jika reservationTTL nol, reservasi dimatikan
+// dan stok hanya dicek saat item ditambahkan atau diubah di cart.
+func NewInventoryService(repo repository.Inventory, reservationTTL time.Duration, notifier notifier.Notifier) Inventory {
+	return &inventoryService{
+		repo:           repo,
+		reservationTTL: reservationTTL,
+		notifier:       notifier,
+	}
+}
+
+// insufficientStockError membuat error 409 dengan kode ErrInsufficientStock
+func insufficientStockError(productID uint, requested, available int) error {
+	if available < 0 {
+		available = 0
+	}
+	return web.NewHTTPError(http.StatusConflict,
+		fmt.Sprintf("Insufficient stock for product %d: requested %d, available %d", productID, requested, available),
+		web.ErrInsufficientStock)
+}
+
+// AvailableStock mengembalikan stok produk dikurangi reservasi aktif baris cart lain (minimal 0).
+// cartItemID boleh 0 untuk menghitung semua reservasi.
+func (s *inventoryService) AvailableStock(ctx context.Context, productID, cartItemID uint) (int, error) {
+	available, err := s.repo.AvailableStock(ctx, productID, cartItemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to check stock: %w", err)
+	}
+	if available < 0 {
+		available = 0
+	}
+	return available, nil
+}
+
+// CheckAvailability memastikan quantity tidak melebihi stok dikurangi reservasi baris cart lain.
+// cartItemID boleh 0 untuk baris yang belum dibuat.
+func (s *inventoryService) CheckAvailability(ctx context.Context, productID, cartItemID uint, quantity int) error {
+	available, err := s.repo.AvailableStock(ctx, productID, cartItemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check stock: %w", err)
+	}
+
+	if quantity > available {
+		slog.Warn("Insufficient stock", "product_id", productID, "requested", quantity, "available", available)
+		return insufficientStockError(productID, quantity, available)
+	}
+	return nil
+}
+
+// Reserve menahan stok untuk baris cart selama reservationTTL. Jika reservasi dimatikan,
+// hanya melakukan pengecekan stok.
+func (s *inventoryService) Reserve(ctx context.Context, productID, cartItemID, userID uint, quantity int) error {
+	return s.ReserveAll(ctx, cartItemID, userID, map[uint]int{productID: quantity})
+}
+
+// ReserveAll menahan stok beberapa produk untuk satu baris cart (misalnya semua komponen bundle)
+// secara atomik: semua berhasil atau tidak ada yang direservasi. quantities berisi product ID ke quantity.
+func (s *inventoryService) ReserveAll(ctx context.Context, cartItemID, userID uint, quantities map[uint]int) error {
+	if s.reservationTTL <= 0 {
+		for productID, quantity := range quantities {
+			if err := s.CheckAvailability(ctx, productID, cartItemID, quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	expiresAt := time.Now().Add(s.reservationTTL)
+	reservations := make([]model.Reservation, 0, len(quantities))
+	for productID, quantity := range quantities {
+		reservations = append(reservations, model.Reservation{
+			ProductID:  productID,
+			CartItemID: cartItemID,
+			UserID:     userID,
+			Quantity:   quantity,
+			ExpiresAt:  expiresAt,
+		})
+	}
+
+	err := s.repo.Reserve(ctx, reservations)
+	var shortage *model.StockShortageError
+	if errors.As(err, &shortage) {
+		slog.Warn("Insufficient stock for reservation", "product_id", shortage.ProductID, "requested", shortage.Requested, "available", shortage.Available)
+		return insufficientStockError(shortage.ProductID, shortage.Requested, shortage.Available)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reserve stock: %w", err)
+	}
+
+	slog.Info("Stock reserved", "cart_item_id", cartItemID, "products", len(reservations), "expires_at", expiresAt)
+	return nil
+}
+
+// LockProducts mengunci stok produk (termasuk komponen bundle) sampai transaksi di ctx selesai.
+// Dipakai sebelum beberapa reservasi dalam satu transaksi agar produk selalu dikunci dengan urutan
+// yang sama. Tidak melakukan apa-apa jika reservasi dimatikan.
+func (s *inventoryService) LockProducts(ctx context.Context, productIDs ...uint) error {
+	if s.reservationTTL <= 0 {
+		return nil
+	}
+	if err := s.repo.LockProducts(ctx, productIDs); err != nil {
+		return fmt.Errorf("failed to lock products: %w", err)
+	}
+	return nil
+}
+
+// Release melepas semua reservasi milik baris cart yang diberikan
+func (s *inventoryService) Release(ctx context.Context, cartItemIDs ...uint) error {
+	if len(cartItemIDs) == 0 {
+		return nil
+	}
+	if err := s.repo.Release(ctx, cartItemIDs); err != nil {
+		return fmt.Errorf("failed to release reservations: %w", err)
+	}
+	return nil
+}
+
+// ReleaseExpired menghapus reservasi yang sudah kedaluwarsa
+func (s *inventoryService) ReleaseExpired(ctx context.Context) (int64, error) {
+	count, err := s.repo.DeleteExpired(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if count > 0 {
+		slog.Info("Expired reservations released", "count", count)
+	}
+	return count, nil
+}
+
+// RunReservationSweeper membersihkan reservasi kedaluwarsa secara berkala sampai ctx selesai.
+// Reservasi kedaluwarsa sudah tidak dihitung saat cek stok; sweeper hanya menjaga tabel tetap kecil.
+func (s *inventoryService) RunReservationSweeper(ctx context.Context, interval time.Duration) {
+	if s.reservationTTL <= 0 {
+		return
+	}
+
+	slog.Info("Reservation sweeper started", "interval", interval, "ttl", s.reservationTTL)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("Reservation sweeper stopped")
+			return
+		case <-ticker.C:
+			if _, err := s.ReleaseExpired(ctx); err != nil {
+				slog.Error("Failed to release expired reservations", "error", err)
+			}
+		}
+	}
+}
+
+// AdjustStock mencatat perubahan stok ke ledger dan memperbarui quantity produk.
+// restock dan return harus menambah stok, sale harus mengurangi; adjustment bebas.
+func (s *inventoryService) AdjustStock(ctx context.Context, productID, actorID uint, req *dto.StockAdjustmentRequest) (*model.Movement, error) {
+	if err := validateMovementSign(req.Reason, req.Delta); err != nil {
+		return nil, err
+	}
+
+	movement := &model.Movement{
+		ProductID: productID,
+		Delta:     req.Delta,
+		Reason:    req.Reason,
+		Reference: optionalString(req.Reference),
+		Note:      optionalString(req.Note),
+	}
+	if actorID != 0 {
+		movement.ActorID = &actorID
+	}
+
+	err := s.repo.ApplyMovement(ctx, movement)
+	if errors.Is(err, model.ErrNegativeStock) {
+		return nil, web.NewHTTPError(http.StatusConflict,
+			fmt.Sprintf("Stock for product %d cannot go below zero", productID), web.ErrInsufficientStock)
+	}
+	if errors.Is(err, model.ErrBundleStock) {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Bundle stock is derived from its components", web.ErrValidation)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply stock movement: %w", err)
+	}
+
+	slog.Info("Stock movement recorded", "product_id", productID, "delta", req.Delta, "reason", req.Reason, "quantity_after", movement.QuantityAfter, "actor_id", actorID)
+
+	// AdjustStock bisa berjalan di dalam transaksi pemanggil (mis. update produk atau dry-run
+	// import), jadi alert baru dikirim setelah transaksi itu commit
+	if movement.CrossedReorderPoint() {
+		database.AfterCommit(ctx, func() { s.notifyLowStock(ctx, movement) })
+	}
+	return movement, nil
+}
+
+// notifyLowStock mengirim alert stok menipis. Kegagalan notifier hanya dicatat di log
+// agar perubahan stok yang sudah tersimpan tidak dianggap gagal.
+func (s *inventoryService) notifyLowStock(ctx context.Context, movement *model.Movement) {
+	notificationType, subject := "low_stock", fmt.Sprintf("Product %d is running low", movement.ProductID)
+	if movement.QuantityAfter == 0 {
+		notificationType, subject = "out_of_stock", fmt.Sprintf("Product %d is sold out", movement.ProductID)
+	}
+
+	err := s.notifier.Notify(ctx, notifier.Notification{
+		Type:    notificationType,
+		Subject: subject,
+		Message: fmt.Sprintf("Stock dropped to %d (reorder point %d) after %s of %d",
+			movement.QuantityAfter, *movement.ReorderPoint, movement.Reason, movement.Delta),
+		Data: map[string]any{
+			"product_id":    movement.ProductID,
+			"quantity":      movement.QuantityAfter,
+			"reorder_point": *movement.ReorderPoint,
+			"movement_id":   movement.ID,
+		},
+		CreatedAt: movement.CreatedAt,
+	})
+	if err != nil {
+		slog.Error("Failed to send low stock alert", "product_id", movement.ProductID, "error", err)
+	}
+}
+
+// GetLowStock mengembalikan produk yang stoknya sudah di bawah atau sama dengan reorder point
+func (s *inventoryService) GetLowStock(ctx context.Context, limit, offset int) ([]model.LowStockItem, int64, error) {
+	items, total, err := s.repo.GetLowStock(ctx, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if items == nil {
+		items = []model.LowStockItem{}
+	}
+	return items, total, nil
+}
+
+// GetMovements mengembalikan riwayat movement produk, terbaru lebih dulu
+func (s *inventoryService) GetMovements(ctx context.Context, productID uint, limit, offset int) ([]model.Movement, int64, error) {
+	movements, total, err := s.repo.GetMovements(ctx, productID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if movements == nil {
+		movements = []model.Movement{}
+	}
+	return movements, total, nil
+}
+
+// validateMovementSign memastikan arah delta sesuai alasan movement
+func validateMovementSign(reason string, delta int) error {
+	switch reason {
+	case model.ReasonRestock, model.ReasonReturn:
+		if delta < 0 {
+			return web.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("Delta for %s must be positive", reason), web.ErrValidation)
+		}
+	case model.ReasonSale:
+		if delta > 0 {
+			return web.NewHTTPError(http.StatusUnprocessableEntity, "Delta for sale must be negative", web.ErrValidation)
+		}
+	}
+	return nil
+}
+
+// optionalString mengubah string kosong menjadi NULL
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -3,11 +3,15 @@ package controller
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
+	currencyService "go-fiber-api/internal/app/currency/service"
 	"go-fiber-api/internal/app/product/service"
 	"go-fiber-api/internal/shared/dto"
 	"go-fiber-api/internal/shared/types"
@@ -18,56 +22,171 @@ import (
 )
 
 type product struct {
-	productService service.Product
-	decoder        *schema.Decoder
+	productService  service.Product
+	currencyService currencyService.Currency
+	decoder         *schema.Decoder
 }
 
-func NewProductController(mux *http.ServeMux, productService service.Product) {
+func NewProductController(mux *http.ServeMux, productService service.Product, currencyService currencyService.Currency) {
 	p := &product{
-		productService: productService,
-		decoder:        schema.NewDecoder(),
+		productService:  productService,
+		currencyService: currencyService,
+		decoder:         schema.NewDecoder(),
 	}
 	p.decoder.IgnoreUnknownKeys(true)
 
 	mux.HandleFunc("POST /v1/products", middleware.ValidateRole(types.RoleAdmin)(p.Create))
+	mux.HandleFunc("POST /v1/products/import", middleware.ValidateRole(types.RoleAdmin)(p.Import))
+	mux.HandleFunc("GET /v1/products/export", middleware.ValidateRole(types.RoleAdmin)(p.Export))
 	mux.HandleFunc("GET /v1/products", middleware.ValidateRole(types.RoleAdmin)(p.GetAllProducts))
 	mux.HandleFunc("GET /v1/products/{id}", middleware.ValidateRole(types.RoleAdmin)(p.GetProductsByID))
 	mux.HandleFunc("PUT /v1/products/{id}", middleware.ValidateRole(types.RoleAdmin)(p.Update))
+	mux.HandleFunc("PATCH /v1/products/{id}", middleware.ValidateRole(types.RoleAdmin)(p.Patch))
 	mux.HandleFunc("DELETE /v1/products/{id}", middleware.ValidateRole(types.RoleAdmin)(p.Delete))
+	mux.HandleFunc("POST /v1/products/{id}/sales", middleware.ValidateRole(types.RoleAdmin)(p.ScheduleSale))
+	// /v1/products/by-slug/{slug} dan /v1/products/{id}/prices dianggap bentrok oleh ServeMux,
+	// sehingga keduanya dilayani satu handler. Route /v1/products/{id}/reviews dan sejenisnya lebih
+	// spesifik dan menang atas route ini; slug dengan nilai tersebut dicegah di service (reservedSlugs).
+	mux.HandleFunc("GET /v1/products/{id}/{sub}", p.getSubresource)
+}
+
+// getSubresource meneruskan GET /v1/products/by-slug/{slug} (publik) dan
+// GET /v1/products/{id}/prices (admin) ke handler masing-masing
+func (p *product) getSubresource(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.PathValue("id") == "by-slug":
+		r.SetPathValue("slug", r.PathValue("sub"))
+		p.GetProductBySlug(w, r)
+	case r.PathValue("sub") == "prices":
+		middleware.ValidateRole(types.RoleAdmin)(p.GetPriceHistory)(w, r)
+	default:
+		web.Err(w, web.NewHTTPError(http.StatusNotFound, "Not found", web.ErrNotFound))
+	}
 }
 
 func (p *product) GetAllProducts(w http.ResponseWriter, r *http.Request) {
 	slog.Info("GetAllProducts called")
-	products, err := p.productService.GetAllProducts(r.Context())
+
+	var filter dto.ProductFilter
+	if err := p.decoder.Decode(&filter, r.URL.Query()); err != nil {
+		slog.Error("GetAllProducts failed - invalid query", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid query parameters", web.ErrValidation))
+		return
+	}
+	if err := web.Validator().Struct(&filter); err != nil {
+		web.Err(w, err)
+		return
+	}
+	for key, values := range r.URL.Query() {
+		if code, ok := strings.CutPrefix(key, dto.AttributeFilterPrefix); ok {
+			if filter.Attributes == nil {
+				filter.Attributes = make(map[string][]string)
+			}
+			filter.Attributes[code] = values
+		}
+	}
+
+	products, err := p.productService.GetAllProducts(r.Context(), filter)
 	if err != nil {
 		slog.Error("GetAllProducts failed", "error", err)
 		web.Err(w, err)
 		return
 	}
+	if err := p.convertProducts(r, products...); err != nil {
+		web.Err(w, err)
+		return
+	}
 	slog.Info("GetAllProducts success", "count", len(products))
 	web.OK(w, http.StatusOK, products)
 }
 
 func (p *product) GetProductsByID(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := web.PathID(r, "id", "product")
 	if err != nil {
-		slog.Error("Invalid product ID", "id", idStr)
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid product ID", web.ErrValidation))
+		web.Err(w, err)
 		return
 	}
 	slog.Info("GetProductsByID called", "id", id)
 
-	product, err := p.productService.GetProductsByID(r.Context(), uint(id))
+	product, err := p.productService.GetProductsByID(r.Context(), id)
 	if err != nil {
 		slog.Error("GetProductsByID failed", "id", id, "error", err)
 		web.Err(w, err)
 		return
 	}
+	if err := p.convertProducts(r, product); err != nil {
+		web.Err(w, err)
+		return
+	}
 	slog.Info("GetProductsByID success", "id", id)
+	web.SetETag(w, product.Version)
 	web.OK(w, http.StatusOK, product)
 }
 
+// GetProductBySlug bersifat publik untuk URL storefront. Slug lama dijawab 301 ke slug aktif.
+func (p *product) GetProductBySlug(w http.ResponseWriter, r *http.Request) {
+	slug := r.PathValue("slug")
+	slog.Info("GetProductBySlug called", "slug", slug)
+
+	product, currentSlug, err := p.productService.GetProductBySlug(r.Context(), slug)
+	if err != nil {
+		slog.Error("GetProductBySlug failed", "slug", slug, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	if product == nil {
+		location := "/v1/products/by-slug/" + url.PathEscape(currentSlug)
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+		return
+	}
+
+	if err := p.convertProducts(r, product); err != nil {
+		web.Err(w, err)
+		return
+	}
+	web.SetETag(w, product.Version)
+	web.OK(w, http.StatusOK, product)
+}
+
+// convertProducts mengonversi harga ke mata uang yang diminta lewat ?currency= atau Accept-Currency
+func (p *product) convertProducts(r *http.Request, products ...*dto.ProductResponse) error {
+	target := web.RequestedCurrency(r)
+	if target == "" {
+		return nil
+	}
+
+	converter, err := p.currencyService.NewConverter(r.Context(), target)
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		price, info, err := converter.Convert(r.Context(), product.Price)
+		if err != nil {
+			return err
+		}
+		product.Price = price
+		product.Exchange = info
+
+		if product.RegularPrice, _, err = converter.Convert(r.Context(), product.RegularPrice); err != nil {
+			return err
+		}
+
+		if product.CompareAtPrice != nil {
+			compareAt, _, err := converter.Convert(r.Context(), *product.CompareAtPrice)
+			if err != nil {
+				return err
+			}
+			product.CompareAtPrice = &compareAt
+		}
+	}
+	return nil
+}
+
 func (p *product) Create(w http.ResponseWriter, r *http.Request) {
 	body, _ := io.ReadAll(r.Body)
 	slog.Info("Create Product Body", "body", string(body))
@@ -93,19 +212,24 @@ func (p *product) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	slog.Info("Create success", "product_id", product.ID)
+	web.SetETag(w, product.Version)
 	web.OK(w, http.StatusCreated, product)
 }
 
 func (p *product) Update(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := web.PathID(r, "id", "product")
 	if err != nil {
-		slog.Error("Invalid product ID for update", "id", idStr)
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid product ID", web.ErrValidation))
+		web.Err(w, err)
 		return
 	}
 	slog.Info("Update called", "id", id)
 
+	version, err := web.IfMatchVersion(r)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
 	var req dto.ProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		slog.Error("Update failed - invalid JSON", "error", err)
@@ -119,7 +243,7 @@ func (p *product) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	updated, err := p.productService.Update(r.Context(), uint(id), &req)
+	updated, err := p.productService.Update(r.Context(), id, version, &req)
 	if err != nil {
 		slog.Error("Update failed", "id", id, "error", err)
 		web.Err(w, err)
@@ -127,20 +251,76 @@ func (p *product) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	slog.Info("Update success", "id", id)
+	web.SetETag(w, updated.Version)
 	web.OK(w, http.StatusOK, updated)
 }
 
+// Patch menerima application/merge-patch+json (RFC 7396); application/json juga diterima
+func (p *product) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+	slog.Info("Patch called", "id", id)
+
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "" && !strings.HasPrefix(contentType, "application/merge-patch+json") && !strings.HasPrefix(contentType, "application/json") {
+		web.Err(w, web.NewHTTPError(http.StatusUnsupportedMediaType, "Content-Type must be application/merge-patch+json", web.ErrValidation))
+		return
+	}
+
+	version, err := web.IfMatchVersion(r)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.ProductPatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("Patch failed - invalid JSON", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid request body: %v", err), web.ErrValidation))
+		return
+	}
+
+	if req.IsEmpty() {
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Patch body must contain at least one field", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		slog.Error("Patch failed - validation error", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	patched, err := p.productService.Patch(r.Context(), id, version, &req)
+	if err != nil {
+		slog.Error("Patch failed", "id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("Patch success", "id", id, "version", patched.Version)
+	web.SetETag(w, patched.Version)
+	web.OK(w, http.StatusOK, patched)
+}
+
 func (p *product) Delete(w http.ResponseWriter, r *http.Request) {
-	idStr := r.PathValue("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := web.PathID(r, "id", "product")
 	if err != nil {
-		slog.Error("Invalid product ID for delete", "id", idStr)
-		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid product ID", web.ErrValidation))
+		web.Err(w, err)
 		return
 	}
 	slog.Info("Delete called", "id", id)
 
-	if err := p.productService.Delete(r.Context(), uint(id)); err != nil {
+	version, err := web.IfMatchVersion(r)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := p.productService.Delete(r.Context(), id, version); err != nil {
 		slog.Error("Delete failed", "id", id, "error", err)
 		web.Err(w, err)
 		return
@@ -149,3 +329,118 @@ func (p *product) Delete(w http.ResponseWriter, r *http.Request) {
 	slog.Info("Delete success", "id", id)
 	web.OKNoContent(w, http.StatusOK)
 }
+
+// maxImportBodySize membatasi ukuran file import (cukup untuk puluhan ribu baris)
+const maxImportBodySize = 32 << 20
+
+// importFormat menentukan format dari query ?format= atau dari Content-Type
+func importFormat(r *http.Request) string {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format
+	}
+	contentType := r.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return service.FormatCSV
+	case strings.HasPrefix(contentType, "application/x-ndjson"),
+		strings.HasPrefix(contentType, "application/jsonl"):
+		return service.FormatJSONL
+	}
+	return service.FormatCSV
+}
+
+func (p *product) Import(w http.ResponseWriter, r *http.Request) {
+	format := importFormat(r)
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dry_run"))
+	slog.Info("Import called", "format", format, "dry_run", dryRun)
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+	report, err := p.productService.ImportProducts(r.Context(), format, body, dryRun)
+	if err != nil {
+		slog.Error("Import failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("Import success", "total", report.Total, "failed", report.Failed)
+	web.OK(w, http.StatusOK, report)
+}
+
+func (p *product) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = service.FormatCSV
+	}
+	format, err := service.NormalizeFormat(format)
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+	slog.Info("Export called", "format", format)
+
+	contentType := "text/csv; charset=utf-8"
+	if format == service.FormatJSONL {
+		contentType = "application/x-ndjson"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="products.%s"`, format))
+	w.WriteHeader(http.StatusOK)
+
+	// Header sudah terkirim, jadi error di tengah stream hanya bisa dicatat di log
+	if err := p.productService.ExportProducts(r.Context(), format, w); err != nil {
+		slog.Error("Export failed", "format", format, "error", err)
+		return
+	}
+	slog.Info("Export success", "format", format)
+}
+
+func (p *product) ScheduleSale(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+	slog.Info("ScheduleSale called", "id", id)
+
+	var req dto.ProductSaleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("ScheduleSale failed - invalid JSON", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		slog.Error("ScheduleSale failed - validation error", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	sale, err := p.productService.ScheduleSale(r.Context(), id, &req)
+	if err != nil {
+		slog.Error("ScheduleSale failed", "id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("ScheduleSale success", "id", id, "price_id", sale.ID)
+	web.OK(w, http.StatusCreated, sale)
+}
+
+func (p *product) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+	slog.Info("GetPriceHistory called", "id", id)
+
+	prices, err := p.productService.GetPriceHistory(r.Context(), id)
+	if err != nil {
+		slog.Error("GetPriceHistory failed", "id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("GetPriceHistory success", "id", id, "count", len(prices))
+	web.OK(w, http.StatusOK, prices)
+}
@@ -0,0 +1,22 @@
+package model
+
+import "go-fiber-api/internal/shared/types"
+
+// BundleItem adalah satu komponen bundle beserta jumlahnya per satu bundle
+type BundleItem struct {
+	BundleID    uint `db:"bundle_id" json:"bundle_id"`
+	ComponentID uint `db:"component_id" json:"component_id"`
+	Quantity    int  `db:"quantity" json:"quantity"`
+
+	// Kolom hasil join ke produk komponen
+	SKU      *string `db:"sku" json:"sku,omitempty"`
+	Name     string  `db:"name" json:"name"`
+	Price    int64   `db:"price" json:"price"`
+	Currency string  `db:"currency" json:"currency"`
+	Stock    int     `db:"stock" json:"stock"`
+}
+
+// UnitPrice mengembalikan harga normal satu unit komponen
+func (b *BundleItem) UnitPrice() types.Money {
+	return types.NewMoney(b.Price, b.Currency)
+}
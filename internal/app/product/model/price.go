@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// Jenis baris harga di tabel product_prices
+const (
+	PriceKindRegular = "regular"
+	PriceKindSale    = "sale"
+)
+
+// ProductPrice adalah satu baris riwayat harga. Baris regular dicatat setiap kali harga
+// normal berubah, baris sale adalah harga promo yang berlaku pada rentang valid_from - valid_to.
+// Price dan CompareAtPrice dalam minor unit Currency.
+type ProductPrice struct {
+	ID             uint       `db:"id" json:"id"`
+	ProductID      uint       `db:"product_id" json:"product_id"`
+	Kind           string     `db:"kind" json:"kind"`
+	Price          int64      `db:"price" json:"price"`
+	CompareAtPrice *int64     `db:"compare_at_price" json:"compare_at_price,omitempty"`
+	Currency       string     `db:"currency" json:"currency"`
+	ValidFrom      time.Time  `db:"valid_from" json:"valid_from"`
+	ValidTo        *time.Time `db:"valid_to" json:"valid_to,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+}
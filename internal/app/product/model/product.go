@@ -1,14 +1,100 @@
 package model
 
+import (
+	"errors"
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+// ErrVersionConflict dikembalikan repository jika versi produk sudah berubah sejak dibaca
+var ErrVersionConflict = errors.New("product version conflict")
+
+// Tipe produk
+const (
+	TypeSimple = "simple"
+	TypeBundle = "bundle" // Kit berisi beberapa produk lain dengan harga bundle
+)
+
 type Product struct {
 	ID          uint    `db:"id" json:"id"`
+	SKU         *string `db:"sku" json:"sku,omitempty"`
+	Slug        string  `db:"slug" json:"slug"`
 	Name        string  `db:"name" json:"name"`
 	Description string  `db:"description" json:"description"`
 	Quantity    int     `db:"quantity" json:"quantity"`
-	Price       float64 `db:"price" json:"price"`
+	Price       int64   `db:"price" json:"price"`       // Harga normal (regular) dalam minor unit
+	Currency    string  `db:"currency" json:"currency"` // Kode ISO 4217
 	Color       string  `db:"color" json:"color"`
 	Size        string  `db:"size" json:"size"`
 	CreatedAt   string  `db:"created_at" json:"created_at"`
 	UpdatedAt   string  `db:"updated_at" json:"updated_at"`
 	DeletedAt   *string `db:"deleted_at" json:"deleted_at,omitempty"` // Gunakan pointer untuk nullable field
+	Version     int     `db:"version" json:"version"`                 // Naik setiap update, dipakai sebagai ETag
+	Type        string  `db:"type" json:"type"`
+	Category    *string `db:"category" json:"category,omitempty"` // Dipakai untuk filter listing dan eligibility kupon
+	TaxClass    string  `db:"tax_class" json:"tax_class"`
+
+	// Ambang stok untuk alert restock, nil jika tidak dipantau
+	ReorderPoint *int `db:"reorder_point" json:"reorder_point,omitempty"`
+
+	// Batas quantity produk ini dalam satu cart, nil jika tanpa batas
+	MaxPerOrder *int `db:"max_per_order" json:"max_per_order,omitempty"`
+
+	// Berat dan dimensi kemasan untuk ongkos kirim, nil jika belum diisi
+	WeightGrams *int `db:"weight_grams" json:"weight_grams,omitempty"`
+	LengthMM    *int `db:"length_mm" json:"length_mm,omitempty"`
+	WidthMM     *int `db:"width_mm" json:"width_mm,omitempty"`
+	HeightMM    *int `db:"height_mm" json:"height_mm,omitempty"`
+
+	// Nilai atribut kustom sesuai skema attribute_definitions
+	Attributes types.Attributes `db:"attributes" json:"attributes"`
+
+	// Agregat review approved, di-maintain oleh modul review
+	RatingAverage float64 `db:"rating_average" json:"rating_average"`
+	ReviewCount   int     `db:"review_count" json:"review_count"`
+
+	// Kolom hasil join ke product_prices, terisi jika ada harga sale yang berlaku sekarang
+	SalePrice      *int64     `db:"sale_price" json:"sale_price,omitempty"`
+	CompareAtPrice *int64     `db:"compare_at_price" json:"compare_at_price,omitempty"`
+	SaleEndsAt     *time.Time `db:"sale_ends_at" json:"sale_ends_at,omitempty"`
+
+	// Komponen bundle, hanya terisi untuk produk bundle yang dimuat bersama komponennya
+	Components []BundleItem `db:"-" json:"components,omitempty"`
+}
+
+// IsBundle bernilai true untuk produk bundle
+func (p *Product) IsBundle() bool {
+	return p.Type == TypeBundle
+}
+
+// BundleStock menghitung berapa bundle yang bisa dirakit dari stok komponen saat ini
+func (p *Product) BundleStock() int {
+	if len(p.Components) == 0 {
+		return 0
+	}
+	stock := -1
+	for _, component := range p.Components {
+		available := component.Stock / component.Quantity
+		if stock < 0 || available < stock {
+			stock = available
+		}
+	}
+	if stock < 0 {
+		return 0
+	}
+	return stock
+}
+
+// RegularPrice mengembalikan harga normal produk
+func (p *Product) RegularPrice() types.Money {
+	return types.NewMoney(p.Price, p.Currency)
+}
+
+// EffectivePrice mengembalikan harga yang berlaku sekarang: harga sale jika ada, selain itu harga normal
+func (p *Product) EffectivePrice() types.Money {
+	if p.SalePrice != nil {
+		return types.NewMoney(*p.SalePrice, p.Currency)
+	}
+	return p.RegularPrice()
 }
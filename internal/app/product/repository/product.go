@@ -2,34 +2,112 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-fiber-api/database"
 	"go-fiber-api/internal/app/product/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
 	"log/slog"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Product interface {
-	GetAllProducts(ctx context.Context) ([]model.Product, error)
+	GetAllProducts(ctx context.Context, filter dto.ProductFilter, attributes map[string][]any) ([]model.Product, error)
 	GetProductsByID(ctx context.Context, id uint) (*model.Product, error)
 	Create(ctx context.Context, p *model.Product) error
 	Update(ctx context.Context, id uint, p *model.Product) error
-	Delete(ctx context.Context, id uint) error
+	UpdateFields(ctx context.Context, id uint, version int, fields map[string]any) (int, error)
+	Delete(ctx context.Context, id uint, version int) error
+	GetProductBySKU(ctx context.Context, sku string) (*model.Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*model.Product, error)
+	SlugTaken(ctx context.Context, slug string, excludeID uint) (bool, error)
+	FindSlugRedirect(ctx context.Context, slug string) (uint, error)
+	RecordSlugRedirect(ctx context.Context, productID uint, oldSlug, newSlug string) error
+	StreamProducts(ctx context.Context, fn func(p *model.Product) error) error
+	RecordRegularPrice(ctx context.Context, productID uint, price types.Money) error
+	CreateSalePrice(ctx context.Context, price *model.ProductPrice) error
+	HasOverlappingSale(ctx context.Context, productID uint, from, to time.Time) (bool, error)
+	GetPriceHistory(ctx context.Context, productID uint) ([]model.ProductPrice, error)
+	GetBundleItems(ctx context.Context, bundleIDs []uint) ([]model.BundleItem, error)
+	IsBundleComponent(ctx context.Context, productID uint) (bool, error)
 }
 
 type productRepo struct {
 	db *sqlx.DB
 }
 
+// selectProducts memilih produk beserta harga sale yang sedang berlaku (jika ada).
+// Jika ada lebih dari satu sale aktif, yang dimulai paling akhir yang dipakai.
+const selectProducts = `
+	SELECT p.*, sp.price AS sale_price, sp.compare_at_price, sp.valid_to AS sale_ends_at
+	FROM products p
+	LEFT JOIN LATERAL (
+		SELECT pp.price, pp.compare_at_price, pp.valid_to
+		FROM product_prices pp
+		WHERE pp.product_id = p.id AND pp.kind = 'sale'
+		  AND pp.valid_from <= NOW() AND (pp.valid_to IS NULL OR pp.valid_to > NOW())
+		ORDER BY pp.valid_from DESC
+		LIMIT 1
+	) sp ON TRUE`
+
 func NewProductRepository(db *sqlx.DB) Product {
 	return &productRepo{db}
 }
 
-func (r *productRepo) GetAllProducts(ctx context.Context) ([]model.Product, error) {
+// GetAllProducts mengembalikan produk sesuai filter. attributes berisi nilai atribut yang sudah
+// dikonversi ke tipenya (lihat attribute service ParseFilters).
+func (r *productRepo) GetAllProducts(ctx context.Context, filter dto.ProductFilter, attributes map[string][]any) ([]model.Product, error) {
 	var products []model.Product
-	query := `SELECT * FROM products ORDER BY id DESC`
+	var conditions []string
+	var args []interface{}
+
+	if filter.MinRating > 0 {
+		args = append(args, filter.MinRating)
+		conditions = append(conditions, fmt.Sprintf("p.rating_average >= $%d", len(args)))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf("p.category = $%d", len(args)))
+	}
+
+	// Filter atribut memakai containment (@>) agar bisa memakai GIN index;
+	// beberapa nilai untuk satu atribut digabung dengan OR
+	codes := make([]string, 0, len(attributes))
+	for code := range attributes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		var alternatives []string
+		for _, value := range attributes[code] {
+			contained, err := json.Marshal(map[string]any{code: value})
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, string(contained))
+			alternatives = append(alternatives, fmt.Sprintf("p.attributes @> $%d::jsonb", len(args)))
+		}
+		if len(alternatives) > 0 {
+			conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+		}
+	}
 
-	slog.Info("Executing query GetAll", "query", query)
-	if err := r.db.SelectContext(ctx, &products, query); err != nil {
+	query := selectProducts
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY p.id DESC`
+
+	slog.Info("Executing query GetAll", "query", query, "filter", filter)
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &products, query, args...); err != nil {
 		slog.Error("Failed to get all products", "error", err)
 		return nil, err
 	}
@@ -38,64 +116,389 @@ func (r *productRepo) GetAllProducts(ctx context.Context) ([]model.Product, erro
 
 func (r *productRepo) GetProductsByID(ctx context.Context, id uint) (*model.Product, error) {
 	var product model.Product
-	query := `SELECT * FROM products WHERE id = $1`
+	query := selectProducts + ` WHERE p.id = $1`
 
 	slog.Info("Executing query GetByID", "query", query, "id", id)
-	if err := r.db.GetContext(ctx, &product, query, id); err != nil {
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &product, query, id); err != nil {
 		slog.Error("Failed to get product by ID", "id", id, "error", err)
 		return nil, err
 	}
 	return &product, nil
 }
 
+// Create menyimpan produk baru. Untuk bundle, komponennya disimpan dalam transaksi yang sama.
 func (r *productRepo) Create(ctx context.Context, p *model.Product) error {
 	query := `
-		INSERT INTO products (name, description, quantity, price, color, size)
-		VALUES (:name, :description, :quantity, :price, :color, :size)
-		RETURNING id
+		INSERT INTO products (sku, slug, name, description, quantity, price, currency, color, size, reorder_point, max_per_order, category, tax_class,
+			weight_grams, length_mm, width_mm, height_mm, attributes, type)
+		VALUES (:sku, :slug, :name, :description, :quantity, :price, :currency, :color, :size, :reorder_point, :max_per_order, :category, :tax_class,
+			:weight_grams, :length_mm, :width_mm, :height_mm, :attributes, :type)
+		RETURNING id, version
+	`
+
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		slog.Info("Executing query Create", "query", query, "product", p)
+		rows, err := sqlx.NamedQueryContext(ctx, tx, query, p)
+		if err != nil {
+			slog.Error("Failed to create product", "error", err)
+			return err
+		}
+		if rows.Next() {
+			if err := rows.Scan(&p.ID, &p.Version); err != nil {
+				rows.Close()
+				slog.Error("Failed to scan created product ID", "error", err)
+				return err
+			}
+		}
+		rows.Close()
+
+		if p.IsBundle() {
+			if err := replaceBundleItems(ctx, tx, p.ID, p.Components); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Update hanya berhasil jika p.Version masih sama dengan versi di database.
+// Versi baru ditulis kembali ke p; model.ErrVersionConflict jika versi sudah berubah.
+// Komponen bundle hanya diganti jika p.Components tidak nil.
+func (r *productRepo) Update(ctx context.Context, id uint, p *model.Product) error {
+	query := `
+		UPDATE products
+		SET sku = :sku, slug = :slug, name = :name, description = :description,
+			price = :price, currency = :currency, color = :color, size = :size,
+			reorder_point = :reorder_point, max_per_order = :max_per_order, category = :category, tax_class = :tax_class, attributes = :attributes,
+			weight_grams = :weight_grams, length_mm = :length_mm, width_mm = :width_mm, height_mm = :height_mm, version = version + 1
+		WHERE id = :id AND version = :version
+		RETURNING version
 	`
+	p.ID = id
+
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		slog.Info("Executing query Update", "query", query, "id", id, "product", p)
+		rows, err := sqlx.NamedQueryContext(ctx, tx, query, p)
+		if err != nil {
+			slog.Error("Failed to update product", "id", id, "error", err)
+			return err
+		}
+		if !rows.Next() {
+			rows.Close()
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			slog.Warn("Product version conflict", "id", id, "version", p.Version)
+			return model.ErrVersionConflict
+		}
+		err = rows.Scan(&p.Version)
+		rows.Close()
+		if err != nil {
+			return err
+		}
 
-	slog.Info("Executing query Create", "query", query, "product", p)
-	rows, err := r.db.NamedQueryContext(ctx, query, p)
+		if p.IsBundle() && p.Components != nil {
+			if err := replaceBundleItems(ctx, tx, id, p.Components); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// patchableColumns adalah kolom yang boleh diubah lewat UpdateFields
+var patchableColumns = map[string]bool{
+	"sku": true, "slug": true, "name": true, "description": true, "price": true,
+	"currency": true, "color": true, "size": true, "reorder_point": true,
+	"max_per_order": true, "category": true, "tax_class": true, "attributes": true,
+	"weight_grams": true, "length_mm": true, "width_mm": true, "height_mm": true,
+}
+
+// UpdateFields hanya mengubah kolom yang diberikan, dengan pengecekan versi yang sama seperti Update.
+// Mengembalikan versi baru produk.
+func (r *productRepo) UpdateFields(ctx context.Context, id uint, version int, fields map[string]any) (int, error) {
+	columns := make([]string, 0, len(fields))
+	for column := range fields {
+		if !patchableColumns[column] {
+			return 0, fmt.Errorf("column %q cannot be updated", column)
+		}
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	args := []any{id, version}
+	sets := make([]string, 0, len(columns)+1)
+	for _, column := range columns {
+		args = append(args, fields[column])
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	sets = append(sets, "version = version + 1")
+
+	query := `UPDATE products SET ` + strings.Join(sets, ", ") + ` WHERE id = $1 AND version = $2 RETURNING version`
+
+	slog.Info("Executing query UpdateFields", "query", query, "id", id, "columns", columns)
+	var newVersion int
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &newVersion, query, args...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.Warn("Product version conflict", "id", id, "version", version)
+			return 0, model.ErrVersionConflict
+		}
+		slog.Error("Failed to update product fields", "id", id, "error", err)
+		return 0, err
+	}
+	return newVersion, nil
+}
+
+// Delete menghapus produk jika versinya masih sama; version 0 berarti tanpa pengecekan versi
+func (r *productRepo) Delete(ctx context.Context, id uint, version int) error {
+	query := `DELETE FROM products WHERE id = $1 AND ($2 = 0 OR version = $2)`
+
+	slog.Info("Executing query Delete", "query", query, "id", id, "version", version)
+	result, err := database.Conn(ctx, r.db).ExecContext(ctx, query, id, version)
 	if err != nil {
-		slog.Error("Failed to create product", "error", err)
+		slog.Error("Failed to delete product", "id", id, "error", err)
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 && version != 0 {
+		return model.ErrVersionConflict
+	}
+	return nil
+}
+
+func (r *productRepo) GetProductBySKU(ctx context.Context, sku string) (*model.Product, error) {
+	var product model.Product
+	query := selectProducts + ` WHERE p.sku = $1`
+
+	slog.Info("Executing query GetBySKU", "query", query, "sku", sku)
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &product, query, sku); err != nil {
+		slog.Error("Failed to get product by SKU", "sku", sku, "error", err)
+		return nil, err
+	}
+	return &product, nil
+}
+
+// StreamProducts membaca semua produk satu per satu tanpa memuat seluruh tabel ke memori
+func (r *productRepo) StreamProducts(ctx context.Context, fn func(p *model.Product) error) error {
+	query := selectProducts + ` ORDER BY p.id`
+
+	slog.Info("Executing query Stream", "query", query)
+	rows, err := database.Conn(ctx, r.db).QueryxContext(ctx, query)
+	if err != nil {
+		slog.Error("Failed to stream products", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product model.Product
+		if err := rows.StructScan(&product); err != nil {
+			slog.Error("Failed to scan streamed product", "error", err)
+			return err
+		}
+		if err := fn(&product); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// RecordRegularPrice menutup baris harga regular yang masih terbuka lalu mencatat harga baru
+func (r *productRepo) RecordRegularPrice(ctx context.Context, productID uint, price types.Money) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		closeQuery := `
+			UPDATE product_prices SET valid_to = NOW()
+			WHERE product_id = $1 AND kind = 'regular' AND valid_to IS NULL
+		`
+		slog.Info("Executing query RecordRegularPrice", "query", closeQuery, "product_id", productID, "price", price.String())
+		if _, err := tx.ExecContext(ctx, closeQuery, productID); err != nil {
+			slog.Error("Failed to close regular price", "product_id", productID, "error", err)
+			return err
+		}
+
+		insertQuery := `
+			INSERT INTO product_prices (product_id, kind, price, currency, valid_from)
+			VALUES ($1, 'regular', $2, $3, NOW())
+		`
+		if _, err := tx.ExecContext(ctx, insertQuery, productID, price.Amount, price.Currency); err != nil {
+			slog.Error("Failed to record regular price", "product_id", productID, "error", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (r *productRepo) CreateSalePrice(ctx context.Context, price *model.ProductPrice) error {
+	query := `
+		INSERT INTO product_prices (product_id, kind, price, compare_at_price, currency, valid_from, valid_to)
+		VALUES (:product_id, 'sale', :price, :compare_at_price, :currency, :valid_from, :valid_to)
+		RETURNING id, kind, created_at
+	`
+
+	slog.Info("Executing query CreateSalePrice", "query", query, "product_id", price.ProductID)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, price)
+	if err != nil {
+		slog.Error("Failed to create sale price", "product_id", price.ProductID, "error", err)
 		return err
 	}
 	defer rows.Close()
 
 	if rows.Next() {
-		if err := rows.Scan(&p.ID); err != nil {
-			slog.Error("Failed to scan created product ID", "error", err)
+		if err := rows.Scan(&price.ID, &price.Kind, &price.CreatedAt); err != nil {
+			slog.Error("Failed to scan created sale price", "error", err)
 			return err
 		}
 	}
 	return nil
 }
 
-func (r *productRepo) Update(ctx context.Context, id uint, p *model.Product) error {
+func (r *productRepo) HasOverlappingSale(ctx context.Context, productID uint, from, to time.Time) (bool, error) {
+	var exists bool
 	query := `
-		UPDATE products
-		SET name = :name, description = :description, quantity = :quantity,
-			price = :price, color = :color, size = :size
-		WHERE id = :id
+		SELECT EXISTS (
+			SELECT 1 FROM product_prices
+			WHERE product_id = $1 AND kind = 'sale'
+			  AND valid_from < $3 AND (valid_to IS NULL OR valid_to > $2)
+		)
 	`
-	p.ID = id
 
-	slog.Info("Executing query Update", "query", query, "id", id, "product", p)
-	_, err := r.db.NamedExecContext(ctx, query, p)
-	if err != nil {
-		slog.Error("Failed to update product", "id", id, "error", err)
+	slog.Info("Executing query HasOverlappingSale", "query", query, "product_id", productID)
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &exists, query, productID, from, to); err != nil {
+		slog.Error("Failed to check overlapping sale", "product_id", productID, "error", err)
+		return false, err
 	}
-	return err
+	return exists, nil
 }
 
-func (r *productRepo) Delete(ctx context.Context, id uint) error {
-	query := `DELETE FROM products WHERE id = $1`
+func (r *productRepo) GetPriceHistory(ctx context.Context, productID uint) ([]model.ProductPrice, error) {
+	var prices []model.ProductPrice
+	query := `SELECT * FROM product_prices WHERE product_id = $1 ORDER BY valid_from DESC, id DESC`
 
-	slog.Info("Executing query Delete", "query", query, "id", id)
-	_, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		slog.Error("Failed to delete product", "id", id, "error", err)
+	slog.Info("Executing query GetPriceHistory", "query", query, "product_id", productID)
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &prices, query, productID); err != nil {
+		slog.Error("Failed to get price history", "product_id", productID, "error", err)
+		return nil, err
+	}
+	return prices, nil
+}
+
+func (r *productRepo) GetProductBySlug(ctx context.Context, slug string) (*model.Product, error) {
+	var product model.Product
+	query := selectProducts + ` WHERE p.slug = $1`
+
+	slog.Info("Executing query GetBySlug", "query", query, "slug", slug)
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &product, query, slug); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// SlugTaken memeriksa apakah slug sudah dipakai produk lain, baik sebagai slug aktif
+// maupun sebagai slug lama di tabel redirect
+func (r *productRepo) SlugTaken(ctx context.Context, slug string, excludeID uint) (bool, error) {
+	var taken bool
+	query := `
+		SELECT EXISTS (SELECT 1 FROM products WHERE slug = $1 AND id <> $2)
+		    OR EXISTS (SELECT 1 FROM product_slug_redirects WHERE slug = $1 AND product_id <> $2)
+	`
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &taken, query, slug, excludeID); err != nil {
+		slog.Error("Failed to check slug", "slug", slug, "error", err)
+		return false, err
+	}
+	return taken, nil
+}
+
+// FindSlugRedirect mengembalikan ID produk pemilik slug lama
+func (r *productRepo) FindSlugRedirect(ctx context.Context, slug string) (uint, error) {
+	var productID uint
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &productID, `SELECT product_id FROM product_slug_redirects WHERE slug = $1`, slug); err != nil {
+		return 0, err
+	}
+	return productID, nil
+}
+
+// RecordSlugRedirect menyimpan slug lama sebagai redirect. Jika produk kembali memakai
+// slug yang pernah jadi redirect-nya, redirect tersebut dihapus.
+func (r *productRepo) RecordSlugRedirect(ctx context.Context, productID uint, oldSlug, newSlug string) error {
+	return database.RunInTx(ctx, r.db, func(ctx context.Context, tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM product_slug_redirects WHERE slug = $1 AND product_id = $2`, newSlug, productID); err != nil {
+			slog.Error("Failed to delete slug redirect", "product_id", productID, "slug", newSlug, "error", err)
+			return err
+		}
+
+		query := `
+			INSERT INTO product_slug_redirects (slug, product_id)
+			VALUES ($1, $2)
+			ON CONFLICT (slug) DO UPDATE SET product_id = EXCLUDED.product_id, created_at = NOW()
+		`
+		slog.Info("Executing query RecordSlugRedirect", "query", query, "product_id", productID, "old_slug", oldSlug)
+		if _, err := tx.ExecContext(ctx, query, oldSlug, productID); err != nil {
+			slog.Error("Failed to record slug redirect", "product_id", productID, "error", err)
+			return err
+		}
+
+		return nil
+	})
+}
+
+// GetBundleItems mengembalikan komponen dari bundle-bundle yang diberikan beserta data produknya.
+// Produk yang bukan bundle tidak punya baris sehingga tidak muncul di hasil.
+func (r *productRepo) GetBundleItems(ctx context.Context, bundleIDs []uint) ([]model.BundleItem, error) {
+	var items []model.BundleItem
+	if len(bundleIDs) == 0 {
+		return items, nil
+	}
+
+	ids := make([]int64, len(bundleIDs))
+	for i, id := range bundleIDs {
+		ids[i] = int64(id)
 	}
-	return err
+
+	query := `
+		SELECT bi.bundle_id, bi.component_id, bi.quantity,
+			p.sku, p.name, p.price, p.currency, COALESCE(p.quantity, 0) AS stock
+		FROM product_bundle_items bi
+		JOIN products p ON p.id = bi.component_id
+		WHERE bi.bundle_id = ANY($1)
+		ORDER BY bi.bundle_id, bi.component_id
+	`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &items, query, pq.Array(ids)); err != nil {
+		slog.Error("Failed to get bundle items", "bundle_ids", bundleIDs, "error", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+// IsBundleComponent bernilai true jika produk dipakai sebagai komponen bundle mana pun
+func (r *productRepo) IsBundleComponent(ctx context.Context, productID uint) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM product_bundle_items WHERE component_id = $1)`
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &exists, query, productID); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// replaceBundleItems mengganti seluruh komponen bundle di dalam transaksi tx
+func replaceBundleItems(ctx context.Context, tx *sqlx.Tx, bundleID uint, components []model.BundleItem) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_bundle_items WHERE bundle_id = $1`, bundleID); err != nil {
+		slog.Error("Failed to clear bundle items", "bundle_id", bundleID, "error", err)
+		return err
+	}
+
+	for _, component := range components {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO product_bundle_items (bundle_id, component_id, quantity) VALUES ($1, $2, $3)`,
+			bundleID, component.ComponentID, component.Quantity)
+		if err != nil {
+			slog.Error("Failed to save bundle item", "bundle_id", bundleID, "component_id", component.ComponentID, "error", err)
+			return err
+		}
+	}
+	return nil
 }
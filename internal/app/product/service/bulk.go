@@ -0,0 +1,576 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go-fiber-api/internal/app/product/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Format file yang didukung untuk import/export produk
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// Aksi yang dilaporkan per baris import
+const (
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionSkip   = "skip"
+)
+
+// productCSVHeader adalah urutan kolom CSV untuk export, dan kolom yang dikenali saat import
+var productCSVHeader = []string{"id", "sku", "name", "description", "quantity", "price", "currency", "color", "size", "reorder_point", "attributes", "type", "max_per_order", "category", "tax_class",
+	"weight_grams", "length_mm", "width_mm", "height_mm", "components"}
+
+// NormalizeFormat menerima nama format (csv, jsonl, ndjson) dan mengembalikan format baku
+func NormalizeFormat(format string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatCSV:
+		return FormatCSV, nil
+	case FormatJSONL, "ndjson":
+		return FormatJSONL, nil
+	}
+	return "", web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported format %q, use csv or jsonl", format), web.ErrValidation)
+}
+
+// importRow adalah satu baris yang sudah di-decode dari file import
+type importRow struct {
+	line int
+	item dto.ProductImportItem
+	err  error
+}
+
+// rowReader membaca baris import satu per satu
+type rowReader interface {
+	next() (*importRow, error)
+}
+
+func newRowReader(format string, r io.Reader) (rowReader, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVRowReader(r)
+	case FormatJSONL:
+		return newJSONLRowReader(r), nil
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+func newCSVRowReader(r io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "CSV header is missing", web.ErrValidation)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"name", "description", "quantity", "price", "color", "size"} {
+		if _, ok := columns[required]; !ok {
+			return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("CSV header must contain column %q", required), web.ErrValidation)
+		}
+	}
+
+	return &csvRowReader{reader: reader, columns: columns, line: 1}, nil
+}
+
+func (c *csvRowReader) next() (*importRow, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	c.line++
+
+	get := func(column string) string {
+		if i, ok := c.columns[column]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	row := &importRow{line: c.line}
+	var errs []string
+
+	if v := get("id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid id %q", v))
+		}
+		row.item.ID = uint(id)
+	}
+	if v := get("quantity"); v != "" {
+		quantity, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid quantity %q", v))
+		}
+		row.item.Quantity = quantity
+	}
+	if v := get("price"); v != "" {
+		price, err := types.ParseMoney(v, get("currency"))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid price %q: %v", v, err))
+		}
+		row.item.Price = price
+	}
+	if v := get("reorder_point"); v != "" {
+		reorderPoint, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid reorder_point %q", v))
+		}
+		row.item.ReorderPoint = &reorderPoint
+	}
+	if v := get("max_per_order"); v != "" {
+		maxPerOrder, err := strconv.Atoi(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid max_per_order %q", v))
+		}
+		row.item.MaxPerOrder = &maxPerOrder
+	}
+	if v := get("category"); v != "" {
+		row.item.Category = &v
+	}
+	row.item.TaxClass = get("tax_class")
+	for column, target := range map[string]**int{
+		"weight_grams": &row.item.WeightGrams,
+		"length_mm":    &row.item.LengthMM,
+		"width_mm":     &row.item.WidthMM,
+		"height_mm":    &row.item.HeightMM,
+	} {
+		if v := get(column); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("invalid %s %q", column, v))
+			}
+			*target = &n
+		}
+	}
+	// Kolom attributes berisi objek JSON, misalnya {"material":"cotton"}
+	if v := get("attributes"); v != "" {
+		if err := json.Unmarshal([]byte(v), &row.item.Attributes); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid attributes %q: must be a JSON object", v))
+		}
+	}
+	// Kolom components berisi array JSON, misalnya [{"product_id":3,"quantity":2}]
+	if v := get("components"); v != "" {
+		if err := json.Unmarshal([]byte(v), &row.item.Components); err != nil {
+			errs = append(errs, fmt.Sprintf("invalid components %q: must be a JSON array", v))
+		}
+	}
+	row.item.SKU = get("sku")
+	row.item.Name = get("name")
+	row.item.Description = get("description")
+	row.item.Color = get("color")
+	row.item.Size = get("size")
+	row.item.Type = get("type")
+
+	if len(errs) > 0 {
+		row.err = errors.New(strings.Join(errs, "; "))
+	}
+	return row, nil
+}
+
+type jsonlRowReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func newJSONLRowReader(r io.Reader) *jsonlRowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	return &jsonlRowReader{scanner: scanner}
+}
+
+func (j *jsonlRowReader) next() (*importRow, error) {
+	for j.scanner.Scan() {
+		j.line++
+		text := strings.TrimSpace(j.scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		row := &importRow{line: j.line}
+		if err := json.Unmarshal([]byte(text), &row.item); err != nil {
+			row.err = fmt.Errorf("invalid JSON: %v", err)
+		}
+		return row, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+// ImportProducts membaca file CSV/JSONL, memvalidasi setiap baris dengan aturan dto.ProductRequest,
+// lalu meng-upsert produk berdasarkan ID atau SKU. Pada mode dry-run setiap baris ditulis dalam
+// transaksi yang di-rollback, sehingga tidak ada data yang tersimpan.
+func (s *productService) ImportProducts(ctx context.Context, format string, r io.Reader, dryRun bool) (*dto.ProductImportReport, error) {
+	format, err := NormalizeFormat(format)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := newRowReader(format, r)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Importing products", "format", format, "dry_run", dryRun)
+
+	report := &dto.ProductImportReport{DryRun: dryRun, Rows: []dto.ProductImportRowResult{}}
+	seenSKU := map[string]int{}
+	seenID := map[uint]int{}
+
+	for {
+		row, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			slog.Error("Failed to read import file", "error", err)
+			return nil, web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Failed to read import file: %v", err), web.ErrValidation)
+		}
+
+		result := s.importRow(ctx, row, dryRun, seenSKU, seenID)
+		report.Total++
+		switch {
+		case len(result.Errors) > 0:
+			report.Failed++
+		case result.Action == ImportActionCreate:
+			report.Created++
+		case result.Action == ImportActionUpdate:
+			report.Updated++
+		}
+		report.Rows = append(report.Rows, result)
+	}
+
+	slog.Info("Products imported", "total", report.Total, "created", report.Created, "updated", report.Updated, "failed", report.Failed, "dry_run", dryRun)
+	return report, nil
+}
+
+// importRow memproses satu baris: validasi, resolve produk yang sudah ada, lalu create/update
+func (s *productService) importRow(ctx context.Context, row *importRow, dryRun bool, seenSKU map[string]int, seenID map[uint]int) dto.ProductImportRowResult {
+	item := row.item
+	item.SKU = strings.TrimSpace(item.SKU)
+
+	result := dto.ProductImportRowResult{Row: row.line, ProductID: item.ID, SKU: item.SKU, Action: ImportActionSkip}
+	if row.err != nil {
+		result.Errors = append(result.Errors, row.err.Error())
+		return result
+	}
+
+	if err := validateImportItem(&item.ProductRequest); err != nil {
+		result.Errors = append(result.Errors, err...)
+		return result
+	}
+
+	if item.ID != 0 {
+		if first, ok := seenID[item.ID]; ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("duplicate id %d (first seen at row %d)", item.ID, first))
+			return result
+		}
+		seenID[item.ID] = row.line
+	}
+	if item.SKU != "" {
+		if first, ok := seenSKU[item.SKU]; ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("duplicate sku %q (first seen at row %d)", item.SKU, first))
+			return result
+		}
+		seenSKU[item.SKU] = row.line
+	}
+
+	existing, err := s.resolveImportTarget(ctx, &item)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+
+	// Field yang kosong di baris update tidak mengubah nilai yang sudah ada; aturan ini ada di Update
+	if existing == nil {
+		result.Action = ImportActionCreate
+		err = s.writeImportRow(ctx, dryRun, func(ctx context.Context) error {
+			created, err := s.Create(ctx, &item.ProductRequest)
+			if err == nil && !dryRun {
+				result.ProductID = created.ID
+			}
+			return err
+		})
+	} else {
+		result.Action = ImportActionUpdate
+		result.ProductID = existing.ID
+		err = s.writeImportRow(ctx, dryRun, func(ctx context.Context) error {
+			_, err := s.Update(ctx, existing.ID, existing.Version, &item.ProductRequest)
+			return err
+		})
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	}
+	return result
+}
+
+// errDryRunRollback membatalkan transaksi dry-run setelah baris berhasil ditulis
+var errDryRunRollback = errors.New("dry run rollback")
+
+// writeImportRow menjalankan write untuk satu baris import. Pada dry-run write dijalankan dalam
+// transaksi yang selalu di-rollback, sehingga baris diperiksa dengan aturan yang sama persis
+// seperti import sungguhan (tipe, komponen bundle, stok bundle, slug) tanpa menyimpan apa pun.
+func (s *productService) writeImportRow(ctx context.Context, dryRun bool, write func(ctx context.Context) error) error {
+	if !dryRun {
+		return write(ctx)
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := write(ctx); err != nil {
+			return err
+		}
+		return errDryRunRollback
+	})
+	if errors.Is(err, errDryRunRollback) {
+		return nil
+	}
+	return err
+}
+
+// resolveImportTarget mencari produk yang akan di-update. Mengembalikan nil jika baris harus dibuat baru.
+func (s *productService) resolveImportTarget(ctx context.Context, item *dto.ProductImportItem) (*model.Product, error) {
+	if item.ID != 0 {
+		existing, err := s.repo.GetProductsByID(ctx, item.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("product id %d not found", item.ID)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if item.SKU != "" {
+			other, err := s.repo.GetProductBySKU(ctx, item.SKU)
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			if other != nil && other.ID != existing.ID {
+				return nil, fmt.Errorf("sku %q already belongs to product %d", item.SKU, other.ID)
+			}
+		}
+		return existing, nil
+	}
+
+	if item.SKU == "" {
+		return nil, nil
+	}
+
+	existing, err := s.repo.GetProductBySKU(ctx, item.SKU)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// validateImportItem menjalankan aturan validate dari dto.ProductRequest dan mengembalikan pesan per field
+func validateImportItem(req *dto.ProductRequest) []string {
+	err := web.Validator().Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var vErrs validator.ValidationErrors
+	if !errors.As(err, &vErrs) {
+		return []string{err.Error()}
+	}
+
+	msgs := make([]string, 0, len(vErrs))
+	for _, fe := range vErrs {
+		msgs = append(msgs, fmt.Sprintf("Field '%s' failed on the '%s' rule", fe.Field(), fe.Tag()))
+	}
+	return msgs
+}
+
+// exportItem mengubah produk menjadi baris export yang bisa di-import kembali. Untuk bundle,
+// komponennya ikut ditulis dan quantity ditulis 0 karena stoknya dihitung dari komponen.
+func (s *productService) exportItem(ctx context.Context, p *model.Product) (*dto.ProductImportItem, error) {
+	if err := s.loadComponents(ctx, p); err != nil {
+		return nil, err
+	}
+	resp := toProductResponse(p)
+
+	item := &dto.ProductImportItem{
+		ID: resp.ID,
+		ProductRequest: dto.ProductRequest{
+			SKU:         resp.SKU,
+			Name:        resp.Name,
+			Description: resp.Description,
+			Quantity:    resp.Quantity,
+			Price:       p.RegularPrice(),
+			Color:       resp.Color,
+			Size:        resp.Size,
+
+			ReorderPoint: resp.ReorderPoint,
+			MaxPerOrder:  resp.MaxPerOrder,
+			Category:     resp.Category,
+			TaxClass:     resp.TaxClass,
+			Attributes:   resp.Attributes,
+			Type:         resp.Type,
+
+			WeightGrams: resp.WeightGrams,
+			LengthMM:    resp.LengthMM,
+			WidthMM:     resp.WidthMM,
+			HeightMM:    resp.HeightMM,
+		},
+	}
+	if p.IsBundle() {
+		item.Quantity = 0
+		for _, component := range p.Components {
+			item.Components = append(item.Components, dto.BundleComponentRequest{
+				ProductID: component.ComponentID,
+				Quantity:  component.Quantity,
+			})
+		}
+	}
+	return item, nil
+}
+
+// ExportProducts menulis semua produk ke w secara streaming dalam format CSV atau JSONL
+func (s *productService) ExportProducts(ctx context.Context, format string, w io.Writer) error {
+	format, err := NormalizeFormat(format)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Exporting products", "format", format)
+
+	count := 0
+	switch format {
+	case FormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(productCSVHeader); err != nil {
+			return err
+		}
+		err = s.repo.StreamProducts(ctx, func(p *model.Product) error {
+			count++
+			item, err := s.exportItem(ctx, p)
+			if err != nil {
+				return err
+			}
+			if err := writer.Write([]string{
+				strconv.FormatUint(uint64(item.ID), 10),
+				item.SKU,
+				item.Name,
+				item.Description,
+				strconv.Itoa(item.Quantity),
+				item.Price.Decimal(),
+				item.Price.Currency,
+				item.Color,
+				item.Size,
+				formatOptionalInt(item.ReorderPoint),
+				formatAttributes(item.Attributes),
+				item.Type,
+				formatOptionalInt(item.MaxPerOrder),
+				formatOptionalString(item.Category),
+				item.TaxClass,
+				formatOptionalInt(item.WeightGrams),
+				formatOptionalInt(item.LengthMM),
+				formatOptionalInt(item.WidthMM),
+				formatOptionalInt(item.HeightMM),
+				formatComponents(item.Components),
+			}); err != nil {
+				return err
+			}
+			// Flush berkala agar data langsung terkirim ke client
+			if count%100 == 0 {
+				writer.Flush()
+				return writer.Error()
+			}
+			return nil
+		})
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+	case FormatJSONL:
+		encoder := json.NewEncoder(w)
+		err = s.repo.StreamProducts(ctx, func(p *model.Product) error {
+			count++
+			item, err := s.exportItem(ctx, p)
+			if err != nil {
+				return err
+			}
+			return encoder.Encode(item)
+		})
+	}
+
+	if err != nil {
+		slog.Error("Failed to export products", "format", format, "count", count, "error", err)
+		return err
+	}
+
+	slog.Info("Products exported", "format", format, "count", count)
+	return nil
+}
+
+// formatOptionalInt menulis nilai nil sebagai kolom kosong
+func formatOptionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+// formatOptionalString menulis nilai nil sebagai kolom kosong
+func formatOptionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// formatAttributes menulis atribut sebagai objek JSON, atau kolom kosong jika tidak ada
+// formatComponents menulis komponen bundle sebagai array JSON, kosong untuk produk biasa
+func formatComponents(components []dto.BundleComponentRequest) string {
+	if len(components) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(components)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func formatAttributes(attrs types.Attributes) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(attrs)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/product/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/utils/web"
+)
+
+// bundleComponents memvalidasi komponen bundle: minimal satu, tidak duplikat, bukan bundle itu sendiri,
+// dan semuanya produk simple (bundle di dalam bundle tidak didukung).
+func (s *productService) bundleComponents(ctx context.Context, bundleID uint, reqs []dto.BundleComponentRequest) ([]model.BundleItem, error) {
+	if len(reqs) == 0 {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Bundle requires at least one component", web.ErrValidation)
+	}
+
+	components := make([]model.BundleItem, 0, len(reqs))
+	seen := make(map[uint]bool, len(reqs))
+	for _, req := range reqs {
+		if req.ProductID == bundleID {
+			return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Bundle cannot contain itself", web.ErrValidation)
+		}
+		if seen[req.ProductID] {
+			return nil, web.NewHTTPError(http.StatusUnprocessableEntity,
+				fmt.Sprintf("Component %d is listed more than once", req.ProductID), web.ErrValidation)
+		}
+		seen[req.ProductID] = true
+
+		component, err := s.repo.GetProductsByID(ctx, req.ProductID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusUnprocessableEntity,
+				fmt.Sprintf("Component product %d not found", req.ProductID), web.ErrValidation)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if component.IsBundle() {
+			return nil, web.NewHTTPError(http.StatusUnprocessableEntity,
+				fmt.Sprintf("Component %d is itself a bundle", req.ProductID), web.ErrValidation)
+		}
+
+		components = append(components, model.BundleItem{
+			BundleID:    bundleID,
+			ComponentID: component.ID,
+			Quantity:    req.Quantity,
+			SKU:         component.SKU,
+			Name:        component.Name,
+			Price:       component.Price,
+			Currency:    component.Currency,
+			Stock:       component.Quantity,
+		})
+	}
+	return components, nil
+}
+
+// checkBundleQuantity menolak quantity bundle yang berbeda dari stok turunan komponennya.
+// Quantity yang sama dengan response GET dianggap dikirim balik apa adanya dan diterima.
+func (s *productService) checkBundleQuantity(ctx context.Context, product *model.Product, quantity int) error {
+	if err := s.loadComponents(ctx, product); err != nil {
+		return err
+	}
+	if quantity != product.BundleStock() {
+		return web.NewHTTPError(http.StatusUnprocessableEntity, "Bundle stock is derived from its components", web.ErrValidation)
+	}
+	return nil
+}
+
+// loadComponents mengisi komponen untuk produk bundle dengan satu query
+func (s *productService) loadComponents(ctx context.Context, products ...*model.Product) error {
+	byID := make(map[uint]*model.Product)
+	ids := make([]uint, 0)
+	for _, product := range products {
+		if product.IsBundle() {
+			byID[product.ID] = product
+			ids = append(ids, product.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	items, err := s.repo.GetBundleItems(ctx, ids)
+	if err != nil {
+		slog.Error("Failed to load bundle components", "bundle_ids", ids, "error", err)
+		return err
+	}
+	for _, product := range byID {
+		product.Components = []model.BundleItem{}
+	}
+	for _, item := range items {
+		byID[item.BundleID].Components = append(byID[item.BundleID].Components, item)
+	}
+	return nil
+}
+
+// toBundleComponentResponses memetakan komponen bundle ke response API
+func toBundleComponentResponses(items []model.BundleItem) []dto.BundleComponentResponse {
+	components := make([]dto.BundleComponentResponse, 0, len(items))
+	for _, item := range items {
+		component := dto.BundleComponentResponse{
+			ProductID: item.ComponentID,
+			Name:      item.Name,
+			Quantity:  item.Quantity,
+			Price:     item.UnitPrice(),
+		}
+		if item.SKU != nil {
+			component.SKU = *item.SKU
+		}
+		components = append(components, component)
+	}
+	return components
+}
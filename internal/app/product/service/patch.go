@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+
+	inventoryModel "go-fiber-api/internal/app/inventory/model"
+	"go-fiber-api/internal/app/product/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+)
+
+// nullableFields adalah field PATCH yang boleh dikirim null untuk menghapus nilainya
+var nullableFields = map[string]bool{
+	"sku":           true,
+	"reorder_point": true,
+	"max_per_order": true,
+	"category":      true,
+	"weight_grams":  true,
+	"length_mm":     true,
+	"width_mm":      true,
+	"height_mm":     true,
+	"attributes":    true,
+}
+
+// Patch menerapkan JSON Merge Patch ke produk. Hanya kolom yang berubah yang ditulis ke database;
+// perubahan quantity dicatat lewat ledger inventory.
+func (s *productService) Patch(ctx context.Context, id uint, version int, req *dto.ProductPatchRequest) (*dto.ProductResponse, error) {
+	slog.Info("Patching product", "product_id", id, "version", version)
+
+	for field := range req.Nulls {
+		if !nullableFields[field] {
+			return nil, web.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("Field '%s' cannot be null", field), web.ErrValidation)
+		}
+	}
+
+	product, err := s.repo.GetProductsByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		slog.Error("Failed to fetch product for patch", "product_id", id, "error", err)
+		return nil, err
+	}
+	if version != 0 && product.Version != version {
+		return nil, versionConflictError(product)
+	}
+	if product.IsBundle() && req.Quantity != nil {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Bundle stock is derived from its components", web.ErrValidation)
+	}
+
+	currentName := product.Name
+	fields := productPatchFields(product, req)
+	if fields["attributes"] != nil {
+		if err := s.attributes.Validate(ctx, product.Attributes); err != nil {
+			return nil, err
+		}
+	}
+	if fields["tax_class"] != nil {
+		if err := s.taxes.ValidateClass(ctx, product.TaxClass); err != nil {
+			return nil, err
+		}
+	}
+	priceChanged := fields["price"] != nil || fields["currency"] != nil
+
+	var oldSlug string
+	if product.Name != currentName {
+		if oldSlug, err = s.renameSlug(ctx, product, product.Name); err != nil {
+			return nil, err
+		}
+		if oldSlug != "" {
+			fields["slug"] = product.Slug
+		}
+	}
+
+	// Sama seperti Update, semua penulisan di-commit bersama
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if len(fields) > 0 {
+			newVersion, err := s.repo.UpdateFields(ctx, id, product.Version, fields)
+			if errors.Is(err, model.ErrVersionConflict) {
+				return s.currentVersionConflict(ctx, id)
+			}
+			if err != nil {
+				slog.Error("Failed to patch product", "product_id", id, "error", err)
+				return err
+			}
+			product.Version = newVersion
+		}
+
+		if err := s.recordSlugRedirect(ctx, product, oldSlug); err != nil {
+			return err
+		}
+
+		if priceChanged {
+			if err := s.repo.RecordRegularPrice(ctx, id, product.RegularPrice()); err != nil {
+				slog.Error("Failed to record price change", "product_id", id, "error", err)
+				return err
+			}
+		}
+
+		if req.Quantity == nil {
+			return nil
+		}
+		return s.changeStock(ctx, product, *req.Quantity, inventoryModel.ReasonAdjustment, "product-patch")
+	})
+	if err != nil {
+		return nil, slugConflict(err)
+	}
+
+	if err := s.loadComponents(ctx, product); err != nil {
+		return nil, err
+	}
+
+	slog.Info("Product patched successfully", "product_id", id, "columns", len(fields), "version", product.Version)
+	return toProductResponse(product), nil
+}
+
+// productPatchFields membandingkan patch dengan produk saat ini, menerapkan perubahan ke product,
+// dan mengembalikan kolom yang benar-benar berubah
+func productPatchFields(product *model.Product, req *dto.ProductPatchRequest) map[string]any {
+	fields := make(map[string]any)
+
+	setString := func(column string, current *string, value *string) {
+		if value != nil && *value != *current {
+			*current = *value
+			fields[column] = *value
+		}
+	}
+	setString("name", &product.Name, req.Name)
+	setString("description", &product.Description, req.Description)
+	setString("color", &product.Color, req.Color)
+	setString("size", &product.Size, req.Size)
+
+	switch {
+	case req.Nulls["sku"]:
+		if product.SKU != nil {
+			product.SKU = nil
+			fields["sku"] = nil
+		}
+	case req.SKU != nil:
+		sku := skuPtr(*req.SKU)
+		if !equalStringPtr(product.SKU, sku) {
+			product.SKU = sku
+			fields["sku"] = sku
+		}
+	}
+
+	// setOptionalInt untuk kolom int nullable: null menghapus nilai, angka menggantinya
+	setOptionalInt := func(column string, current **int, value *int) {
+		switch {
+		case req.Nulls[column]:
+			if *current != nil {
+				*current = nil
+				fields[column] = nil
+			}
+		case value != nil:
+			if *current == nil || **current != *value {
+				*current = value
+				fields[column] = *value
+			}
+		}
+	}
+	setOptionalInt("reorder_point", &product.ReorderPoint, req.ReorderPoint)
+	setOptionalInt("max_per_order", &product.MaxPerOrder, req.MaxPerOrder)
+	setOptionalInt("weight_grams", &product.WeightGrams, req.WeightGrams)
+	setOptionalInt("length_mm", &product.LengthMM, req.LengthMM)
+	setOptionalInt("width_mm", &product.WidthMM, req.WidthMM)
+	setOptionalInt("height_mm", &product.HeightMM, req.HeightMM)
+
+	if req.TaxClass != nil && *req.TaxClass != product.TaxClass {
+		product.TaxClass = *req.TaxClass
+		fields["tax_class"] = *req.TaxClass
+	}
+
+	switch {
+	case req.Nulls["category"]:
+		if product.Category != nil {
+			product.Category = nil
+			fields["category"] = nil
+		}
+	case req.Category != nil:
+		if product.Category == nil || *product.Category != *req.Category {
+			product.Category = req.Category
+			fields["category"] = *req.Category
+		}
+	}
+
+	if req.Price != nil {
+		if req.Price.Amount != product.Price {
+			product.Price = req.Price.Amount
+			fields["price"] = req.Price.Amount
+		}
+		// Mata uang kosong berarti mata uang produk tidak berubah
+		if currency := strings.ToUpper(req.Price.Currency); currency != "" && currency != product.Currency {
+			product.Currency = currency
+			fields["currency"] = currency
+		}
+	}
+
+	if req.Nulls["attributes"] || req.Attributes != nil {
+		merged := mergeAttributes(product.Attributes, req.Attributes, req.Nulls["attributes"])
+		if !reflect.DeepEqual(merged, attributesOrEmpty(product.Attributes)) {
+			product.Attributes = merged
+			fields["attributes"] = merged
+		}
+	}
+
+	return fields
+}
+
+// mergeAttributes menerapkan merge patch ke atribut: atribut bernilai null dihapus,
+// dan reset menghapus semua atribut lama sebelum patch diterapkan
+func mergeAttributes(current types.Attributes, patch map[string]any, reset bool) types.Attributes {
+	merged := types.Attributes{}
+	if !reset {
+		for code, value := range current {
+			merged[code] = value
+		}
+	}
+	for code, value := range patch {
+		if value == nil {
+			delete(merged, code)
+			continue
+		}
+		merged[code] = value
+	}
+	return merged
+}
+
+func equalStringPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go-fiber-api/internal/app/product/model"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+)
+
+// ScheduleSale menjadwalkan harga sale untuk produk. Sale tidak boleh tumpang tindih dengan sale lain
+// dan harganya harus lebih rendah dari compare-at price.
+func (s *productService) ScheduleSale(ctx context.Context, id uint, req *dto.ProductSaleRequest) (*dto.ProductPriceResponse, error) {
+	slog.Info("Scheduling sale", "product_id", id, "price", req.Price.String(), "valid_from", req.ValidFrom, "valid_to", req.ValidTo)
+
+	product, err := s.repo.GetProductsByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		slog.Error("Failed to fetch product for sale", "product_id", id, "error", err)
+		return nil, err
+	}
+
+	if !req.ValidTo.After(time.Now()) {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "valid_to must be in the future", web.ErrValidation)
+	}
+
+	regular := product.RegularPrice()
+	compareAt := regular
+	if req.CompareAtPrice != nil {
+		compareAt = *req.CompareAtPrice
+	}
+	if priceCurrency(req.Price) != regular.Currency || compareAt.Currency != regular.Currency {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "Sale price must use the product currency "+regular.Currency, web.ErrValidation)
+	}
+	if req.Price.Amount >= compareAt.Amount {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "Sale price must be lower than the compare-at price", web.ErrValidation)
+	}
+
+	overlap, err := s.repo.HasOverlappingSale(ctx, id, req.ValidFrom, req.ValidTo)
+	if err != nil {
+		return nil, err
+	}
+	if overlap {
+		return nil, web.NewHTTPError(http.StatusConflict, "Another sale is already scheduled in this period", web.ErrConflict)
+	}
+
+	validTo := req.ValidTo
+	price := &model.ProductPrice{
+		ProductID:      id,
+		Price:          req.Price.Amount,
+		CompareAtPrice: &compareAt.Amount,
+		Currency:       regular.Currency,
+		ValidFrom:      req.ValidFrom,
+		ValidTo:        &validTo,
+	}
+	if err := s.repo.CreateSalePrice(ctx, price); err != nil {
+		slog.Error("Failed to schedule sale", "product_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Sale scheduled successfully", "product_id", id, "price_id", price.ID)
+	resp := toProductPriceResponse(price)
+	return &resp, nil
+}
+
+// GetPriceHistory mengembalikan seluruh riwayat harga regular dan sale, terbaru lebih dulu
+func (s *productService) GetPriceHistory(ctx context.Context, id uint) ([]dto.ProductPriceResponse, error) {
+	slog.Info("Fetching price history", "product_id", id)
+
+	if _, err := s.repo.GetProductsByID(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+		}
+		return nil, err
+	}
+
+	prices, err := s.repo.GetPriceHistory(ctx, id)
+	if err != nil {
+		slog.Error("Failed to fetch price history", "product_id", id, "error", err)
+		return nil, err
+	}
+
+	result := make([]dto.ProductPriceResponse, 0, len(prices))
+	for i := range prices {
+		result = append(result, toProductPriceResponse(&prices[i]))
+	}
+
+	slog.Info("Price history fetched", "product_id", id, "count", len(result))
+	return result, nil
+}
+
+func toProductPriceResponse(price *model.ProductPrice) dto.ProductPriceResponse {
+	resp := dto.ProductPriceResponse{
+		ID:        price.ID,
+		ProductID: price.ProductID,
+		Kind:      price.Kind,
+		Price:     types.NewMoney(price.Price, price.Currency),
+		ValidFrom: price.ValidFrom,
+		ValidTo:   price.ValidTo,
+		CreatedAt: price.CreatedAt,
+	}
+	if price.CompareAtPrice != nil {
+		compareAt := types.NewMoney(*price.CompareAtPrice, price.Currency)
+		resp.CompareAtPrice = &compareAt
+	}
+	return resp
+}
@@ -3,78 +3,159 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
 	"log/slog"
+	"net/http"
+	"strings"
 
+	"go-fiber-api/database"
+	attributeService "go-fiber-api/internal/app/attribute/service"
+	inventoryModel "go-fiber-api/internal/app/inventory/model"
+	inventoryService "go-fiber-api/internal/app/inventory/service"
 	"go-fiber-api/internal/app/product/model"
 	"go-fiber-api/internal/app/product/repository"
+	taxModel "go-fiber-api/internal/app/tax/model"
+	taxService "go-fiber-api/internal/app/tax/service"
 	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
 )
 
 type Product interface {
 	Create(ctx context.Context, req *dto.ProductRequest) (*dto.ProductResponse, error)
-	GetAllProducts(ctx context.Context) ([]*dto.ProductResponse, error)
+	GetAllProducts(ctx context.Context, filter dto.ProductFilter) ([]*dto.ProductResponse, error)
 	GetProductsByID(ctx context.Context, id uint) (*dto.ProductResponse, error)
-	Update(ctx context.Context, id uint, req *dto.ProductRequest) (*dto.ProductResponse, error)
-	Delete(ctx context.Context, id uint) error
+	GetProductBySlug(ctx context.Context, slug string) (*dto.ProductResponse, string, error)
+	Update(ctx context.Context, id uint, version int, req *dto.ProductRequest) (*dto.ProductResponse, error)
+	Patch(ctx context.Context, id uint, version int, req *dto.ProductPatchRequest) (*dto.ProductResponse, error)
+	Delete(ctx context.Context, id uint, version int) error
+	ImportProducts(ctx context.Context, format string, r io.Reader, dryRun bool) (*dto.ProductImportReport, error)
+	ExportProducts(ctx context.Context, format string, w io.Writer) error
+	ScheduleSale(ctx context.Context, id uint, req *dto.ProductSaleRequest) (*dto.ProductPriceResponse, error)
+	GetPriceHistory(ctx context.Context, id uint) ([]dto.ProductPriceResponse, error)
 }
 
 type productService struct {
-	repo repository.Product
+	repo       repository.Product
+	inventory  inventoryService.Inventory
+	attributes attributeService.Attribute
+	taxes      taxService.Tax
+	tx         database.Transactor
 }
 
-func NewProductService(repo repository.Product) Product {
-	return &productService{repo: repo}
+func NewProductService(repo repository.Product, inventory inventoryService.Inventory, attributes attributeService.Attribute, taxes taxService.Tax, tx database.Transactor) Product {
+	return &productService{
+		repo:       repo,
+		inventory:  inventory,
+		attributes: attributes,
+		taxes:      taxes,
+		tx:         tx,
+	}
 }
 
 func (s *productService) Create(ctx context.Context, req *dto.ProductRequest) (*dto.ProductResponse, error) {
 	slog.Info("Creating product", "name", req.Name)
 
+	if err := s.attributes.Validate(ctx, req.Attributes); err != nil {
+		return nil, err
+	}
+	taxClass := req.TaxClass
+	if taxClass == "" {
+		taxClass = taxModel.DefaultClass
+	}
+	if err := s.taxes.ValidateClass(ctx, taxClass); err != nil {
+		return nil, err
+	}
+
 	product := &model.Product{
+		SKU:         skuPtr(req.SKU),
 		Name:        req.Name,
 		Description: req.Description,
-		Quantity:    req.Quantity,
-		Price:       req.Price,
+		Price:       req.Price.Amount,
+		Currency:    priceCurrency(req.Price),
 		Color:       req.Color,
 		Size:        req.Size,
+
+		ReorderPoint: req.ReorderPoint,
+		MaxPerOrder:  req.MaxPerOrder,
+		Category:     req.Category,
+		TaxClass:     taxClass,
+		Attributes:   attributesOrEmpty(req.Attributes),
+		Type:         productType(req.Type),
+
+		WeightGrams: req.WeightGrams,
+		LengthMM:    req.LengthMM,
+		WidthMM:     req.WidthMM,
+		HeightMM:    req.HeightMM,
 	}
 
-	if err := s.repo.Create(ctx, product); err != nil {
-		slog.Error("Failed to create product", "error", err)
+	if product.IsBundle() {
+		components, err := s.bundleComponents(ctx, 0, req.Components)
+		if err != nil {
+			return nil, err
+		}
+		product.Components = components
+	} else if len(req.Components) > 0 {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Only bundle products can have components", web.ErrValidation)
+	}
+
+	slug, err := s.uniqueSlug(ctx, req.Name, 0)
+	if err != nil {
 		return nil, err
 	}
+	product.Slug = slug
+
+	// Produk, riwayat harga dan stok awal ditulis dalam satu transaksi
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, product); err != nil {
+			slog.Error("Failed to create product", "error", err)
+			return err
+		}
+
+		if err := s.repo.RecordRegularPrice(ctx, product.ID, product.RegularPrice()); err != nil {
+			slog.Error("Failed to record initial price", "product_id", product.ID, "error", err)
+			return err
+		}
+
+		// Stok awal masuk lewat ledger sebagai restock. Bundle tidak punya stok sendiri.
+		if product.IsBundle() {
+			return nil
+		}
+		return s.changeStock(ctx, product, req.Quantity, inventoryModel.ReasonRestock, "product-create")
+	})
+	if err != nil {
+		return nil, slugConflict(err)
+	}
 
 	slog.Info("Product created successfully", "product_id", product.ID)
-	return &dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Quantity:    product.Quantity,
-		Price:       product.Price,
-		Color:       product.Color,
-		Size:        product.Size,
-	}, nil
+	return toProductResponse(product), nil
 }
 
-func (s *productService) GetAllProducts(ctx context.Context) ([]*dto.ProductResponse, error) {
-	slog.Info("Fetching all products")
+func (s *productService) GetAllProducts(ctx context.Context, filter dto.ProductFilter) ([]*dto.ProductResponse, error) {
+	slog.Info("Fetching all products", "filter", filter)
+
+	attributes, err := s.attributes.ParseFilters(ctx, filter.Attributes)
+	if err != nil {
+		return nil, err
+	}
 
-	products, err := s.repo.GetAllProducts(ctx)
+	products, err := s.repo.GetAllProducts(ctx, filter, attributes)
 	if err != nil {
 		slog.Error("Failed to fetch products", "error", err)
 		return nil, err
 	}
 
+	refs := make([]*model.Product, len(products))
+	for i := range products {
+		refs[i] = &products[i]
+	}
+	if err := s.loadComponents(ctx, refs...); err != nil {
+		return nil, err
+	}
+
 	var result []*dto.ProductResponse
-	for _, product := range products {
-		result = append(result, &dto.ProductResponse{
-			ID:          product.ID,
-			Name:        product.Name,
-			Description: product.Description,
-			Quantity:    product.Quantity,
-			Price:       product.Price,
-			Color:       product.Color,
-			Size:        product.Size,
-		})
+	for _, product := range refs {
+		result = append(result, toProductResponse(product))
 	}
 
 	slog.Info("Fetched products successfully", "count", len(result))
@@ -89,56 +170,166 @@ func (s *productService) GetProductsByID(ctx context.Context, id uint) (*dto.Pro
 		slog.Error("Failed to fetch product by ID", "product_id", id, "error", err)
 		return nil, err
 	}
+	if err := s.loadComponents(ctx, product); err != nil {
+		return nil, err
+	}
 
 	slog.Info("Product found", "product_id", id)
-	return &dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Quantity:    product.Quantity,
-		Price:       product.Price,
-		Color:       product.Color,
-		Size:        product.Size,
-	}, nil
+	return toProductResponse(product), nil
 }
 
-func (s *productService) Update(ctx context.Context, id uint, req *dto.ProductRequest) (*dto.ProductResponse, error) {
-	slog.Info("Updating product", "product_id", id)
+// Update memperbarui produk. version adalah versi dari If-Match; 0 berarti tanpa pengecekan versi
+// (dipakai bulk import). Jika versi sudah berubah, dikembalikan 412 beserta representasi terbaru.
+// Field opsional yang tidak dikirim tidak diubah; nilainya dihapus lewat PATCH dengan null.
+func (s *productService) Update(ctx context.Context, id uint, version int, req *dto.ProductRequest) (*dto.ProductResponse, error) {
+	slog.Info("Updating product", "product_id", id, "version", version)
 
 	product, err := s.repo.GetProductsByID(ctx, id)
 	if err != nil {
 		slog.Warn("Product not found", "product_id", id)
 		return nil, errors.New("product not found")
 	}
+	if version != 0 && product.Version != version {
+		return nil, versionConflictError(product)
+	}
+	if err := s.attributes.Validate(ctx, req.Attributes); err != nil {
+		return nil, err
+	}
+	if req.TaxClass != "" && req.TaxClass != product.TaxClass {
+		if err := s.taxes.ValidateClass(ctx, req.TaxClass); err != nil {
+			return nil, err
+		}
+		product.TaxClass = req.TaxClass
+	}
+	if req.Type != "" && req.Type != product.Type {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Product type cannot be changed", web.ErrValidation)
+	}
+	if product.IsBundle() && req.Quantity != 0 {
+		if err := s.checkBundleQuantity(ctx, product, req.Quantity); err != nil {
+			return nil, err
+		}
+	}
+	if product.IsBundle() && req.Components != nil {
+		if product.Components, err = s.bundleComponents(ctx, id, req.Components); err != nil {
+			return nil, err
+		}
+	} else if !product.IsBundle() && len(req.Components) > 0 {
+		return nil, web.NewHTTPError(http.StatusUnprocessableEntity, "Only bundle products can have components", web.ErrValidation)
+	}
+
+	// req.Price selalu harga normal (regular_price pada response), bukan harga sale yang berlaku
+	price := types.NewMoney(req.Price.Amount, priceCurrency(req.Price))
+	priceChanged := price != product.RegularPrice()
+
+	var oldSlug string
+	if req.Name != product.Name {
+		if oldSlug, err = s.renameSlug(ctx, product, req.Name); err != nil {
+			return nil, err
+		}
+	}
 
+	// SKU yang tidak dikirim tidak dihapus; gunakan PATCH dengan null untuk menghapusnya
+	if sku := skuPtr(req.SKU); sku != nil {
+		product.SKU = sku
+	}
 	product.Name = req.Name
 	product.Description = req.Description
-	product.Quantity = req.Quantity
-	product.Price = req.Price
+	product.Price = price.Amount
+	product.Currency = price.Currency
 	product.Color = req.Color
 	product.Size = req.Size
+	if req.ReorderPoint != nil {
+		product.ReorderPoint = req.ReorderPoint
+	}
+	if req.MaxPerOrder != nil {
+		product.MaxPerOrder = req.MaxPerOrder
+	}
+	if req.Category != nil {
+		product.Category = req.Category
+	}
+	if req.WeightGrams != nil {
+		product.WeightGrams = req.WeightGrams
+	}
+	if req.LengthMM != nil {
+		product.LengthMM = req.LengthMM
+	}
+	if req.WidthMM != nil {
+		product.WidthMM = req.WidthMM
+	}
+	if req.HeightMM != nil {
+		product.HeightMM = req.HeightMM
+	}
+	if req.Attributes != nil {
+		product.Attributes = req.Attributes
+	}
 
-	if err := s.repo.Update(ctx, id, product); err != nil {
-		slog.Error("Failed to update product", "product_id", id, "error", err)
-		return nil, err
+	// Produk, redirect slug, riwayat harga dan ledger stok di-commit bersama
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Update(ctx, id, product); err != nil {
+			if errors.Is(err, model.ErrVersionConflict) {
+				return s.currentVersionConflict(ctx, id)
+			}
+			slog.Error("Failed to update product", "product_id", id, "error", err)
+			return err
+		}
+
+		if err := s.recordSlugRedirect(ctx, product, oldSlug); err != nil {
+			return err
+		}
+
+		if priceChanged {
+			if err := s.repo.RecordRegularPrice(ctx, id, product.RegularPrice()); err != nil {
+				slog.Error("Failed to record price change", "product_id", id, "error", err)
+				return err
+			}
+		}
+
+		// Quantity tidak ditimpa langsung; selisihnya dicatat sebagai adjustment di ledger
+		if product.IsBundle() {
+			return nil
+		}
+		return s.changeStock(ctx, product, req.Quantity, inventoryModel.ReasonAdjustment, "product-update")
+	})
+	if err != nil {
+		return nil, slugConflict(err)
+	}
+	if product.IsBundle() {
+		if err := s.loadComponents(ctx, product); err != nil {
+			return nil, err
+		}
 	}
 
 	slog.Info("Product updated successfully", "product_id", id)
-	return &dto.ProductResponse{
-		ID:          product.ID,
-		Name:        product.Name,
-		Description: product.Description,
-		Quantity:    product.Quantity,
-		Price:       product.Price,
-		Color:       product.Color,
-		Size:        product.Size,
-	}, nil
+	return toProductResponse(product), nil
 }
 
-func (s *productService) Delete(ctx context.Context, id uint) error {
-	slog.Info("Deleting product", "product_id", id)
+// Delete menghapus produk. version 0 berarti tanpa pengecekan versi.
+func (s *productService) Delete(ctx context.Context, id uint, version int) error {
+	slog.Info("Deleting product", "product_id", id, "version", version)
+
+	inBundle, err := s.repo.IsBundleComponent(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inBundle {
+		return web.NewHTTPError(http.StatusConflict, "Product is a component of a bundle; remove it from the bundle first", web.ErrConflict)
+	}
+
+	if version != 0 {
+		product, err := s.repo.GetProductsByID(ctx, id)
+		if err != nil {
+			slog.Warn("Product not found", "product_id", id)
+			return errors.New("product not found")
+		}
+		if product.Version != version {
+			return versionConflictError(product)
+		}
+	}
 
-	if err := s.repo.Delete(ctx, id); err != nil {
+	if err := s.repo.Delete(ctx, id, version); err != nil {
+		if errors.Is(err, model.ErrVersionConflict) {
+			return s.currentVersionConflict(ctx, id)
+		}
 		slog.Error("Failed to delete product", "product_id", id, "error", err)
 		return err
 	}
@@ -146,3 +337,125 @@ func (s *productService) Delete(ctx context.Context, id uint) error {
 	slog.Info("Product deleted successfully", "product_id", id)
 	return nil
 }
+
+// changeStock menyesuaikan stok produk ke target quantity melalui ledger inventory
+func (s *productService) changeStock(ctx context.Context, product *model.Product, target int, reason, reference string) error {
+	delta := target - product.Quantity
+	if delta == 0 {
+		return nil
+	}
+
+	movement, err := s.inventory.AdjustStock(ctx, product.ID, web.UserIDFromContext(ctx), &dto.StockAdjustmentRequest{
+		Delta:     delta,
+		Reason:    reason,
+		Reference: reference,
+	})
+	if err != nil {
+		slog.Error("Failed to record stock change", "product_id", product.ID, "delta", delta, "error", err)
+		return err
+	}
+
+	product.Quantity = movement.QuantityAfter
+	product.Version = movement.ProductVersion
+	return nil
+}
+
+// versionConflictError membuat error 412 dengan representasi produk terbaru
+func versionConflictError(current *model.Product) error {
+	slog.Warn("Product version conflict", "product_id", current.ID, "current_version", current.Version)
+	return web.NewHTTPError(http.StatusPreconditionFailed, "Product has been modified by another request", web.ErrPreconditionFailed).
+		WithData(toProductResponse(current))
+}
+
+// currentVersionConflict membaca ulang produk setelah update bersyarat gagal karena versi berubah
+func (s *productService) currentVersionConflict(ctx context.Context, id uint) error {
+	current, err := s.repo.GetProductsByID(ctx, id)
+	if err != nil {
+		return web.NewHTTPError(http.StatusPreconditionFailed, "Product has been modified by another request", web.ErrPreconditionFailed)
+	}
+	return versionConflictError(current)
+}
+
+// toProductResponse memetakan model product ke response API
+func toProductResponse(product *model.Product) *dto.ProductResponse {
+	resp := &dto.ProductResponse{
+		ID:           product.ID,
+		Slug:         product.Slug,
+		Name:         product.Name,
+		Description:  product.Description,
+		Quantity:     product.Quantity,
+		Price:        product.EffectivePrice(),
+		RegularPrice: product.RegularPrice(),
+		Color:        product.Color,
+		Size:         product.Size,
+		Type:         productType(product.Type),
+
+		Version:      product.Version,
+		ReorderPoint: product.ReorderPoint,
+		LowStock:     product.ReorderPoint != nil && product.Quantity <= *product.ReorderPoint,
+		MaxPerOrder:  product.MaxPerOrder,
+		Category:     product.Category,
+		TaxClass:     product.TaxClass,
+		Attributes:   attributesOrEmpty(product.Attributes),
+
+		WeightGrams: product.WeightGrams,
+		LengthMM:    product.LengthMM,
+		WidthMM:     product.WidthMM,
+		HeightMM:    product.HeightMM,
+
+		AverageRating: product.RatingAverage,
+		ReviewCount:   product.ReviewCount,
+	}
+	if product.SKU != nil {
+		resp.SKU = *product.SKU
+	}
+	if product.IsBundle() {
+		resp.Quantity = product.BundleStock()
+		resp.LowStock = false
+		if product.Components != nil {
+			resp.Components = toBundleComponentResponses(product.Components)
+		}
+	}
+	if product.SalePrice != nil {
+		compareAt := product.RegularPrice()
+		if product.CompareAtPrice != nil {
+			compareAt = types.NewMoney(*product.CompareAtPrice, product.Currency)
+		}
+		resp.CompareAtPrice = &compareAt
+		resp.SaleEndsAt = product.SaleEndsAt
+	}
+	return resp
+}
+
+// priceCurrency mengembalikan mata uang harga, atau mata uang default jika kosong
+func priceCurrency(price types.Money) string {
+	if price.Currency == "" {
+		return types.DefaultCurrency()
+	}
+	return price.Currency
+}
+
+// skuPtr mengubah SKU kosong menjadi NULL agar unique index tidak bentrok
+func skuPtr(sku string) *string {
+	sku = strings.TrimSpace(sku)
+	if sku == "" {
+		return nil
+	}
+	return &sku
+}
+
+// attributesOrEmpty memastikan atribut selalu berupa objek JSON, bukan null
+func attributesOrEmpty(attrs types.Attributes) types.Attributes {
+	if attrs == nil {
+		return types.Attributes{}
+	}
+	return attrs
+}
+
+// productType mengembalikan tipe produk, default simple
+func productType(value string) string {
+	if value == "" {
+		return model.TypeSimple
+	}
+	return value
+}
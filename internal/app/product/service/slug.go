@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/product/model"
+	"go-fiber-api/internal/shared/dto"
+	utils "go-fiber-api/utils/helper"
+	"go-fiber-api/utils/web"
+)
+
+// maxSlugAttempts membatasi jumlah suffix yang dicoba saat slug bentrok
+const maxSlugAttempts = 100
+
+// reservedSlugs adalah segmen route GET /v1/products/{id}/... yang lebih spesifik daripada
+// /v1/products/by-slug/{slug}, sehingga slug dengan nilai ini tidak akan pernah sampai ke handler slug.
+// Harus sama dengan daftar di migrasi 000013_product_slugs.
+var reservedSlugs = map[string]bool{
+	"reviews":         true,
+	"recommendations": true,
+	"stock-movements": true,
+}
+
+// uniqueSlug membuat slug dari nama produk. Jika sudah dipakai produk lain atau termasuk reservedSlugs,
+// ditambahkan suffix -2, -3, dst. excludeID adalah produk yang sedang diubah, sehingga slug miliknya sendiri tidak dianggap bentrok.
+func (s *productService) uniqueSlug(ctx context.Context, name string, excludeID uint) (string, error) {
+	base := utils.Slugify(name)
+	if base == "" {
+		base = "product"
+	}
+
+	slug := base
+	for i := 2; i <= maxSlugAttempts; i++ {
+		taken := reservedSlugs[slug]
+		if !taken {
+			var err error
+			if taken, err = s.repo.SlugTaken(ctx, slug, excludeID); err != nil {
+				return "", err
+			}
+		}
+		if !taken {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", web.NewHTTPError(http.StatusConflict, "Could not generate a unique slug for this product name", web.ErrConflict)
+}
+
+// slugConflict memetakan bentrok unique index slug menjadi 409. Bentrok ini terjadi jika produk lain
+// dengan nama yang sama disimpan bersamaan di antara SlugTaken dan insert/update; request bisa diulang.
+func slugConflict(err error) error {
+	if database.IsUniqueViolation(err, "products_slug_key") {
+		return web.NewHTTPError(http.StatusConflict, "Another product with the same slug was saved at the same time; please retry", web.ErrConflict)
+	}
+	return err
+}
+
+// renameSlug menghitung slug baru saat nama produk berubah. Mengembalikan slug lama
+// yang perlu disimpan sebagai redirect, atau string kosong jika slug tidak berubah.
+func (s *productService) renameSlug(ctx context.Context, product *model.Product, newName string) (string, error) {
+	slug, err := s.uniqueSlug(ctx, newName, product.ID)
+	if err != nil {
+		return "", err
+	}
+	if slug == product.Slug {
+		return "", nil
+	}
+
+	oldSlug := product.Slug
+	product.Slug = slug
+	return oldSlug, nil
+}
+
+// recordSlugRedirect menyimpan slug lama agar URL lama tetap bisa diakses
+func (s *productService) recordSlugRedirect(ctx context.Context, product *model.Product, oldSlug string) error {
+	if oldSlug == "" {
+		return nil
+	}
+	if err := s.repo.RecordSlugRedirect(ctx, product.ID, oldSlug, product.Slug); err != nil {
+		slog.Error("Failed to record slug redirect", "product_id", product.ID, "old_slug", oldSlug, "error", err)
+		return err
+	}
+	slog.Info("Product slug changed", "product_id", product.ID, "old_slug", oldSlug, "new_slug", product.Slug)
+	return nil
+}
+
+// GetProductBySlug mencari produk berdasarkan slug. Jika slug adalah slug lama,
+// produk bernilai nil dan slug aktif dikembalikan untuk redirect.
+func (s *productService) GetProductBySlug(ctx context.Context, slug string) (*dto.ProductResponse, string, error) {
+	product, err := s.repo.GetProductBySlug(ctx, slug)
+	if err == nil {
+		if err := s.loadComponents(ctx, product); err != nil {
+			return nil, "", err
+		}
+		return toProductResponse(product), "", nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		slog.Error("Failed to fetch product by slug", "slug", slug, "error", err)
+		return nil, "", err
+	}
+
+	productID, err := s.repo.FindSlugRedirect(ctx, slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	current, err := s.repo.GetProductsByID(ctx, productID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, "", web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	slog.Info("Redirecting old product slug", "slug", slug, "current_slug", current.Slug)
+	return nil, current.Slug, nil
+}
@@ -0,0 +1,38 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"go-fiber-api/internal/app/recommendation/service"
+	"go-fiber-api/utils/web"
+)
+
+type recommendation struct {
+	recommendationService service.Recommendation
+}
+
+func NewRecommendationController(mux *http.ServeMux, recommendationService service.Recommendation) {
+	rc := &recommendation{recommendationService: recommendationService}
+
+	mux.HandleFunc("GET /v1/products/{id}/recommendations", rc.GetRecommendations)
+}
+
+func (rc *recommendation) GetRecommendations(w http.ResponseWriter, r *http.Request) {
+	productID, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	recommendations, err := rc.recommendationService.GetRecommendations(r.Context(), productID, limit)
+	if err != nil {
+		slog.Error("GetRecommendations failed", "product_id", productID, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, recommendations)
+}
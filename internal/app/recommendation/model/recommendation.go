@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Recommendation adalah produk yang sering berada di cart yang sama dengan produk sumber
+type Recommendation struct {
+	ProductID     uint      `db:"product_id" json:"-"`
+	RecommendedID uint      `db:"recommended_product_id" json:"product_id"`
+	Slug          string    `db:"slug" json:"slug"`
+	Name          string    `db:"name" json:"name"`
+	Price         int64     `db:"price" json:"-"` // Harga berlaku (sale jika ada) dalam minor unit
+	Currency      string    `db:"currency" json:"-"`
+	Quantity      int       `db:"quantity" json:"-"`
+	PairCount     int       `db:"pair_count" json:"pair_count"` // Jumlah user yang punya kedua produk di cart
+	Score         float64   `db:"score" json:"score"`           // pair_count dibagi jumlah user yang punya produk sumber
+	ComputedAt    time.Time `db:"computed_at" json:"computed_at"`
+}
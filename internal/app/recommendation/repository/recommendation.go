@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/internal/app/recommendation/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Recommendation interface {
+	Recompute(ctx context.Context, minPairCount int) (int64, error)
+	FindByProduct(ctx context.Context, productID uint, limit int) ([]model.Recommendation, error)
+}
+
+type recommendationRepo struct {
+	db *sqlx.DB
+}
+
+func NewRecommendationRepository(db *sqlx.DB) Recommendation {
+	return &recommendationRepo{db: db}
+}
+
+// Recompute menghitung ulang seluruh skor co-occurrence dalam satu transaksi,
+// sehingga pembaca tidak pernah melihat tabel yang setengah terisi.
+// Pasangan dihitung per user: dua produk dianggap muncul bersama jika pernah ada di cart user yang sama.
+func (r *recommendationRepo) Recompute(ctx context.Context, minPairCount int) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_recommendations`); err != nil {
+		slog.Error("Failed to clear recommendations", "error", err)
+		return 0, err
+	}
+
+	query := `
+		WITH user_products AS (
+			SELECT DISTINCT ci.user_id, ci.product_id
+			FROM cart_items ci
+			JOIN products p ON p.id = ci.product_id
+		),
+		product_users AS (
+			SELECT product_id, COUNT(*) AS users
+			FROM user_products
+			GROUP BY product_id
+		)
+		INSERT INTO product_recommendations (product_id, recommended_product_id, pair_count, score)
+		SELECT a.product_id, b.product_id, COUNT(*) AS pair_count,
+			COUNT(*)::DOUBLE PRECISION / pu.users AS score
+		FROM user_products a
+		JOIN user_products b ON b.user_id = a.user_id AND b.product_id <> a.product_id
+		JOIN product_users pu ON pu.product_id = a.product_id
+		GROUP BY a.product_id, b.product_id, pu.users
+		HAVING COUNT(*) >= $1
+	`
+
+	slog.Info("Executing query Recompute recommendations", "query", query, "min_pair_count", minPairCount)
+	result, err := tx.ExecContext(ctx, query, minPairCount)
+	if err != nil {
+		slog.Error("Failed to compute recommendations", "error", err)
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return count, tx.Commit()
+}
+
+// FindByProduct mengembalikan rekomendasi dengan skor tertinggi, tanpa produk yang
+// sudah dihapus atau stoknya habis
+func (r *recommendationRepo) FindByProduct(ctx context.Context, productID uint, limit int) ([]model.Recommendation, error) {
+	var recommendations []model.Recommendation
+	query := `
+		SELECT pr.product_id, pr.recommended_product_id, pr.pair_count, pr.score, pr.computed_at,
+			p.slug, p.name, COALESCE(sp.price, p.price) AS price, p.currency, st.quantity
+		FROM product_recommendations pr
+		JOIN products p ON p.id = pr.recommended_product_id
+		LEFT JOIN LATERAL (
+			SELECT pp.price
+			FROM product_prices pp
+			WHERE pp.product_id = p.id AND pp.kind = 'sale'
+			  AND pp.valid_from <= NOW() AND (pp.valid_to IS NULL OR pp.valid_to > NOW())
+			ORDER BY pp.valid_from DESC
+			LIMIT 1
+		) sp ON TRUE
+		CROSS JOIN LATERAL (
+			-- Stok bundle diturunkan dari komponennya
+			SELECT CASE WHEN p.type = 'bundle' THEN (
+				SELECT COALESCE(MIN(COALESCE(c.quantity, 0) / bi.quantity), 0)
+				FROM product_bundle_items bi
+				JOIN products c ON c.id = bi.component_id
+				WHERE bi.bundle_id = p.id
+			) ELSE COALESCE(p.quantity, 0) END AS quantity
+		) st
+		WHERE pr.product_id = $1 AND p.deleted_at IS NULL AND st.quantity > 0
+		ORDER BY pr.score DESC, pr.pair_count DESC, p.id
+		LIMIT $2
+	`
+
+	slog.Info("Executing query FindByProduct recommendations", "query", query, "product_id", productID)
+	if err := r.db.SelectContext(ctx, &recommendations, query, productID, limit); err != nil {
+		slog.Error("Failed to get recommendations", "product_id", productID, "error", err)
+		return nil, err
+	}
+	return recommendations, nil
+}
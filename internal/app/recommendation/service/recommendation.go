@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	productRepo "go-fiber-api/internal/app/product/repository"
+	recommendationRepo "go-fiber-api/internal/app/recommendation/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+)
+
+// maxRecommendations membatasi jumlah rekomendasi per request
+const maxRecommendations = 20
+
+type Recommendation interface {
+	Recompute(ctx context.Context) (int64, error)
+	GetRecommendations(ctx context.Context, productID uint, limit int) ([]dto.RecommendationResponse, error)
+	RunScheduler(ctx context.Context, interval time.Duration)
+}
+
+type recommendationService struct {
+	repo         recommendationRepo.Recommendation
+	productRepo  productRepo.Product
+	minPairCount int
+}
+
+// NewRecommendationService membuat service rekomendasi. minPairCount adalah jumlah minimum user
+// yang harus memiliki kedua produk di cart agar pasangan tersebut direkomendasikan.
+func NewRecommendationService(repo recommendationRepo.Recommendation, productRepo productRepo.Product, minPairCount int) Recommendation {
+	if minPairCount < 1 {
+		minPairCount = 1
+	}
+	return &recommendationService{
+		repo:         repo,
+		productRepo:  productRepo,
+		minPairCount: minPairCount,
+	}
+}
+
+// Recompute menghitung ulang seluruh skor co-occurrence
+func (s *recommendationService) Recompute(ctx context.Context) (int64, error) {
+	started := time.Now()
+	count, err := s.repo.Recompute(ctx, s.minPairCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to compute recommendations: %w", err)
+	}
+
+	slog.Info("Recommendations recomputed", "pairs", count, "duration", time.Since(started))
+	return count, nil
+}
+
+// GetRecommendations mengembalikan produk yang sering dibeli bersama produk ini
+func (s *recommendationService) GetRecommendations(ctx context.Context, productID uint, limit int) ([]dto.RecommendationResponse, error) {
+	if _, err := s.productRepo.GetProductsByID(ctx, productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+		}
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxRecommendations {
+		limit = maxRecommendations
+	}
+
+	recommendations, err := s.repo.FindByProduct(ctx, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.RecommendationResponse, 0, len(recommendations))
+	for _, rec := range recommendations {
+		result = append(result, dto.RecommendationResponse{
+			ProductID: rec.RecommendedID,
+			Slug:      rec.Slug,
+			Name:      rec.Name,
+			Price:     types.NewMoney(rec.Price, rec.Currency),
+			PairCount: rec.PairCount,
+			Score:     rec.Score,
+		})
+	}
+	return result, nil
+}
+
+// RunScheduler menghitung ulang rekomendasi saat start dan setiap interval sampai ctx selesai
+func (s *recommendationService) RunScheduler(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	slog.Info("Recommendation scheduler started", "interval", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Recompute(ctx); err != nil {
+			slog.Error("Scheduled recommendation recompute failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("Recommendation scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
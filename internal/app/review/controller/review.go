@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/review/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type review struct {
+	reviewService service.Review
+}
+
+func NewReviewController(mux *http.ServeMux, reviewService service.Review) {
+	rv := &review{reviewService: reviewService}
+
+	mux.HandleFunc("GET /v1/products/{id}/reviews", rv.GetByProduct)
+	mux.Handle("POST /v1/products/{id}/reviews", middleware.AuthMiddleware(http.HandlerFunc(rv.Create)))
+	mux.HandleFunc("GET /v1/admin/reviews", middleware.ValidateRole(types.RoleAdmin)(rv.GetByStatus))
+	mux.HandleFunc("PATCH /v1/admin/reviews/{id}", middleware.ValidateRole(types.RoleAdmin)(rv.Moderate))
+}
+
+func (rv *review) GetByProduct(w http.ResponseWriter, r *http.Request) {
+	productID, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	params := web.NewPaginationParams(r)
+	reviews, total, err := rv.reviewService.GetByProduct(r.Context(), productID, params.PageSize, params.CalculateOffset())
+	if err != nil {
+		slog.Error("GetByProduct reviews failed", "product_id", productID, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, reviews, params.GetPaginationResponse(r, total))
+}
+
+func (rv *review) Create(w http.ResponseWriter, r *http.Request) {
+	userID := web.GetUserID(r)
+	if userID == 0 {
+		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
+		return
+	}
+
+	productID, err := web.PathID(r, "id", "product")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.ReviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Warn("Failed to decode review request", "user_id", userID, "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	created, err := rv.reviewService.Create(r.Context(), userID, productID, &req)
+	if err != nil {
+		slog.Error("Create review failed", "product_id", productID, "user_id", userID, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusCreated, created)
+}
+
+func (rv *review) GetByStatus(w http.ResponseWriter, r *http.Request) {
+	params := web.NewPaginationParams(r)
+	reviews, total, err := rv.reviewService.GetByStatus(r.Context(), r.URL.Query().Get("status"), params.PageSize, params.CalculateOffset())
+	if err != nil {
+		slog.Error("GetByStatus reviews failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	web.OK(w, http.StatusOK, reviews, params.GetPaginationResponse(r, total))
+}
+
+func (rv *review) Moderate(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "review")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.ReviewModerationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	moderated, err := rv.reviewService.Moderate(r.Context(), id, &req)
+	if err != nil {
+		slog.Error("Moderate review failed", "review_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+
+	slog.Info("Moderate review success", "review_id", id, "status", moderated.Status)
+	web.OK(w, http.StatusOK, moderated)
+}
@@ -0,0 +1,25 @@
+package model
+
+import "time"
+
+// Status moderasi review. Hanya review approved yang tampil dan dihitung di agregat rating.
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+type Review struct {
+	ID             uint       `db:"id" json:"id"`
+	ProductID      uint       `db:"product_id" json:"product_id"`
+	UserID         uint       `db:"user_id" json:"user_id"`
+	AuthorName     string     `db:"author_name" json:"author_name"` // Hasil join ke users.username
+	Rating         int        `db:"rating" json:"rating"`
+	Title          string     `db:"title" json:"title"`
+	Body           string     `db:"body" json:"body"`
+	Status         string     `db:"status" json:"status"`
+	ModerationNote *string    `db:"moderation_note" json:"moderation_note,omitempty"`
+	ModeratedAt    *time.Time `db:"moderated_at" json:"moderated_at,omitempty"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/internal/app/review/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Review interface {
+	Create(ctx context.Context, review *model.Review) error
+	FindByID(ctx context.Context, id uint) (*model.Review, error)
+	FindByProductAndUser(ctx context.Context, productID, userID uint) (*model.Review, error)
+	FindByProduct(ctx context.Context, productID uint, status string, limit, offset int) ([]model.Review, int64, error)
+	FindByStatus(ctx context.Context, status string, limit, offset int) ([]model.Review, int64, error)
+	UpdateStatus(ctx context.Context, id uint, status string, note *string) error
+}
+
+type reviewRepo struct {
+	db *sqlx.DB
+}
+
+func NewReviewRepository(db *sqlx.DB) Review {
+	return &reviewRepo{db: db}
+}
+
+// selectReviews menyertakan username penulis review
+const selectReviews = `
+	SELECT r.*, COALESCE(u.username, '') AS author_name
+	FROM product_reviews r
+	LEFT JOIN users u ON u.id = r.user_id`
+
+// refreshProductRating menghitung ulang agregat rating produk dari review yang approved
+const refreshProductRating = `
+	UPDATE products p
+	SET rating_average = COALESCE(agg.average, 0), review_count = agg.total
+	FROM (
+		SELECT ROUND(AVG(rating)::numeric, 2) AS average, COUNT(*) AS total
+		FROM product_reviews
+		WHERE product_id = $1 AND status = 'approved'
+	) agg
+	WHERE p.id = $1`
+
+func (r *reviewRepo) Create(ctx context.Context, review *model.Review) error {
+	query := `
+		INSERT INTO product_reviews (product_id, user_id, rating, title, body, status)
+		VALUES (:product_id, :user_id, :rating, :title, :body, :status)
+		RETURNING id, created_at, updated_at
+	`
+
+	slog.Info("Executing query Create review", "query", query, "product_id", review.ProductID, "user_id", review.UserID)
+	rows, err := r.db.NamedQueryContext(ctx, query, review)
+	if err != nil {
+		slog.Error("Failed to create review", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&review.ID, &review.CreatedAt, &review.UpdatedAt)
+	}
+	return nil
+}
+
+func (r *reviewRepo) FindByID(ctx context.Context, id uint) (*model.Review, error) {
+	var review model.Review
+	query := selectReviews + ` WHERE r.id = $1`
+
+	if err := r.db.GetContext(ctx, &review, query, id); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepo) FindByProductAndUser(ctx context.Context, productID, userID uint) (*model.Review, error) {
+	var review model.Review
+	query := selectReviews + ` WHERE r.product_id = $1 AND r.user_id = $2`
+
+	if err := r.db.GetContext(ctx, &review, query, productID, userID); err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *reviewRepo) FindByProduct(ctx context.Context, productID uint, status string, limit, offset int) ([]model.Review, int64, error) {
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM product_reviews WHERE product_id = $1 AND status = $2`
+	if err := r.db.GetContext(ctx, &total, countQuery, productID, status); err != nil {
+		slog.Error("Failed to count reviews", "product_id", productID, "error", err)
+		return nil, 0, err
+	}
+
+	var reviews []model.Review
+	query := selectReviews + ` WHERE r.product_id = $1 AND r.status = $2 ORDER BY r.created_at DESC LIMIT $3 OFFSET $4`
+
+	slog.Info("Executing query FindByProduct reviews", "query", query, "product_id", productID)
+	if err := r.db.SelectContext(ctx, &reviews, query, productID, status, limit, offset); err != nil {
+		slog.Error("Failed to get reviews", "product_id", productID, "error", err)
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+func (r *reviewRepo) FindByStatus(ctx context.Context, status string, limit, offset int) ([]model.Review, int64, error) {
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM product_reviews WHERE status = $1`
+	if err := r.db.GetContext(ctx, &total, countQuery, status); err != nil {
+		slog.Error("Failed to count reviews", "status", status, "error", err)
+		return nil, 0, err
+	}
+
+	var reviews []model.Review
+	query := selectReviews + ` WHERE r.status = $1 ORDER BY r.created_at LIMIT $2 OFFSET $3`
+
+	slog.Info("Executing query FindByStatus reviews", "query", query, "status", status)
+	if err := r.db.SelectContext(ctx, &reviews, query, status, limit, offset); err != nil {
+		slog.Error("Failed to get reviews", "status", status, "error", err)
+		return nil, 0, err
+	}
+	return reviews, total, nil
+}
+
+// UpdateStatus mengubah status moderasi dan menghitung ulang agregat rating produk dalam satu transaksi
+func (r *reviewRepo) UpdateStatus(ctx context.Context, id uint, status string, note *string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productID uint
+	query := `
+		UPDATE product_reviews
+		SET status = $2, moderation_note = $3, moderated_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+		RETURNING product_id
+	`
+
+	slog.Info("Executing query UpdateStatus review", "query", query, "id", id, "status", status)
+	if err := tx.GetContext(ctx, &productID, query, id, status, note); err != nil {
+		slog.Error("Failed to update review status", "id", id, "error", err)
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, refreshProductRating, productID); err != nil {
+		slog.Error("Failed to refresh product rating", "product_id", productID, "error", err)
+		return err
+	}
+
+	return tx.Commit()
+}
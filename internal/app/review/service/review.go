@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/database"
+	productRepo "go-fiber-api/internal/app/product/repository"
+	"go-fiber-api/internal/app/review/model"
+	reviewRepo "go-fiber-api/internal/app/review/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/utils/web"
+)
+
+type Review interface {
+	Create(ctx context.Context, userID, productID uint, req *dto.ReviewRequest) (*model.Review, error)
+	GetByProduct(ctx context.Context, productID uint, limit, offset int) ([]model.Review, int64, error)
+	GetByStatus(ctx context.Context, status string, limit, offset int) ([]model.Review, int64, error)
+	Moderate(ctx context.Context, id uint, req *dto.ReviewModerationRequest) (*model.Review, error)
+}
+
+type reviewService struct {
+	repo        reviewRepo.Review
+	productRepo productRepo.Product
+}
+
+func NewReviewService(repo reviewRepo.Review, productRepo productRepo.Product) Review {
+	return &reviewService{
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+// Create menyimpan review baru dengan status pending. Satu user hanya boleh satu review per produk.
+func (s *reviewService) Create(ctx context.Context, userID, productID uint, req *dto.ReviewRequest) (*model.Review, error) {
+	if _, err := s.productRepo.GetProductsByID(ctx, productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+		}
+		return nil, err
+	}
+
+	existing, err := s.repo.FindByProductAndUser(ctx, productID, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, alreadyReviewed()
+	}
+
+	review := &model.Review{
+		ProductID: productID,
+		UserID:    userID,
+		Rating:    req.Rating,
+		Title:     req.Title,
+		Body:      req.Body,
+		Status:    model.StatusPending,
+	}
+	if err := s.repo.Create(ctx, review); err != nil {
+		// Dua request bersamaan bisa lolos pengecekan di atas; unique constraint menjadi penentunya
+		if database.IsUniqueViolation(err, "product_reviews_product_user_key") {
+			return nil, alreadyReviewed()
+		}
+		slog.Error("Failed to create review", "product_id", productID, "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	slog.Info("Review created", "review_id", review.ID, "product_id", productID, "user_id", userID)
+	return review, nil
+}
+
+func alreadyReviewed() error {
+	return web.NewHTTPError(http.StatusConflict, "You have already reviewed this product", web.ErrConflict)
+}
+
+// GetByProduct mengembalikan review approved untuk sebuah produk
+func (s *reviewService) GetByProduct(ctx context.Context, productID uint, limit, offset int) ([]model.Review, int64, error) {
+	reviews, total, err := s.repo.FindByProduct(ctx, productID, model.StatusApproved, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if reviews == nil {
+		reviews = []model.Review{}
+	}
+	return reviews, total, nil
+}
+
+// GetByStatus dipakai admin untuk antrian moderasi
+func (s *reviewService) GetByStatus(ctx context.Context, status string, limit, offset int) ([]model.Review, int64, error) {
+	switch status {
+	case "":
+		status = model.StatusPending
+	case model.StatusPending, model.StatusApproved, model.StatusRejected:
+	default:
+		return nil, 0, web.NewHTTPError(http.StatusBadRequest, "Invalid review status", web.ErrValidation)
+	}
+
+	reviews, total, err := s.repo.FindByStatus(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	if reviews == nil {
+		reviews = []model.Review{}
+	}
+	return reviews, total, nil
+}
+
+// Moderate mengubah status review; agregat rating produk ikut diperbarui di repository
+func (s *reviewService) Moderate(ctx context.Context, id uint, req *dto.ReviewModerationRequest) (*model.Review, error) {
+	var note *string
+	if req.Note != "" {
+		note = &req.Note
+	}
+
+	if err := s.repo.UpdateStatus(ctx, id, req.Status, note); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Review not found", web.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to moderate review: %w", err)
+	}
+
+	review, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Review moderated", "review_id", id, "status", req.Status)
+	return review, nil
+}
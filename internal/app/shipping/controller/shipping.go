@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/shipping/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type shipping struct {
+	shippingService service.Shipping
+}
+
+// NewShippingController mendaftarkan route admin untuk zona, metode dan tarif pengiriman.
+// Quote ongkos kirim untuk cart ada di controller cart (POST /v1/cart/shipping-quote).
+func NewShippingController(mux *http.ServeMux, shippingService service.Shipping) {
+	c := &shipping{shippingService: shippingService}
+
+	mux.HandleFunc("GET /v1/admin/shipping-zones", middleware.ValidateRole(types.RoleAdmin)(c.GetZones))
+	mux.HandleFunc("POST /v1/admin/shipping-zones", middleware.ValidateRole(types.RoleAdmin)(c.CreateZone))
+	mux.HandleFunc("GET /v1/admin/shipping-zones/{id}", middleware.ValidateRole(types.RoleAdmin)(c.GetZone))
+	mux.HandleFunc("PUT /v1/admin/shipping-zones/{id}", middleware.ValidateRole(types.RoleAdmin)(c.UpdateZone))
+	mux.HandleFunc("DELETE /v1/admin/shipping-zones/{id}", middleware.ValidateRole(types.RoleAdmin)(c.DeleteZone))
+	mux.HandleFunc("POST /v1/admin/shipping-zones/{id}/methods", middleware.ValidateRole(types.RoleAdmin)(c.CreateMethod))
+	mux.HandleFunc("PUT /v1/admin/shipping-methods/{id}", middleware.ValidateRole(types.RoleAdmin)(c.UpdateMethod))
+	mux.HandleFunc("DELETE /v1/admin/shipping-methods/{id}", middleware.ValidateRole(types.RoleAdmin)(c.DeleteMethod))
+}
+
+func (c *shipping) GetZones(w http.ResponseWriter, r *http.Request) {
+	zones, err := c.shippingService.GetZones(r.Context())
+	if err != nil {
+		slog.Error("GetShippingZones failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, zones)
+}
+
+func (c *shipping) GetZone(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "shipping zone")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	zone, err := c.shippingService.GetZone(r.Context(), id)
+	if err != nil {
+		slog.Error("GetShippingZone failed", "zone_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, zone)
+}
+
+func (c *shipping) CreateZone(w http.ResponseWriter, r *http.Request) {
+	var req dto.ShippingZoneRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	zone, err := c.shippingService.CreateZone(r.Context(), &req)
+	if err != nil {
+		slog.Error("CreateShippingZone failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusCreated, zone)
+}
+
+func (c *shipping) UpdateZone(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "shipping zone")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.ShippingZoneRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	zone, err := c.shippingService.UpdateZone(r.Context(), id, &req)
+	if err != nil {
+		slog.Error("UpdateShippingZone failed", "zone_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, zone)
+}
+
+func (c *shipping) DeleteZone(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "shipping zone")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := c.shippingService.DeleteZone(r.Context(), id); err != nil {
+		slog.Error("DeleteShippingZone failed", "zone_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OKNoContent(w, http.StatusOK)
+}
+
+func (c *shipping) CreateMethod(w http.ResponseWriter, r *http.Request) {
+	zoneID, err := web.PathID(r, "id", "shipping zone")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.ShippingMethodRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	method, err := c.shippingService.CreateMethod(r.Context(), zoneID, &req)
+	if err != nil {
+		slog.Error("CreateShippingMethod failed", "zone_id", zoneID, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusCreated, method)
+}
+
+func (c *shipping) UpdateMethod(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "shipping method")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.ShippingMethodRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+
+	method, err := c.shippingService.UpdateMethod(r.Context(), id, &req)
+	if err != nil {
+		slog.Error("UpdateShippingMethod failed", "method_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, method)
+}
+
+func (c *shipping) DeleteMethod(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "shipping method")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := c.shippingService.DeleteMethod(r.Context(), id); err != nil {
+		slog.Error("DeleteShippingMethod failed", "method_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OKNoContent(w, http.StatusOK)
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, req any) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		slog.Error("Invalid shipping request body", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return false
+	}
+	if err := web.Validator().Struct(req); err != nil {
+		slog.Error("Shipping request validation failed", "error", err)
+		web.Err(w, err)
+		return false
+	}
+	return true
+}
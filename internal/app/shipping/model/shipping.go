@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+// VolumetricDivisor mengubah volume kemasan (mm³) menjadi berat volumetrik dalam gram,
+// setara 5000 cm³ per kg yang umum dipakai kurir
+const VolumetricDivisor = 5000
+
+// Zone adalah wilayah tujuan pengiriman: satu negara, opsional dibatasi rentang kode pos
+type Zone struct {
+	ID         uint      `db:"id" json:"id"`
+	Name       string    `db:"name" json:"name"`
+	Country    string    `db:"country" json:"country"`
+	PostalFrom string    `db:"postal_from" json:"postal_from"`
+	PostalTo   string    `db:"postal_to" json:"postal_to"`
+	IsActive   bool      `db:"is_active" json:"is_active"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+
+	Methods []Method `db:"-" json:"methods"`
+}
+
+// Method adalah layanan pengiriman di satu zona beserta tabel tarifnya
+type Method struct {
+	ID           uint      `db:"id" json:"id"`
+	ZoneID       uint      `db:"zone_id" json:"zone_id"`
+	Code         string    `db:"code" json:"code"`
+	Name         string    `db:"name" json:"name"`
+	Currency     string    `db:"currency" json:"currency"`
+	MinDays      int       `db:"min_days" json:"min_days"`
+	MaxDays      int       `db:"max_days" json:"max_days"`
+	FreeShipping bool      `db:"free_shipping" json:"free_shipping"` // Gratis jika cart mencapai ambang free shipping
+	IsActive     bool      `db:"is_active" json:"is_active"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at" json:"updated_at"`
+
+	Rates []Rate `db:"-" json:"rates"` // Urut dari band teringan
+}
+
+// Rate adalah tarif untuk paket dengan berat sampai MaxWeightGrams
+type Rate struct {
+	MethodID       uint  `db:"method_id" json:"-"`
+	MaxWeightGrams int   `db:"max_weight_grams" json:"max_weight_grams"`
+	Price          int64 `db:"price" json:"price"`
+}
+
+// RateFor mengembalikan band teringan yang memuat berat paket; false jika paket melebihi band terakhir
+func (m *Method) RateFor(weightGrams int) (Rate, bool) {
+	for _, rate := range m.Rates {
+		if weightGrams <= rate.MaxWeightGrams {
+			return rate, true
+		}
+	}
+	return Rate{}, false
+}
+
+// Destination adalah alamat tujuan yang menentukan zona
+type Destination struct {
+	Country    string
+	PostalCode string
+}
+
+// Parcel adalah isi cart yang dikirim. Value dipakai untuk ambang free shipping.
+type Parcel struct {
+	WeightGrams int
+	Value       types.Money
+}
+
+// Quote adalah harga satu metode pengiriman untuk sebuah paket
+type Quote struct {
+	Code    string
+	Name    string
+	Price   types.Money
+	Free    bool // Gratis karena ambang free shipping tercapai
+	MinDays int
+	MaxDays int
+}
+
+// FreeShippingProgress adalah sisa belanja sampai ambang free shipping tercapai
+type FreeShippingProgress struct {
+	Threshold types.Money
+	Remaining types.Money
+	Qualified bool
+}
+
+// ChargeableWeight mengembalikan berat yang ditagih untuk satu unit: berat aktual atau berat
+// volumetrik jika kemasannya lebih besar. Nilai yang belum diisi dihitung 0.
+func ChargeableWeight(weightGrams, lengthMM, widthMM, heightMM *int) int {
+	weight := 0
+	if weightGrams != nil {
+		weight = *weightGrams
+	}
+	if lengthMM != nil && widthMM != nil && heightMM != nil {
+		volumetric := int((int64(*lengthMM)*int64(*widthMM)*int64(*heightMM) + VolumetricDivisor - 1) / VolumetricDivisor)
+		weight = max(weight, volumetric)
+	}
+	return weight
+}
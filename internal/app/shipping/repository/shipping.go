@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/shipping/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Shipping interface {
+	GetZones(ctx context.Context) ([]model.Zone, error)
+	GetZone(ctx context.Context, id uint) (*model.Zone, error)
+	CreateZone(ctx context.Context, zone *model.Zone) error
+	UpdateZone(ctx context.Context, zone *model.Zone) error
+	DeleteZone(ctx context.Context, id uint) error
+	FindZone(ctx context.Context, country, postalCode string) (*model.Zone, error)
+
+	GetMethods(ctx context.Context, zoneIDs []uint) ([]model.Method, error)
+	GetMethod(ctx context.Context, id uint) (*model.Method, error)
+	CreateMethod(ctx context.Context, method *model.Method) error
+	UpdateMethod(ctx context.Context, method *model.Method) error
+	DeleteMethod(ctx context.Context, id uint) error
+	GetRates(ctx context.Context, methodIDs []uint) ([]model.Rate, error)
+	ReplaceRates(ctx context.Context, methodID uint, rates []model.Rate) error
+}
+
+type shippingRepo struct {
+	db *sqlx.DB
+}
+
+func NewShippingRepository(db *sqlx.DB) Shipping {
+	return &shippingRepo{db: db}
+}
+
+func (r *shippingRepo) GetZones(ctx context.Context) ([]model.Zone, error) {
+	var zones []model.Zone
+	query := `SELECT * FROM shipping_zones ORDER BY country, postal_from, id`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &zones, query); err != nil {
+		slog.Error("Failed to get shipping zones", "error", err)
+		return nil, err
+	}
+	return zones, nil
+}
+
+func (r *shippingRepo) GetZone(ctx context.Context, id uint) (*model.Zone, error) {
+	var zone model.Zone
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &zone, `SELECT * FROM shipping_zones WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *shippingRepo) CreateZone(ctx context.Context, zone *model.Zone) error {
+	query := `
+		INSERT INTO shipping_zones (name, country, postal_from, postal_to, is_active)
+		VALUES (:name, :country, :postal_from, :postal_to, :is_active)
+		RETURNING id, created_at, updated_at
+	`
+
+	slog.Info("Executing query CreateShippingZone", "query", query, "country", zone.Country)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, zone)
+	if err != nil {
+		slog.Error("Failed to create shipping zone", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&zone.ID, &zone.CreatedAt, &zone.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+func (r *shippingRepo) UpdateZone(ctx context.Context, zone *model.Zone) error {
+	query := `
+		UPDATE shipping_zones
+		SET name = :name, country = :country, postal_from = :postal_from, postal_to = :postal_to,
+			is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+		RETURNING updated_at
+	`
+
+	slog.Info("Executing query UpdateShippingZone", "query", query, "zone_id", zone.ID)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, zone)
+	if err != nil {
+		slog.Error("Failed to update shipping zone", "zone_id", zone.ID, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&zone.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+func (r *shippingRepo) DeleteZone(ctx context.Context, id uint) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM shipping_zones WHERE id = $1`, id)
+	return err
+}
+
+// FindZone mencari zona aktif untuk alamat tujuan. Zona dengan rentang kode pos yang cocok
+// didahulukan dari zona seluruh negara; kode pos dibandingkan sebagai teks dengan panjang sama.
+func (r *shippingRepo) FindZone(ctx context.Context, country, postalCode string) (*model.Zone, error) {
+	var zone model.Zone
+	query := `
+		SELECT * FROM shipping_zones
+		WHERE is_active AND country = $1
+		  AND (postal_from = '' OR (length(postal_from) = length($2) AND $2 BETWEEN postal_from AND postal_to))
+		ORDER BY postal_from = '', id
+		LIMIT 1
+	`
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &zone, query, country, postalCode); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func (r *shippingRepo) GetMethods(ctx context.Context, zoneIDs []uint) ([]model.Method, error) {
+	var methods []model.Method
+	query := `SELECT * FROM shipping_methods WHERE zone_id = ANY($1) ORDER BY zone_id, min_days, id`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &methods, query, uintArray(zoneIDs)); err != nil {
+		slog.Error("Failed to get shipping methods", "error", err)
+		return nil, err
+	}
+	return methods, nil
+}
+
+func (r *shippingRepo) GetMethod(ctx context.Context, id uint) (*model.Method, error) {
+	var method model.Method
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &method, `SELECT * FROM shipping_methods WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &method, nil
+}
+
+func (r *shippingRepo) CreateMethod(ctx context.Context, method *model.Method) error {
+	query := `
+		INSERT INTO shipping_methods (zone_id, code, name, currency, min_days, max_days, free_shipping, is_active)
+		VALUES (:zone_id, :code, :name, :currency, :min_days, :max_days, :free_shipping, :is_active)
+		RETURNING id, created_at, updated_at
+	`
+
+	slog.Info("Executing query CreateShippingMethod", "query", query, "zone_id", method.ZoneID, "code", method.Code)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, method)
+	if err != nil {
+		slog.Error("Failed to create shipping method", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&method.ID, &method.CreatedAt, &method.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+func (r *shippingRepo) UpdateMethod(ctx context.Context, method *model.Method) error {
+	query := `
+		UPDATE shipping_methods
+		SET code = :code, name = :name, currency = :currency, min_days = :min_days, max_days = :max_days,
+			free_shipping = :free_shipping, is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+		RETURNING updated_at
+	`
+
+	slog.Info("Executing query UpdateShippingMethod", "query", query, "method_id", method.ID)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, method)
+	if err != nil {
+		slog.Error("Failed to update shipping method", "method_id", method.ID, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&method.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+func (r *shippingRepo) DeleteMethod(ctx context.Context, id uint) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM shipping_methods WHERE id = $1`, id)
+	return err
+}
+
+func (r *shippingRepo) GetRates(ctx context.Context, methodIDs []uint) ([]model.Rate, error) {
+	var rates []model.Rate
+	query := `SELECT * FROM shipping_rates WHERE method_id = ANY($1) ORDER BY method_id, max_weight_grams`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &rates, query, uintArray(methodIDs)); err != nil {
+		slog.Error("Failed to get shipping rates", "error", err)
+		return nil, err
+	}
+	return rates, nil
+}
+
+// ReplaceRates mengganti seluruh tabel tarif satu metode. Dipanggil di dalam transaksi.
+func (r *shippingRepo) ReplaceRates(ctx context.Context, methodID uint, rates []model.Rate) error {
+	conn := database.Conn(ctx, r.db)
+	if _, err := conn.ExecContext(ctx, `DELETE FROM shipping_rates WHERE method_id = $1`, methodID); err != nil {
+		return err
+	}
+	for _, rate := range rates {
+		query := `INSERT INTO shipping_rates (method_id, max_weight_grams, price) VALUES ($1, $2, $3)`
+		if _, err := conn.ExecContext(ctx, query, methodID, rate.MaxWeightGrams, rate.Price); err != nil {
+			slog.Error("Failed to insert shipping rate", "method_id", methodID, "max_weight_grams", rate.MaxWeightGrams, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// uintArray mengubah []uint menjadi parameter array Postgres
+func uintArray(ids []uint) interface{} {
+	values := make([]int64, len(ids))
+	for i, id := range ids {
+		values[i] = int64(id)
+	}
+	return pq.Array(values)
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/shipping/model"
+	"go-fiber-api/internal/app/shipping/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/utils/web"
+)
+
+type Shipping interface {
+	GetZones(ctx context.Context) ([]model.Zone, error)
+	GetZone(ctx context.Context, id uint) (*model.Zone, error)
+	CreateZone(ctx context.Context, req *dto.ShippingZoneRequest) (*model.Zone, error)
+	UpdateZone(ctx context.Context, id uint, req *dto.ShippingZoneRequest) (*model.Zone, error)
+	DeleteZone(ctx context.Context, id uint) error
+	CreateMethod(ctx context.Context, zoneID uint, req *dto.ShippingMethodRequest) (*model.Method, error)
+	UpdateMethod(ctx context.Context, id uint, req *dto.ShippingMethodRequest) (*model.Method, error)
+	DeleteMethod(ctx context.Context, id uint) error
+	Quote(ctx context.Context, destination model.Destination, parcel model.Parcel) (*model.Zone, []model.Quote, error)
+	FreeShippingProgress(value types.Money) *model.FreeShippingProgress
+}
+
+type shippingService struct {
+	repo          repository.Shipping
+	tx            database.Transactor
+	freeThreshold types.Money
+}
+
+// NewShippingService membuat service pengiriman. freeThreshold adalah nilai cart minimum untuk
+// free shipping pada metode yang mengizinkannya; amount 0 mematikan free shipping.
+func NewShippingService(repo repository.Shipping, tx database.Transactor, freeThreshold types.Money) Shipping {
+	return &shippingService{repo: repo, tx: tx, freeThreshold: freeThreshold}
+}
+
+// GetZones mengembalikan semua zona beserta metode dan tarifnya
+func (s *shippingService) GetZones(ctx context.Context) ([]model.Zone, error) {
+	zones, err := s.repo.GetZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if zones == nil {
+		zones = []model.Zone{}
+	}
+	if err := s.loadMethods(ctx, zones); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+func (s *shippingService) GetZone(ctx context.Context, id uint) (*model.Zone, error) {
+	zone, err := s.findZone(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	zones := []model.Zone{*zone}
+	if err := s.loadMethods(ctx, zones); err != nil {
+		return nil, err
+	}
+	return &zones[0], nil
+}
+
+func (s *shippingService) CreateZone(ctx context.Context, req *dto.ShippingZoneRequest) (*model.Zone, error) {
+	zone := &model.Zone{IsActive: true}
+	if err := applyZoneRequest(zone, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateZone(ctx, zone); err != nil {
+		slog.Error("Failed to create shipping zone", "country", zone.Country, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Shipping zone created", "zone_id", zone.ID, "country", zone.Country)
+	zone.Methods = []model.Method{}
+	return zone, nil
+}
+
+func (s *shippingService) UpdateZone(ctx context.Context, id uint, req *dto.ShippingZoneRequest) (*model.Zone, error) {
+	zone, err := s.findZone(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyZoneRequest(zone, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateZone(ctx, zone); err != nil {
+		slog.Error("Failed to update shipping zone", "zone_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Shipping zone updated", "zone_id", id)
+	return s.GetZone(ctx, id)
+}
+
+// DeleteZone menghapus zona beserta metode dan tarifnya
+func (s *shippingService) DeleteZone(ctx context.Context, id uint) error {
+	if _, err := s.findZone(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteZone(ctx, id); err != nil {
+		slog.Error("Failed to delete shipping zone", "zone_id", id, "error", err)
+		return err
+	}
+
+	slog.Info("Shipping zone deleted", "zone_id", id)
+	return nil
+}
+
+func (s *shippingService) CreateMethod(ctx context.Context, zoneID uint, req *dto.ShippingMethodRequest) (*model.Method, error) {
+	if _, err := s.findZone(ctx, zoneID); err != nil {
+		return nil, err
+	}
+
+	method := &model.Method{ZoneID: zoneID, IsActive: true}
+	if err := s.applyMethodRequest(ctx, method, req); err != nil {
+		return nil, err
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateMethod(ctx, method); err != nil {
+			return err
+		}
+		return s.repo.ReplaceRates(ctx, method.ID, method.Rates)
+	})
+	if err != nil {
+		slog.Error("Failed to create shipping method", "zone_id", zoneID, "code", method.Code, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Shipping method created", "method_id", method.ID, "zone_id", zoneID, "code", method.Code)
+	return method, nil
+}
+
+func (s *shippingService) UpdateMethod(ctx context.Context, id uint, req *dto.ShippingMethodRequest) (*model.Method, error) {
+	method, err := s.findMethod(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyMethodRequest(ctx, method, req); err != nil {
+		return nil, err
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.UpdateMethod(ctx, method); err != nil {
+			return err
+		}
+		return s.repo.ReplaceRates(ctx, method.ID, method.Rates)
+	})
+	if err != nil {
+		slog.Error("Failed to update shipping method", "method_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Shipping method updated", "method_id", id)
+	return method, nil
+}
+
+func (s *shippingService) DeleteMethod(ctx context.Context, id uint) error {
+	if _, err := s.findMethod(ctx, id); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteMethod(ctx, id); err != nil {
+		slog.Error("Failed to delete shipping method", "method_id", id, "error", err)
+		return err
+	}
+
+	slog.Info("Shipping method deleted", "method_id", id)
+	return nil
+}
+
+// Quote menghitung harga setiap metode aktif di zona tujuan untuk berat paket. Metode yang band
+// tarifnya tidak memuat berat paket dilewati. Metode dengan free_shipping bernilai 0 jika nilai
+// paket mencapai ambang free shipping.
+func (s *shippingService) Quote(ctx context.Context, destination model.Destination, parcel model.Parcel) (*model.Zone, []model.Quote, error) {
+	country := strings.ToUpper(strings.TrimSpace(destination.Country))
+	postalCode := normalizePostalCode(destination.PostalCode)
+
+	zone, err := s.repo.FindZone(ctx, country, postalCode)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, web.NewHTTPError(http.StatusUnprocessableEntity,
+				fmt.Sprintf("Shipping to %s %s is not available", country, postalCode), web.ErrShippingUnavailable)
+		}
+		return nil, nil, err
+	}
+	zones := []model.Zone{*zone}
+	if err := s.loadMethods(ctx, zones); err != nil {
+		return nil, nil, err
+	}
+	zone = &zones[0]
+
+	progress := s.FreeShippingProgress(parcel.Value)
+	quotes := make([]model.Quote, 0, len(zone.Methods))
+	for i := range zone.Methods {
+		method := &zone.Methods[i]
+		if !method.IsActive {
+			continue
+		}
+		rate, ok := method.RateFor(parcel.WeightGrams)
+		if !ok {
+			continue
+		}
+
+		quote := model.Quote{
+			Code:    method.Code,
+			Name:    method.Name,
+			Price:   types.NewMoney(rate.Price, method.Currency),
+			MinDays: method.MinDays,
+			MaxDays: method.MaxDays,
+		}
+		if method.FreeShipping && progress != nil && progress.Qualified {
+			quote.Price.Amount = 0
+			quote.Free = true
+		}
+		quotes = append(quotes, quote)
+	}
+	return zone, quotes, nil
+}
+
+// FreeShippingProgress menghitung sisa belanja sampai ambang free shipping. Nil jika free
+// shipping tidak aktif atau nilai cart memakai mata uang lain dari ambangnya.
+func (s *shippingService) FreeShippingProgress(value types.Money) *model.FreeShippingProgress {
+	if s.freeThreshold.Amount <= 0 || value.Currency != s.freeThreshold.Currency {
+		return nil
+	}
+	remaining := types.NewMoney(max(s.freeThreshold.Amount-value.Amount, 0), value.Currency)
+	return &model.FreeShippingProgress{
+		Threshold: s.freeThreshold,
+		Remaining: remaining,
+		Qualified: remaining.Amount == 0,
+	}
+}
+
+func (s *shippingService) findZone(ctx context.Context, id uint) (*model.Zone, error) {
+	zone, err := s.repo.GetZone(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Shipping zone not found", web.ErrNotFound)
+		}
+		return nil, err
+	}
+	return zone, nil
+}
+
+func (s *shippingService) findMethod(ctx context.Context, id uint) (*model.Method, error) {
+	method, err := s.repo.GetMethod(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Shipping method not found", web.ErrNotFound)
+		}
+		return nil, err
+	}
+	return method, nil
+}
+
+// loadMethods mengisi metode dan tarif untuk setiap zona dengan dua query
+func (s *shippingService) loadMethods(ctx context.Context, zones []model.Zone) error {
+	if len(zones) == 0 {
+		return nil
+	}
+	zoneIDs := make([]uint, 0, len(zones))
+	for _, zone := range zones {
+		zoneIDs = append(zoneIDs, zone.ID)
+	}
+
+	methods, err := s.repo.GetMethods(ctx, zoneIDs)
+	if err != nil {
+		return err
+	}
+	methodIDs := make([]uint, 0, len(methods))
+	for _, method := range methods {
+		methodIDs = append(methodIDs, method.ID)
+	}
+	rates, err := s.repo.GetRates(ctx, methodIDs)
+	if err != nil {
+		return err
+	}
+
+	ratesByMethod := make(map[uint][]model.Rate, len(methods))
+	for _, rate := range rates {
+		ratesByMethod[rate.MethodID] = append(ratesByMethod[rate.MethodID], rate)
+	}
+	methodsByZone := make(map[uint][]model.Method, len(zones))
+	for _, method := range methods {
+		method.Rates = ratesByMethod[method.ID]
+		if method.Rates == nil {
+			method.Rates = []model.Rate{}
+		}
+		methodsByZone[method.ZoneID] = append(methodsByZone[method.ZoneID], method)
+	}
+	for i := range zones {
+		zones[i].Methods = methodsByZone[zones[i].ID]
+		if zones[i].Methods == nil {
+			zones[i].Methods = []model.Method{}
+		}
+	}
+	return nil
+}
+
+// normalizePostalCode menyeragamkan kode pos agar perbandingan rentang konsisten
+func normalizePostalCode(code string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), " ", ""))
+}
+
+func applyZoneRequest(zone *model.Zone, req *dto.ShippingZoneRequest) error {
+	postalFrom := normalizePostalCode(req.PostalFrom)
+	postalTo := normalizePostalCode(req.PostalTo)
+	if len(postalFrom) != len(postalTo) || postalFrom > postalTo {
+		return web.NewHTTPError(http.StatusBadRequest,
+			"postal_from and postal_to must have the same length and postal_from must not be after postal_to", web.ErrValidation)
+	}
+
+	zone.Name = req.Name
+	zone.Country = strings.ToUpper(req.Country)
+	zone.PostalFrom = postalFrom
+	zone.PostalTo = postalTo
+	if req.IsActive != nil {
+		zone.IsActive = *req.IsActive
+	}
+	return nil
+}
+
+// applyMethodRequest menyalin request ke metode. Kode metode unik per zona, dan semua tarif harus
+// memakai mata uang yang sama.
+func (s *shippingService) applyMethodRequest(ctx context.Context, method *model.Method, req *dto.ShippingMethodRequest) error {
+	method.Code = strings.ToLower(strings.TrimSpace(req.Code))
+	method.Name = req.Name
+	method.MinDays = req.MinDays
+	method.MaxDays = req.MaxDays
+	method.FreeShipping = req.FreeShipping
+	if req.IsActive != nil {
+		method.IsActive = *req.IsActive
+	}
+
+	method.Currency = req.Rates[0].Price.Currency
+	if _, err := types.CurrencyExponent(method.Currency); err != nil {
+		return web.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Unsupported currency code %q", method.Currency), web.ErrValidation)
+	}
+	method.Rates = make([]model.Rate, 0, len(req.Rates))
+	for _, rate := range req.Rates {
+		if rate.Price.Currency != method.Currency {
+			return web.NewHTTPError(http.StatusBadRequest, "All rates must use the same currency", web.ErrValidation)
+		}
+		method.Rates = append(method.Rates, model.Rate{MethodID: method.ID, MaxWeightGrams: rate.MaxWeightGrams, Price: rate.Price.Amount})
+	}
+	sort.Slice(method.Rates, func(i, j int) bool {
+		return method.Rates[i].MaxWeightGrams < method.Rates[j].MaxWeightGrams
+	})
+	for i := 1; i < len(method.Rates); i++ {
+		if method.Rates[i].MaxWeightGrams == method.Rates[i-1].MaxWeightGrams {
+			return web.NewHTTPError(http.StatusBadRequest,
+				fmt.Sprintf("Duplicate rate band for %d grams", method.Rates[i].MaxWeightGrams), web.ErrValidation)
+		}
+	}
+
+	existing, err := s.repo.GetMethods(ctx, []uint{method.ZoneID})
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != method.ID && other.Code == method.Code {
+			return web.NewHTTPError(http.StatusConflict,
+				fmt.Sprintf("Shipping zone already has a method with code %s", method.Code), web.ErrConflict)
+		}
+	}
+	return nil
+}
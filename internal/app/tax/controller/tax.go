@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/tax/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/internal/shared/types"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type tax struct {
+	taxService service.Tax
+}
+
+func NewTaxController(mux *http.ServeMux, taxService service.Tax) {
+	c := &tax{taxService: taxService}
+
+	mux.HandleFunc("GET /v1/admin/tax-classes", middleware.ValidateRole(types.RoleAdmin)(c.GetClasses))
+	mux.HandleFunc("PUT /v1/admin/tax-classes/{code}", middleware.ValidateRole(types.RoleAdmin)(c.SaveClass))
+	mux.HandleFunc("GET /v1/admin/tax-rules", middleware.ValidateRole(types.RoleAdmin)(c.GetRules))
+	mux.HandleFunc("POST /v1/admin/tax-rules", middleware.ValidateRole(types.RoleAdmin)(c.CreateRule))
+	mux.HandleFunc("PUT /v1/admin/tax-rules/{id}", middleware.ValidateRole(types.RoleAdmin)(c.UpdateRule))
+	mux.HandleFunc("DELETE /v1/admin/tax-rules/{id}", middleware.ValidateRole(types.RoleAdmin)(c.DeleteRule))
+}
+
+func (c *tax) GetClasses(w http.ResponseWriter, r *http.Request) {
+	classes, err := c.taxService.GetClasses(r.Context())
+	if err != nil {
+		slog.Error("GetTaxClasses failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, classes)
+}
+
+func (c *tax) SaveClass(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+
+	var req dto.TaxClassRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		slog.Error("SaveTaxClass failed - invalid JSON", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	class, err := c.taxService.SaveClass(r.Context(), code, &req)
+	if err != nil {
+		slog.Error("SaveTaxClass failed", "code", code, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, class)
+}
+
+// GetRules mengembalikan semua rule pajak, bisa difilter dengan ?country=ID
+func (c *tax) GetRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := c.taxService.GetRules(r.Context(), r.URL.Query().Get("country"))
+	if err != nil {
+		slog.Error("GetTaxRules failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, rules)
+}
+
+func (c *tax) CreateRule(w http.ResponseWriter, r *http.Request) {
+	var req dto.TaxRuleRequest
+	if !decodeRuleRequest(w, r, &req) {
+		return
+	}
+
+	rule, err := c.taxService.CreateRule(r.Context(), &req)
+	if err != nil {
+		slog.Error("CreateTaxRule failed", "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusCreated, rule)
+}
+
+func (c *tax) UpdateRule(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "tax rule")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.TaxRuleRequest
+	if !decodeRuleRequest(w, r, &req) {
+		return
+	}
+
+	rule, err := c.taxService.UpdateRule(r.Context(), id, &req)
+	if err != nil {
+		slog.Error("UpdateTaxRule failed", "rule_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, rule)
+}
+
+func (c *tax) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	id, err := web.PathID(r, "id", "tax rule")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := c.taxService.DeleteRule(r.Context(), id); err != nil {
+		slog.Error("DeleteTaxRule failed", "rule_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OKNoContent(w, http.StatusOK)
+}
+
+func decodeRuleRequest(w http.ResponseWriter, r *http.Request, req *dto.TaxRuleRequest) bool {
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		slog.Error("Invalid tax rule request body", "error", err)
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return false
+	}
+	if err := web.Validator().Struct(req); err != nil {
+		slog.Error("Tax rule request validation failed", "error", err)
+		web.Err(w, err)
+		return false
+	}
+	return true
+}
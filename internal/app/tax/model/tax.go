@@ -0,0 +1,81 @@
+package model
+
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+// DefaultClass adalah kelas pajak produk yang tidak menyebutkan kelasnya
+const DefaultClass = "standard"
+
+// Class adalah kelas pajak produk, mis. standard, reduced atau exempt
+type Class struct {
+	Code      string    `db:"code" json:"code"`
+	Name      string    `db:"name" json:"name"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Rule adalah tarif pajak untuk satu kelas di satu wilayah. Rate dalam persen dan disimpan
+// sebagai NUMERIC, dibaca sebagai string agar presisinya tidak hilang. Region kosong berarti
+// berlaku untuk seluruh negara.
+type Rule struct {
+	ID        uint      `db:"id" json:"id"`
+	TaxClass  string    `db:"tax_class" json:"tax_class"`
+	Country   string    `db:"country" json:"country"`
+	Region    string    `db:"region" json:"region"`
+	Name      string    `db:"name" json:"name"` // Ditampilkan di rincian, mis. PPN atau VAT
+	Rate      string    `db:"rate" json:"rate"`
+	Inclusive bool      `db:"inclusive" json:"inclusive"` // Harga produk sudah termasuk pajak
+	IsActive  bool      `db:"is_active" json:"is_active"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Region adalah wilayah yang menentukan rule pajak: kode negara ISO 3166-1 dan kode wilayah opsional
+type Region struct {
+	Country string
+	Region  string
+}
+
+func (r Region) String() string {
+	if r.Region == "" {
+		return r.Country
+	}
+	return r.Country + "-" + r.Region
+}
+
+// Line adalah satu baris yang dihitung pajaknya. Amount sudah dikurangi diskon.
+type Line struct {
+	ID       uint
+	TaxClass string
+	Amount   types.Money
+}
+
+// LineTax adalah pajak satu baris. Rule nil jika tidak ada rule untuk kelas pajaknya.
+// Net + Tax selalu sama dengan Gross, baik untuk harga inclusive maupun exclusive.
+type LineTax struct {
+	ID    uint
+	Rule  *Rule
+	Net   types.Money
+	Tax   types.Money
+	Gross types.Money
+}
+
+// Breakdown adalah total pajak per jenis (nama, tarif dan inclusive) untuk rincian total cart
+type Breakdown struct {
+	Name      string
+	Rate      string
+	Inclusive bool
+	Taxable   types.Money // Jumlah sebelum pajak
+	Amount    types.Money
+}
+
+// Result adalah hasil perhitungan pajak seluruh baris
+type Result struct {
+	Lines     []LineTax
+	Breakdown []Breakdown
+	Total     types.Money // Seluruh pajak, termasuk yang sudah ada di harga
+	Exclusive types.Money // Pajak yang ditambahkan di atas harga
+}
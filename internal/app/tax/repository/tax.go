@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/tax/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type Tax interface {
+	GetClasses(ctx context.Context) ([]model.Class, error)
+	GetClass(ctx context.Context, code string) (*model.Class, error)
+	UpsertClass(ctx context.Context, class *model.Class) error
+	GetRules(ctx context.Context, country string) ([]model.Rule, error)
+	GetRule(ctx context.Context, id uint) (*model.Rule, error)
+	CreateRule(ctx context.Context, rule *model.Rule) error
+	UpdateRule(ctx context.Context, rule *model.Rule) error
+	DeleteRule(ctx context.Context, id uint) error
+	FindRules(ctx context.Context, country, region string, classes []string) ([]model.Rule, error)
+}
+
+type taxRepo struct {
+	db *sqlx.DB
+}
+
+func NewTaxRepository(db *sqlx.DB) Tax {
+	return &taxRepo{db: db}
+}
+
+func (r *taxRepo) GetClasses(ctx context.Context) ([]model.Class, error) {
+	var classes []model.Class
+	query := `SELECT * FROM tax_classes ORDER BY code`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &classes, query); err != nil {
+		slog.Error("Failed to get tax classes", "error", err)
+		return nil, err
+	}
+	return classes, nil
+}
+
+func (r *taxRepo) GetClass(ctx context.Context, code string) (*model.Class, error) {
+	var class model.Class
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &class, `SELECT * FROM tax_classes WHERE code = $1`, code); err != nil {
+		return nil, err
+	}
+	return &class, nil
+}
+
+func (r *taxRepo) UpsertClass(ctx context.Context, class *model.Class) error {
+	query := `
+		INSERT INTO tax_classes (code, name) VALUES ($1, $2)
+		ON CONFLICT (code) DO UPDATE SET name = EXCLUDED.name, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`
+	slog.Info("Executing query UpsertTaxClass", "query", query, "code", class.Code)
+	return database.Conn(ctx, r.db).QueryRowxContext(ctx, query, class.Code, class.Name).
+		Scan(&class.CreatedAt, &class.UpdatedAt)
+}
+
+// GetRules mengambil semua rule, bisa difilter per negara
+func (r *taxRepo) GetRules(ctx context.Context, country string) ([]model.Rule, error) {
+	var rules []model.Rule
+	query := `
+		SELECT * FROM tax_rules
+		WHERE ($1 = '' OR country = $1)
+		ORDER BY country, region, tax_class
+	`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &rules, query, country); err != nil {
+		slog.Error("Failed to get tax rules", "error", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *taxRepo) GetRule(ctx context.Context, id uint) (*model.Rule, error) {
+	var rule model.Rule
+	if err := database.Conn(ctx, r.db).GetContext(ctx, &rule, `SELECT * FROM tax_rules WHERE id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *taxRepo) CreateRule(ctx context.Context, rule *model.Rule) error {
+	query := `
+		INSERT INTO tax_rules (tax_class, country, region, name, rate, inclusive, is_active)
+		VALUES (:tax_class, :country, :region, :name, :rate, :inclusive, :is_active)
+		RETURNING id, created_at, updated_at
+	`
+
+	slog.Info("Executing query CreateTaxRule", "query", query, "tax_class", rule.TaxClass, "country", rule.Country)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, rule)
+	if err != nil {
+		slog.Error("Failed to create tax rule", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+func (r *taxRepo) UpdateRule(ctx context.Context, rule *model.Rule) error {
+	query := `
+		UPDATE tax_rules
+		SET tax_class = :tax_class, country = :country, region = :region, name = :name, rate = :rate,
+			inclusive = :inclusive, is_active = :is_active, updated_at = NOW()
+		WHERE id = :id
+		RETURNING updated_at
+	`
+
+	slog.Info("Executing query UpdateTaxRule", "query", query, "rule_id", rule.ID)
+	rows, err := sqlx.NamedQueryContext(ctx, database.Conn(ctx, r.db), query, rule)
+	if err != nil {
+		slog.Error("Failed to update tax rule", "rule_id", rule.ID, "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&rule.UpdatedAt)
+	}
+	return rows.Err()
+}
+
+func (r *taxRepo) DeleteRule(ctx context.Context, id uint) error {
+	_, err := database.Conn(ctx, r.db).ExecContext(ctx, `DELETE FROM tax_rules WHERE id = $1`, id)
+	return err
+}
+
+// FindRules mengambil rule aktif untuk kelas-kelas pajak di satu wilayah: rule seluruh negara
+// dan rule khusus region. Rule khusus region diurutkan terakhir agar bisa menimpa rule negara.
+func (r *taxRepo) FindRules(ctx context.Context, country, region string, classes []string) ([]model.Rule, error) {
+	var rules []model.Rule
+	query := `
+		SELECT * FROM tax_rules
+		WHERE is_active AND country = $1 AND region IN ('', $2) AND tax_class = ANY($3)
+		ORDER BY tax_class, region
+	`
+	if err := database.Conn(ctx, r.db).SelectContext(ctx, &rules, query, country, region, pq.Array(classes)); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
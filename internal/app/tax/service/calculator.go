@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"go-fiber-api/internal/app/tax/model"
+	"go-fiber-api/internal/app/tax/repository"
+	"go-fiber-api/internal/shared/types"
+)
+
+// Calculator menghitung pajak untuk baris cart di satu wilayah. Implementasi bawaan memakai
+// tax_rules di database; layanan pajak eksternal bisa dipasang dengan mengimplementasikan
+// interface ini.
+type Calculator interface {
+	Calculate(ctx context.Context, region model.Region, lines []model.Line) (*model.Result, error)
+}
+
+type ruleCalculator struct {
+	repo repository.Tax
+}
+
+// NewRuleCalculator membuat Calculator berbasis tax_rules
+func NewRuleCalculator(repo repository.Tax) Calculator {
+	return &ruleCalculator{repo: repo}
+}
+
+// Calculate menghitung pajak per baris lalu menjumlahkannya, sehingga pembulatan terjadi per
+// baris seperti pada faktur. Semua baris harus memakai mata uang yang sama.
+func (c *ruleCalculator) Calculate(ctx context.Context, region model.Region, lines []model.Line) (*model.Result, error) {
+	currency := types.DefaultCurrency()
+	if len(lines) > 0 {
+		currency = lines[0].Amount.Currency
+	}
+	result := &model.Result{
+		Lines:     make([]model.LineTax, 0, len(lines)),
+		Breakdown: []model.Breakdown{},
+		Total:     types.NewMoney(0, currency),
+		Exclusive: types.NewMoney(0, currency),
+	}
+	if len(lines) == 0 {
+		return result, nil
+	}
+
+	rules, err := c.rules(ctx, region, lines)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := make(map[string]int)
+	for _, line := range lines {
+		lineTax, err := taxLine(line, rules[line.TaxClass])
+		if err != nil {
+			return nil, err
+		}
+		result.Lines = append(result.Lines, lineTax)
+
+		rule := lineTax.Rule
+		if rule == nil {
+			continue
+		}
+		result.Total.Amount += lineTax.Tax.Amount
+		if !rule.Inclusive {
+			result.Exclusive.Amount += lineTax.Tax.Amount
+		}
+
+		key := fmt.Sprintf("%s|%s|%t", rule.Name, rule.Rate, rule.Inclusive)
+		i, ok := breakdown[key]
+		if !ok {
+			i = len(result.Breakdown)
+			breakdown[key] = i
+			result.Breakdown = append(result.Breakdown, model.Breakdown{
+				Name:      rule.Name,
+				Rate:      rule.Rate,
+				Inclusive: rule.Inclusive,
+				Taxable:   types.NewMoney(0, currency),
+				Amount:    types.NewMoney(0, currency),
+			})
+		}
+		result.Breakdown[i].Taxable.Amount += lineTax.Net.Amount
+		result.Breakdown[i].Amount.Amount += lineTax.Tax.Amount
+	}
+	return result, nil
+}
+
+// rules memuat rule untuk kelas pajak yang dipakai baris, satu per kelas. Rule khusus region
+// menimpa rule seluruh negara.
+func (c *ruleCalculator) rules(ctx context.Context, region model.Region, lines []model.Line) (map[string]*model.Rule, error) {
+	seen := make(map[string]bool)
+	var classes []string
+	for _, line := range lines {
+		if !seen[line.TaxClass] {
+			seen[line.TaxClass] = true
+			classes = append(classes, line.TaxClass)
+		}
+	}
+
+	found, err := c.repo.FindRules(ctx, region.Country, region.Region, classes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tax rules for %s: %w", region, err)
+	}
+
+	rules := make(map[string]*model.Rule, len(found))
+	for i := range found {
+		rule := &found[i]
+		if current, ok := rules[rule.TaxClass]; !ok || current.Region == "" {
+			rules[rule.TaxClass] = rule
+		}
+	}
+	return rules, nil
+}
+
+// taxLine menghitung pajak satu baris. Untuk harga inclusive pajak diambil dari dalam harga
+// (gross * rate / (100 + rate)), untuk exclusive ditambahkan di atasnya (net * rate / 100).
+func taxLine(line model.Line, rule *model.Rule) (model.LineTax, error) {
+	lineTax := model.LineTax{
+		ID:    line.ID,
+		Rule:  rule,
+		Net:   line.Amount,
+		Tax:   types.NewMoney(0, line.Amount.Currency),
+		Gross: line.Amount,
+	}
+	if rule == nil {
+		return lineTax, nil
+	}
+
+	rate, ok := new(big.Rat).SetString(rule.Rate)
+	if !ok {
+		return lineTax, fmt.Errorf("invalid tax rate %q on rule %d", rule.Rate, rule.ID)
+	}
+
+	hundred := big.NewRat(100, 1)
+	if rule.Inclusive {
+		lineTax.Tax = line.Amount.MulRat(new(big.Rat).Quo(rate, new(big.Rat).Add(hundred, rate)))
+		lineTax.Net.Amount = line.Amount.Amount - lineTax.Tax.Amount
+	} else {
+		lineTax.Tax = line.Amount.MulRat(new(big.Rat).Quo(rate, hundred))
+		lineTax.Gross.Amount = line.Amount.Amount + lineTax.Tax.Amount
+	}
+	return lineTax, nil
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"testing"
+
+	"go-fiber-api/internal/app/tax/model"
+	"go-fiber-api/internal/shared/types"
+)
+
+func TestTaxLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount types.Money
+		rule   *model.Rule
+		net    int64
+		tax    int64
+		gross  int64
+	}{
+		{
+			name:   "no rule",
+			amount: types.NewMoney(10000, "USD"),
+			net:    10000, tax: 0, gross: 10000,
+		},
+		{
+			name:   "exclusive is added on top",
+			amount: types.NewMoney(10000, "USD"),
+			rule:   &model.Rule{Rate: "11", Inclusive: false},
+			net:    10000, tax: 1100, gross: 11100,
+		},
+		{
+			name:   "inclusive is taken out of the price",
+			amount: types.NewMoney(11100, "USD"),
+			rule:   &model.Rule{Rate: "11", Inclusive: true},
+			net:    10000, tax: 1100, gross: 11100,
+		},
+		{
+			name:   "exclusive rounds half away from zero",
+			amount: types.NewMoney(5, "USD"),
+			rule:   &model.Rule{Rate: "10", Inclusive: false},
+			net:    5, tax: 1, gross: 6,
+		},
+		{
+			name:   "fractional rate",
+			amount: types.NewMoney(10000, "USD"),
+			rule:   &model.Rule{Rate: "8.875", Inclusive: false},
+			net:    10000, tax: 888, gross: 10888,
+		},
+		{
+			name:   "large IDR exclusive",
+			amount: types.NewMoney(10_000_000_000, "IDR"),
+			rule:   &model.Rule{Rate: "11", Inclusive: false},
+			net:    10_000_000_000, tax: 1_100_000_000, gross: 11_100_000_000,
+		},
+		{
+			name:   "large IDR inclusive",
+			amount: types.NewMoney(11_100_000_000, "IDR"),
+			rule:   &model.Rule{Rate: "11", Inclusive: true},
+			net:    10_000_000_000, tax: 1_100_000_000, gross: 11_100_000_000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := taxLine(model.Line{ID: 1, Amount: tt.amount}, tt.rule)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Net.Amount != tt.net || got.Tax.Amount != tt.tax || got.Gross.Amount != tt.gross {
+				t.Errorf("got net %d tax %d gross %d, want net %d tax %d gross %d",
+					got.Net.Amount, got.Tax.Amount, got.Gross.Amount, tt.net, tt.tax, tt.gross)
+			}
+			if got.Tax.Currency != tt.amount.Currency {
+				t.Errorf("tax currency %s, want %s", got.Tax.Currency, tt.amount.Currency)
+			}
+		})
+	}
+}
+
+func TestTaxLineInvalidRate(t *testing.T) {
+	_, err := taxLine(model.Line{Amount: types.NewMoney(100, "USD")}, &model.Rule{ID: 3, Rate: "abc"})
+	if err == nil {
+		t.Fatal("expected error for invalid rate")
+	}
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"go-fiber-api/internal/app/tax/model"
+	"go-fiber-api/internal/app/tax/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/utils/web"
+)
+
+type Tax interface {
+	GetClasses(ctx context.Context) ([]model.Class, error)
+	SaveClass(ctx context.Context, code string, req *dto.TaxClassRequest) (*model.Class, error)
+	ValidateClass(ctx context.Context, code string) error
+	GetRules(ctx context.Context, country string) ([]model.Rule, error)
+	CreateRule(ctx context.Context, req *dto.TaxRuleRequest) (*model.Rule, error)
+	UpdateRule(ctx context.Context, id uint, req *dto.TaxRuleRequest) (*model.Rule, error)
+	DeleteRule(ctx context.Context, id uint) error
+}
+
+type taxService struct {
+	repo repository.Tax
+}
+
+func NewTaxService(repo repository.Tax) Tax {
+	return &taxService{repo: repo}
+}
+
+func (s *taxService) GetClasses(ctx context.Context) ([]model.Class, error) {
+	classes, err := s.repo.GetClasses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if classes == nil {
+		classes = []model.Class{}
+	}
+	return classes, nil
+}
+
+func (s *taxService) SaveClass(ctx context.Context, code string, req *dto.TaxClassRequest) (*model.Class, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" || len(code) > 50 {
+		return nil, web.NewHTTPError(http.StatusBadRequest, "Invalid tax class code", web.ErrValidation)
+	}
+
+	class := &model.Class{Code: code, Name: req.Name}
+	if err := s.repo.UpsertClass(ctx, class); err != nil {
+		slog.Error("Failed to save tax class", "code", code, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Tax class saved", "code", code)
+	return class, nil
+}
+
+// ValidateClass memastikan kelas pajak yang dipasang di produk sudah terdaftar
+func (s *taxService) ValidateClass(ctx context.Context, code string) error {
+	if _, err := s.repo.GetClass(ctx, code); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.NewHTTPError(http.StatusUnprocessableEntity, fmt.Sprintf("Unknown tax class %q", code), web.ErrValidation)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *taxService) GetRules(ctx context.Context, country string) ([]model.Rule, error) {
+	rules, err := s.repo.GetRules(ctx, strings.ToUpper(strings.TrimSpace(country)))
+	if err != nil {
+		return nil, err
+	}
+	if rules == nil {
+		rules = []model.Rule{}
+	}
+	return rules, nil
+}
+
+func (s *taxService) CreateRule(ctx context.Context, req *dto.TaxRuleRequest) (*model.Rule, error) {
+	rule := &model.Rule{IsActive: true}
+	if err := s.applyRuleRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		slog.Error("Failed to create tax rule", "tax_class", rule.TaxClass, "country", rule.Country, "region", rule.Region, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Tax rule created", "rule_id", rule.ID, "tax_class", rule.TaxClass, "country", rule.Country, "region", rule.Region)
+	return rule, nil
+}
+
+func (s *taxService) UpdateRule(ctx context.Context, id uint, req *dto.TaxRuleRequest) (*model.Rule, error) {
+	rule, err := s.repo.GetRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Tax rule not found", web.ErrNotFound)
+		}
+		return nil, err
+	}
+	if err := s.applyRuleRequest(ctx, rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		slog.Error("Failed to update tax rule", "rule_id", id, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Tax rule updated", "rule_id", id)
+	return rule, nil
+}
+
+func (s *taxService) DeleteRule(ctx context.Context, id uint) error {
+	if _, err := s.repo.GetRule(ctx, id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return web.NewHTTPError(http.StatusNotFound, "Tax rule not found", web.ErrNotFound)
+		}
+		return err
+	}
+	if err := s.repo.DeleteRule(ctx, id); err != nil {
+		slog.Error("Failed to delete tax rule", "rule_id", id, "error", err)
+		return err
+	}
+
+	slog.Info("Tax rule deleted", "rule_id", id)
+	return nil
+}
+
+// applyRuleRequest menyalin request ke rule. Satu kelas hanya boleh punya satu rule per wilayah.
+func (s *taxService) applyRuleRequest(ctx context.Context, rule *model.Rule, req *dto.TaxRuleRequest) error {
+	rate, ok := new(big.Rat).SetString(req.Rate)
+	if !ok || rate.Sign() < 0 || rate.Cmp(big.NewRat(100, 1)) >= 0 {
+		return web.NewHTTPError(http.StatusBadRequest, "Rate must be a percentage between 0 and 100", web.ErrValidation)
+	}
+
+	rule.TaxClass = strings.ToLower(strings.TrimSpace(req.TaxClass))
+	if err := s.ValidateClass(ctx, rule.TaxClass); err != nil {
+		return err
+	}
+	rule.Country = strings.ToUpper(req.Country)
+	rule.Region = strings.ToUpper(strings.TrimSpace(req.Region))
+	rule.Name = req.Name
+	rule.Rate = req.Rate
+	rule.Inclusive = req.Inclusive
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	existing, err := s.repo.GetRules(ctx, rule.Country)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != rule.ID && other.TaxClass == rule.TaxClass && other.Region == rule.Region {
+			return web.NewHTTPError(http.StatusConflict,
+				fmt.Sprintf("Tax class %s already has a rule for %s", rule.TaxClass, model.Region{Country: rule.Country, Region: rule.Region}),
+				web.ErrConflict)
+		}
+	}
+	return nil
+}
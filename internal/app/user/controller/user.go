@@ -2,6 +2,7 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io"
 	"log/slog"
@@ -16,14 +17,21 @@ import (
 	"github.com/gorilla/schema"
 )
 
+// GuestCartMerger menggabungkan guest cart ke cart user setelah login
+type GuestCartMerger interface {
+	MergeGuestCart(ctx context.Context, userID uint, token string) (*dto.CartMergeResult, error)
+}
+
 type user struct {
 	userService service.User
+	cartMerger  GuestCartMerger
 	decoder     *schema.Decoder
 }
 
-func NewUserController(mux *http.ServeMux, userService service.User) {
+func NewUserController(mux *http.ServeMux, userService service.User, cartMerger GuestCartMerger) {
 	u := &user{
 		userService: userService,
+		cartMerger:  cartMerger,
 		decoder:     schema.NewDecoder(),
 	}
 	u.decoder.IgnoreUnknownKeys(true)
@@ -126,6 +134,16 @@ func (u *user) login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Guest cart dari sebelum login digabung ke cart user; kegagalannya tidak menggagalkan login
+	if token := r.Header.Get("X-Cart-Token"); token != "" && u.cartMerger != nil {
+		merge, err := u.cartMerger.MergeGuestCart(r.Context(), uint(res.User.ID), token)
+		if err != nil {
+			slog.Warn("Gagal menggabungkan guest cart saat login", "user_id", res.User.ID, "error", err)
+		} else {
+			res.CartMerge = merge
+		}
+	}
+
 	slog.Info("Login sukses", "user", res.User.Email)
 	web.OK(w, http.StatusOK, res)
 }
@@ -0,0 +1,279 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/internal/app/wishlist/service"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/middleware"
+	"go-fiber-api/utils/web"
+)
+
+type wishlist struct {
+	wishlistService service.Wishlist
+}
+
+func NewWishlistController(mux *http.ServeMux, wishlistService service.Wishlist) {
+	wl := &wishlist{wishlistService: wishlistService}
+
+	mux.Handle("GET /v1/wishlists", middleware.AuthMiddleware(http.HandlerFunc(wl.GetAll)))
+	mux.Handle("POST /v1/wishlists", middleware.AuthMiddleware(http.HandlerFunc(wl.Create)))
+	mux.Handle("GET /v1/wishlists/{id}", middleware.AuthMiddleware(http.HandlerFunc(wl.GetByID)))
+	mux.Handle("PUT /v1/wishlists/{id}", middleware.AuthMiddleware(http.HandlerFunc(wl.Rename)))
+	mux.Handle("DELETE /v1/wishlists/{id}", middleware.AuthMiddleware(http.HandlerFunc(wl.Delete)))
+	mux.Handle("PUT /v1/wishlists/{id}/share", middleware.AuthMiddleware(http.HandlerFunc(wl.SetSharing)))
+	mux.Handle("POST /v1/wishlists/{id}/items", middleware.AuthMiddleware(http.HandlerFunc(wl.AddItem)))
+	mux.Handle("DELETE /v1/wishlists/{id}/items/{itemId}", middleware.AuthMiddleware(http.HandlerFunc(wl.RemoveItem)))
+	mux.Handle("POST /v1/wishlists/{id}/items/{itemId}/move-to-cart", middleware.AuthMiddleware(http.HandlerFunc(wl.MoveToCart)))
+
+	// Wishlist yang dibagikan bisa dibuka tanpa login
+	mux.HandleFunc("GET /v1/shared-wishlists/{token}", wl.GetShared)
+}
+
+// requireUser mengembalikan user ID dari context, atau menulis 401
+func requireUser(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	userID := web.GetUserID(r)
+	if userID == 0 {
+		web.Err(w, web.NewHTTPError(http.StatusUnauthorized, "Unauthorized", web.ErrAuthentication))
+		return 0, false
+	}
+	return userID, true
+}
+
+func (wl *wishlist) GetAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	wishlists, err := wl.wishlistService.GetByUserID(r.Context(), userID)
+	if err != nil {
+		slog.Error("GetAll wishlists failed", "user_id", userID, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, wishlists)
+}
+
+func (wl *wishlist) Create(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req dto.WishlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	created, err := wl.wishlistService.Create(r.Context(), userID, &req)
+	if err != nil {
+		slog.Error("Create wishlist failed", "user_id", userID, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusCreated, created)
+}
+
+func (wl *wishlist) GetByID(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := web.PathID(r, "id", "wishlist")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	found, err := wl.wishlistService.GetByID(r.Context(), userID, id)
+	if err != nil {
+		slog.Error("GetByID wishlist failed", "wishlist_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, found)
+}
+
+func (wl *wishlist) GetShared(w http.ResponseWriter, r *http.Request) {
+	shared, err := wl.wishlistService.GetShared(r.Context(), r.PathValue("token"))
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, shared)
+}
+
+func (wl *wishlist) Rename(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := web.PathID(r, "id", "wishlist")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.WishlistRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	renamed, err := wl.wishlistService.Rename(r.Context(), userID, id, &req)
+	if err != nil {
+		slog.Error("Rename wishlist failed", "wishlist_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, renamed)
+}
+
+func (wl *wishlist) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := web.PathID(r, "id", "wishlist")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := wl.wishlistService.Delete(r.Context(), userID, id); err != nil {
+		slog.Error("Delete wishlist failed", "wishlist_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OKNoContent(w, http.StatusOK)
+}
+
+func (wl *wishlist) SetSharing(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := web.PathID(r, "id", "wishlist")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.WishlistShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+
+	updated, err := wl.wishlistService.SetSharing(r.Context(), userID, id, req.Public)
+	if err != nil {
+		slog.Error("SetSharing wishlist failed", "wishlist_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusOK, updated)
+}
+
+func (wl *wishlist) AddItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := web.PathID(r, "id", "wishlist")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	var req dto.WishlistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+		return
+	}
+	if err := web.Validator().Struct(&req); err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	item, err := wl.wishlistService.AddItem(r.Context(), userID, id, &req)
+	if err != nil {
+		slog.Error("AddItem wishlist failed", "wishlist_id", id, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusCreated, item)
+}
+
+func (wl *wishlist) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := web.PathID(r, "id", "wishlist")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+	itemID, err := web.PathID(r, "itemId", "wishlist item")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	if err := wl.wishlistService.RemoveItem(r.Context(), userID, id, itemID); err != nil {
+		slog.Error("RemoveItem wishlist failed", "wishlist_id", id, "item_id", itemID, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OKNoContent(w, http.StatusOK)
+}
+
+func (wl *wishlist) MoveToCart(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := web.PathID(r, "id", "wishlist")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+	itemID, err := web.PathID(r, "itemId", "wishlist item")
+	if err != nil {
+		web.Err(w, err)
+		return
+	}
+
+	// Body opsional; tanpa body quantity default 1
+	var req dto.WishlistMoveRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			web.Err(w, web.NewHTTPError(http.StatusBadRequest, "Invalid request body", web.ErrValidation))
+			return
+		}
+		if err := web.Validator().Struct(&req); err != nil {
+			web.Err(w, err)
+			return
+		}
+	}
+
+	cartItem, err := wl.wishlistService.MoveToCart(r.Context(), userID, id, itemID, req.Quantity)
+	if err != nil {
+		slog.Error("MoveToCart failed", "wishlist_id", id, "item_id", itemID, "error", err)
+		web.Err(w, err)
+		return
+	}
+	web.OK(w, http.StatusCreated, cartItem)
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+type Wishlist struct {
+	ID         uint      `db:"id" json:"id"`
+	UserID     uint      `db:"user_id" json:"user_id"`
+	Name       string    `db:"name" json:"name"`
+	ShareToken *string   `db:"share_token" json:"share_token,omitempty"`
+	ItemCount  int       `db:"item_count" json:"item_count"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// WishlistItem menyertakan data produk terkini (nama, harga berlaku, stok) dari join ke products
+type WishlistItem struct {
+	ID         uint      `db:"id" json:"id"`
+	WishlistID uint      `db:"wishlist_id" json:"wishlist_id"`
+	ProductID  uint      `db:"product_id" json:"product_id"`
+	Color      string    `db:"color" json:"color"`
+	Size       string    `db:"size" json:"size"`
+	Note       *string   `db:"note" json:"note,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+
+	ProductName string `db:"product_name" json:"product_name"`
+	Slug        string `db:"slug" json:"slug"`
+	Price       int64  `db:"price" json:"-"`
+	Currency    string `db:"currency" json:"-"`
+	Stock       int    `db:"stock" json:"-"`
+}
+
+// UnitPrice mengembalikan harga produk yang berlaku sekarang
+func (i WishlistItem) UnitPrice() types.Money {
+	return types.NewMoney(i.Price, i.Currency)
+}
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"go-fiber-api/database"
+	"go-fiber-api/internal/app/wishlist/model"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Wishlist interface {
+	Create(ctx context.Context, wishlist *model.Wishlist) error
+	FindByUserID(ctx context.Context, userID uint) ([]model.Wishlist, error)
+	FindByID(ctx context.Context, id uint) (*model.Wishlist, error)
+	FindByShareToken(ctx context.Context, token string) (*model.Wishlist, error)
+	Rename(ctx context.Context, id uint, name string) error
+	SetShareToken(ctx context.Context, id uint, token *string) error
+	Delete(ctx context.Context, id uint) error
+	FindItems(ctx context.Context, wishlistID uint) ([]model.WishlistItem, error)
+	FindItemByID(ctx context.Context, wishlistID, itemID uint) (*model.WishlistItem, error)
+	AddItem(ctx context.Context, item *model.WishlistItem) (bool, error)
+	DeleteItem(ctx context.Context, wishlistID, itemID uint) error
+}
+
+type wishlistRepo struct {
+	db *sqlx.DB
+}
+
+func NewWishlistRepository(db *sqlx.DB) Wishlist {
+	return &wishlistRepo{db: db}
+}
+
+// selectWishlists menyertakan jumlah item per wishlist
+const selectWishlists = `
+	SELECT w.*, (SELECT COUNT(*) FROM wishlist_items wi WHERE wi.wishlist_id = w.id) AS item_count
+	FROM wishlists w`
+
+// selectWishlistItems menyertakan data produk dan harga sale yang sedang berlaku
+const selectWishlistItems = `
+	SELECT wi.*, p.name AS product_name, p.slug, COALESCE(sp.price, p.price) AS price, p.currency,
+		st.quantity AS stock
+	FROM wishlist_items wi
+	JOIN products p ON p.id = wi.product_id
+	LEFT JOIN LATERAL (
+		SELECT pp.price
+		FROM product_prices pp
+		WHERE pp.product_id = p.id AND pp.kind = 'sale'
+		  AND pp.valid_from <= NOW() AND (pp.valid_to IS NULL OR pp.valid_to > NOW())
+		ORDER BY pp.valid_from DESC
+		LIMIT 1
+	) sp ON TRUE
+	CROSS JOIN LATERAL (
+		-- Stok bundle diturunkan dari komponennya
+		SELECT CASE WHEN p.type = 'bundle' THEN (
+			SELECT COALESCE(MIN(COALESCE(c.quantity, 0) / bi.quantity), 0)
+			FROM product_bundle_items bi
+			JOIN products c ON c.id = bi.component_id
+			WHERE bi.bundle_id = p.id
+		) ELSE COALESCE(p.quantity, 0) END AS quantity
+	) st`
+
+func (r *wishlistRepo) Create(ctx context.Context, wishlist *model.Wishlist) error {
+	query := `
+		INSERT INTO wishlists (user_id, name)
+		VALUES (:user_id, :name)
+		RETURNING id, created_at, updated_at
+	`
+
+	slog.Info("Executing query Create wishlist", "query", query, "user_id", wishlist.UserID)
+	rows, err := r.db.NamedQueryContext(ctx, query, wishlist)
+	if err != nil {
+		slog.Error("Failed to create wishlist", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return rows.Scan(&wishlist.ID, &wishlist.CreatedAt, &wishlist.UpdatedAt)
+	}
+	return nil
+}
+
+func (r *wishlistRepo) FindByUserID(ctx context.Context, userID uint) ([]model.Wishlist, error) {
+	var wishlists []model.Wishlist
+	query := selectWishlists + ` WHERE w.user_id = $1 ORDER BY w.created_at, w.id`
+
+	if err := r.db.SelectContext(ctx, &wishlists, query, userID); err != nil {
+		slog.Error("Failed to get wishlists", "user_id", userID, "error", err)
+		return nil, err
+	}
+	return wishlists, nil
+}
+
+func (r *wishlistRepo) FindByID(ctx context.Context, id uint) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	if err := r.db.GetContext(ctx, &wishlist, selectWishlists+` WHERE w.id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepo) FindByShareToken(ctx context.Context, token string) (*model.Wishlist, error) {
+	var wishlist model.Wishlist
+	if err := r.db.GetContext(ctx, &wishlist, selectWishlists+` WHERE w.share_token = $1`, token); err != nil {
+		return nil, err
+	}
+	return &wishlist, nil
+}
+
+func (r *wishlistRepo) Rename(ctx context.Context, id uint, name string) error {
+	query := `UPDATE wishlists SET name = $2, updated_at = NOW() WHERE id = $1`
+
+	slog.Info("Executing query Rename wishlist", "query", query, "id", id)
+	if _, err := r.db.ExecContext(ctx, query, id, name); err != nil {
+		slog.Error("Failed to rename wishlist", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *wishlistRepo) SetShareToken(ctx context.Context, id uint, token *string) error {
+	query := `UPDATE wishlists SET share_token = $2, updated_at = NOW() WHERE id = $1`
+
+	slog.Info("Executing query SetShareToken wishlist", "query", query, "id", id, "public", token != nil)
+	if _, err := r.db.ExecContext(ctx, query, id, token); err != nil {
+		slog.Error("Failed to update wishlist share token", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *wishlistRepo) Delete(ctx context.Context, id uint) error {
+	query := `DELETE FROM wishlists WHERE id = $1`
+
+	slog.Info("Executing query Delete wishlist", "query", query, "id", id)
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		slog.Error("Failed to delete wishlist", "id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (r *wishlistRepo) FindItems(ctx context.Context, wishlistID uint) ([]model.WishlistItem, error) {
+	var items []model.WishlistItem
+	query := selectWishlistItems + ` WHERE wi.wishlist_id = $1 ORDER BY wi.created_at, wi.id`
+
+	if err := r.db.SelectContext(ctx, &items, query, wishlistID); err != nil {
+		slog.Error("Failed to get wishlist items", "wishlist_id", wishlistID, "error", err)
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *wishlistRepo) FindItemByID(ctx context.Context, wishlistID, itemID uint) (*model.WishlistItem, error) {
+	var item model.WishlistItem
+	query := selectWishlistItems + ` WHERE wi.wishlist_id = $1 AND wi.id = $2`
+
+	if err := r.db.GetContext(ctx, &item, query, wishlistID, itemID); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// AddItem menambahkan item ke wishlist. Mengembalikan false jika produk dengan varian yang sama sudah ada.
+func (r *wishlistRepo) AddItem(ctx context.Context, item *model.WishlistItem) (bool, error) {
+	query := `
+		INSERT INTO wishlist_items (wishlist_id, product_id, color, size, note)
+		VALUES (:wishlist_id, :product_id, :color, :size, :note)
+		ON CONFLICT (wishlist_id, product_id, color, size) DO NOTHING
+		RETURNING id, created_at
+	`
+
+	slog.Info("Executing query AddItem wishlist", "query", query, "wishlist_id", item.WishlistID, "product_id", item.ProductID)
+	rows, err := r.db.NamedQueryContext(ctx, query, item)
+	if err != nil {
+		slog.Error("Failed to add wishlist item", "error", err)
+		return false, err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+	if err := rows.Scan(&item.ID, &item.CreatedAt); err != nil {
+		return false, err
+	}
+
+	if _, err := r.db.ExecContext(ctx, `UPDATE wishlists SET updated_at = NOW() WHERE id = $1`, item.WishlistID); err != nil {
+		slog.Warn("Failed to touch wishlist", "wishlist_id", item.WishlistID, "error", err)
+	}
+	return true, nil
+}
+
+func (r *wishlistRepo) DeleteItem(ctx context.Context, wishlistID, itemID uint) error {
+	query := `DELETE FROM wishlist_items WHERE wishlist_id = $1 AND id = $2`
+
+	slog.Info("Executing query DeleteItem wishlist", "query", query, "wishlist_id", wishlistID, "item_id", itemID)
+	if _, err := database.Conn(ctx, r.db).ExecContext(ctx, query, wishlistID, itemID); err != nil {
+		slog.Error("Failed to delete wishlist item", "error", err)
+		return err
+	}
+	return nil
+}
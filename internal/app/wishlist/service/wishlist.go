@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"go-fiber-api/database"
+	cartModel "go-fiber-api/internal/app/cart/model"
+	cartService "go-fiber-api/internal/app/cart/service"
+	productRepo "go-fiber-api/internal/app/product/repository"
+	"go-fiber-api/internal/app/wishlist/model"
+	wishlistRepo "go-fiber-api/internal/app/wishlist/repository"
+	"go-fiber-api/internal/shared/dto"
+	"go-fiber-api/utils/web"
+)
+
+type Wishlist interface {
+	Create(ctx context.Context, userID uint, req *dto.WishlistRequest) (*dto.WishlistResponse, error)
+	GetByUserID(ctx context.Context, userID uint) ([]dto.WishlistResponse, error)
+	GetByID(ctx context.Context, userID, id uint) (*dto.WishlistResponse, error)
+	GetShared(ctx context.Context, token string) (*dto.WishlistResponse, error)
+	Rename(ctx context.Context, userID, id uint, req *dto.WishlistRequest) (*dto.WishlistResponse, error)
+	SetSharing(ctx context.Context, userID, id uint, public bool) (*dto.WishlistResponse, error)
+	Delete(ctx context.Context, userID, id uint) error
+	AddItem(ctx context.Context, userID, id uint, req *dto.WishlistItemRequest) (*dto.WishlistItemResponse, error)
+	RemoveItem(ctx context.Context, userID, id, itemID uint) error
+	MoveToCart(ctx context.Context, userID, id, itemID uint, quantity int) (*cartModel.CartItem, error)
+}
+
+type wishlistService struct {
+	repo        wishlistRepo.Wishlist
+	productRepo productRepo.Product
+	cartService cartService.Cart
+	tx          database.Transactor
+}
+
+func NewWishlistService(repo wishlistRepo.Wishlist, productRepo productRepo.Product, cartService cartService.Cart, tx database.Transactor) Wishlist {
+	return &wishlistService{
+		repo:        repo,
+		productRepo: productRepo,
+		cartService: cartService,
+		tx:          tx,
+	}
+}
+
+func (s *wishlistService) Create(ctx context.Context, userID uint, req *dto.WishlistRequest) (*dto.WishlistResponse, error) {
+	wishlist := &model.Wishlist{UserID: userID, Name: req.Name}
+	if err := s.repo.Create(ctx, wishlist); err != nil {
+		return nil, fmt.Errorf("failed to create wishlist: %w", err)
+	}
+
+	slog.Info("Wishlist created", "wishlist_id", wishlist.ID, "user_id", userID)
+	return toWishlistResponse(wishlist, nil), nil
+}
+
+func (s *wishlistService) GetByUserID(ctx context.Context, userID uint) ([]dto.WishlistResponse, error) {
+	wishlists, err := s.repo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wishlists: %w", err)
+	}
+
+	result := make([]dto.WishlistResponse, 0, len(wishlists))
+	for i := range wishlists {
+		result = append(result, *toWishlistResponse(&wishlists[i], nil))
+	}
+	return result, nil
+}
+
+func (s *wishlistService) GetByID(ctx context.Context, userID, id uint) (*dto.WishlistResponse, error) {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.withItems(ctx, wishlist)
+}
+
+// GetShared mengembalikan wishlist publik berdasarkan share token, tanpa perlu login
+func (s *wishlistService) GetShared(ctx context.Context, token string) (*dto.WishlistResponse, error) {
+	wishlist, err := s.repo.FindByShareToken(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Wishlist not found", web.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.withItems(ctx, wishlist)
+	if err != nil {
+		return nil, err
+	}
+	resp.ShareToken = nil
+	return resp, nil
+}
+
+func (s *wishlistService) Rename(ctx context.Context, userID, id uint, req *dto.WishlistRequest) (*dto.WishlistResponse, error) {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Rename(ctx, id, req.Name); err != nil {
+		return nil, fmt.Errorf("failed to rename wishlist: %w", err)
+	}
+	wishlist.Name = req.Name
+	return toWishlistResponse(wishlist, nil), nil
+}
+
+// SetSharing membuat share token baru saat public=true, atau mencabutnya saat public=false.
+// Membuat token baru juga membatalkan link lama.
+func (s *wishlistService) SetSharing(ctx context.Context, userID, id uint, public bool) (*dto.WishlistResponse, error) {
+	wishlist, err := s.findOwned(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var token *string
+	if public {
+		generated, err := newShareToken()
+		if err != nil {
+			return nil, err
+		}
+		token = &generated
+	}
+
+	if err := s.repo.SetShareToken(ctx, id, token); err != nil {
+		return nil, fmt.Errorf("failed to update wishlist sharing: %w", err)
+	}
+	wishlist.ShareToken = token
+
+	slog.Info("Wishlist sharing updated", "wishlist_id", id, "public", public)
+	return toWishlistResponse(wishlist, nil), nil
+}
+
+func (s *wishlistService) Delete(ctx context.Context, userID, id uint) error {
+	if _, err := s.findOwned(ctx, userID, id); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete wishlist: %w", err)
+	}
+
+	slog.Info("Wishlist deleted", "wishlist_id", id, "user_id", userID)
+	return nil
+}
+
+func (s *wishlistService) AddItem(ctx context.Context, userID, id uint, req *dto.WishlistItemRequest) (*dto.WishlistItemResponse, error) {
+	if _, err := s.findOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.productRepo.GetProductsByID(ctx, req.ProductID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, web.NewHTTPError(http.StatusNotFound, "Product not found", web.ErrProductNotFound)
+		}
+		return nil, err
+	}
+
+	item := &model.WishlistItem{
+		WishlistID: id,
+		ProductID:  req.ProductID,
+		Color:      req.Color,
+		Size:       req.Size,
+	}
+	if req.Note != "" {
+		item.Note = &req.Note
+	}
+
+	added, err := s.repo.AddItem(ctx, item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add wishlist item: %w", err)
+	}
+	if !added {
+		return nil, web.NewHTTPError(http.StatusConflict, "Product is already in this wishlist", web.ErrConflict)
+	}
+
+	saved, err := s.repo.FindItemByID(ctx, id, item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Wishlist item added", "wishlist_id", id, "product_id", req.ProductID)
+	resp := toWishlistItemResponse(saved)
+	return &resp, nil
+}
+
+func (s *wishlistService) RemoveItem(ctx context.Context, userID, id, itemID uint) error {
+	if _, err := s.findOwnedItem(ctx, userID, id, itemID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteItem(ctx, id, itemID); err != nil {
+		return fmt.Errorf("failed to remove wishlist item: %w", err)
+	}
+	return nil
+}
+
+// MoveToCart menambahkan item wishlist ke cart lewat cartService.Create (termasuk cek stok dan
+// penggabungan dengan baris cart yang sama), lalu menghapusnya dari wishlist. Keduanya berjalan
+// dalam satu transaksi agar item tidak tertinggal di wishlist dan cart sekaligus.
+func (s *wishlistService) MoveToCart(ctx context.Context, userID, id, itemID uint, quantity int) (*cartModel.CartItem, error) {
+	item, err := s.findOwnedItem(ctx, userID, id, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	var cartItem *cartModel.CartItem
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		cartItem, err = s.cartService.Create(ctx, cartModel.UserOwner(userID), &dto.CartItemRequest{
+			ProductID: item.ProductID,
+			Quantity:  quantity,
+			Color:     item.Color,
+			Size:      item.Size,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.repo.DeleteItem(ctx, id, itemID); err != nil {
+			return fmt.Errorf("failed to remove wishlist item: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("Failed to move wishlist item to cart", "wishlist_id", id, "item_id", itemID, "error", err)
+		return nil, err
+	}
+
+	slog.Info("Wishlist item moved to cart", "wishlist_id", id, "item_id", itemID, "cart_id", cartItem.ID)
+	return cartItem, nil
+}
+
+// findOwned memastikan wishlist ada dan milik user
+func (s *wishlistService) findOwned(ctx context.Context, userID, id uint) (*model.Wishlist, error) {
+	wishlist, err := s.repo.FindByID(ctx, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Wishlist not found", web.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if wishlist.UserID != userID {
+		return nil, web.NewHTTPError(http.StatusForbidden, "Access denied to this wishlist", web.ErrForbidden)
+	}
+	return wishlist, nil
+}
+
+func (s *wishlistService) findOwnedItem(ctx context.Context, userID, id, itemID uint) (*model.WishlistItem, error) {
+	if _, err := s.findOwned(ctx, userID, id); err != nil {
+		return nil, err
+	}
+
+	item, err := s.repo.FindItemByID(ctx, id, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, web.NewHTTPError(http.StatusNotFound, "Wishlist item not found", web.ErrNotFound)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *wishlistService) withItems(ctx context.Context, wishlist *model.Wishlist) (*dto.WishlistResponse, error) {
+	items, err := s.repo.FindItems(ctx, wishlist.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch wishlist items: %w", err)
+	}
+	return toWishlistResponse(wishlist, items), nil
+}
+
+func toWishlistResponse(wishlist *model.Wishlist, items []model.WishlistItem) *dto.WishlistResponse {
+	resp := &dto.WishlistResponse{
+		ID:         wishlist.ID,
+		Name:       wishlist.Name,
+		ShareToken: wishlist.ShareToken,
+		ItemCount:  wishlist.ItemCount,
+		CreatedAt:  wishlist.CreatedAt,
+		UpdatedAt:  wishlist.UpdatedAt,
+	}
+	if items != nil {
+		resp.Items = make([]dto.WishlistItemResponse, 0, len(items))
+		for i := range items {
+			resp.Items = append(resp.Items, toWishlistItemResponse(&items[i]))
+		}
+		resp.ItemCount = len(items)
+	}
+	return resp
+}
+
+func toWishlistItemResponse(item *model.WishlistItem) dto.WishlistItemResponse {
+	return dto.WishlistItemResponse{
+		ID:          item.ID,
+		ProductID:   item.ProductID,
+		ProductName: item.ProductName,
+		Slug:        item.Slug,
+		Color:       item.Color,
+		Size:        item.Size,
+		Note:        item.Note,
+		Price:       item.UnitPrice(),
+		InStock:     item.Stock > 0,
+		CreatedAt:   item.CreatedAt,
+	}
+}
+
+// newShareToken membuat token acak yang aman untuk URL
+func newShareToken() (string, error) {
+	b := make([]byte, 18)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,8 @@
+package dto
+
+type AttributeDefinitionRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Type          string   `json:"type" validate:"required,oneof=string number boolean enum"`
+	AllowedValues []string `json:"allowed_values" validate:"omitempty,dive,required,max=100"`
+	Filterable    bool     `json:"filterable"`
+}
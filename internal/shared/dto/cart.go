@@ -1,55 +1,164 @@
 package dto
 
-import "time"
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
 
 // CartItemRequest represents the request payload for creating/updating cart items
 type CartItemRequest struct {
 	ProductID uint   `json:"product_id" validate:"required,min=1"`
-	Quantity  int    `json:"quantity" validate:"required,min=1,max=999"`
+	Quantity  int    `json:"quantity" validate:"required,min=1"` // Batas atas diatur CART_MAX_LINE_QUANTITY
 	Color     string `json:"color,omitempty" validate:"omitempty,max=50"`
 	Size      string `json:"size,omitempty" validate:"omitempty,max=50"`
 }
 
-// CartItemTotal represents individual item totals in cart calculation
+// CartItemTotal represents individual item totals in cart calculation.
+// Price adalah harga satuan saat ini; untuk bundle, Components berisi isi kit.
 type CartItemTotal struct {
-	CartItemID  uint    `json:"cart_item_id"`
-	ProductID   uint    `json:"product_id"`
-	ProductName string  `json:"product_name"`
-	Price       float64 `json:"price"`
-	Quantity    int     `json:"quantity"`
-	Subtotal    float64 `json:"subtotal"`
+	CartItemID   uint                  `json:"cart_item_id"`
+	ProductID    uint                  `json:"product_id"`
+	ProductName  string                `json:"product_name"`
+	Price        types.Money           `json:"price"`
+	Quantity     int                   `json:"quantity"`
+	Subtotal     types.Money           `json:"subtotal"`
+	PriceChanged bool                  `json:"price_changed"`
+	Tax          *CartLineTax          `json:"tax,omitempty"` // Kosong jika baris tidak kena pajak
+	Components   []CartBundleComponent `json:"components,omitempty"`
+}
+
+// CartLineTax adalah pajak satu baris, dihitung dari harga baris setelah diskon
+type CartLineTax struct {
+	Name      string      `json:"name"`
+	Rate      string      `json:"rate"` // Persen
+	Inclusive bool        `json:"inclusive"`
+	Amount    types.Money `json:"amount"`
+}
+
+// CartBundleComponent adalah satu komponen bundle pada rincian cart. Harga baris tetap
+// harga bundle; komponen tidak dihitung terpisah di total.
+type CartBundleComponent struct {
+	ProductID         uint   `json:"product_id"`
+	Name              string `json:"name"`
+	QuantityPerBundle int    `json:"quantity_per_bundle"`
+	Quantity          int    `json:"quantity"` // Total untuk seluruh baris (per bundle * quantity baris)
 }
 
 // CartTotalResponse represents the complete cart total calculation
 type CartTotalResponse struct {
-	UserID       uint            `json:"user_id"`
-	TotalItems   int             `json:"total_items"`
-	TotalAmount  float64         `json:"total_amount"`
-	Currency     string          `json:"currency"`
-	Items        []CartItemTotal `json:"items"`
-	CalculatedAt time.Time       `json:"calculated_at"`
+	UserID        uint            `json:"user_id,omitempty"` // Kosong untuk guest cart
+	TotalItems    int             `json:"total_items"`       // Jumlah unit, bukan jumlah baris
+	Subtotal      types.Money     `json:"subtotal"`          // Sebelum diskon
+	Discounts     []CartDiscount  `json:"discounts"`
+	DiscountTotal types.Money     `json:"discount_total"`
+	Taxes         []CartTax       `json:"taxes"`
+	TaxTotal      types.Money     `json:"tax_total"`    // Termasuk pajak yang sudah ada di harga
+	TotalAmount   types.Money     `json:"total_amount"` // Subtotal dikurangi diskon, ditambah pajak exclusive
+	TaxRegion     string          `json:"tax_region"`
+	Currency      string          `json:"currency"`
+	Coupon        *CartCoupon     `json:"coupon,omitempty"`
+	Items         []CartItemTotal `json:"items"`
+	CalculatedAt  time.Time       `json:"calculated_at"`
+	ExchangeRates []ExchangeInfo  `json:"exchange_rates,omitempty"` // Terisi jika total dikonversi
+}
+
+// CartTax adalah total satu jenis pajak pada rincian total cart. Pajak inclusive sudah termasuk
+// di harga baris; pajak exclusive ditambahkan ke total.
+type CartTax struct {
+	Name          string      `json:"name"`
+	Rate          string      `json:"rate"`
+	Inclusive     bool        `json:"inclusive"`
+	TaxableAmount types.Money `json:"taxable_amount"`
+	Amount        types.Money `json:"amount"`
+}
+
+// CartDiscount adalah satu baris diskon pada total cart
+type CartDiscount struct {
+	Code        string      `json:"code"`
+	Description string      `json:"description,omitempty"`
+	Amount      types.Money `json:"amount"`
+	CartItemIDs []uint      `json:"cart_item_ids"` // Baris yang mendapat diskon
+}
+
+// CartCoupon adalah kupon yang terpasang di cart. Applied bernilai false jika kupon tidak
+// berlaku untuk isi cart saat ini (mis. belanja belum mencapai minimum); Reason menjelaskan sebabnya.
+type CartCoupon struct {
+	Code    string `json:"code"`
+	Applied bool   `json:"applied"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// CartCouponRequest digunakan untuk memasang kupon ke cart
+type CartCouponRequest struct {
+	Code string `json:"code" validate:"required,max=50"`
+}
+
+// CartValidationResponse melaporkan perubahan harga dan stok sejak item ditambahkan, sebelum checkout
+type CartValidationResponse struct {
+	Valid     bool                 `json:"valid"`
+	Lines     []CartLineValidation `json:"lines"`
+	CheckedAt time.Time            `json:"checked_at"`
+}
+
+// CartLineValidation adalah hasil validasi satu baris cart. Issues kosong berarti baris siap checkout.
+type CartLineValidation struct {
+	CartItemID        uint         `json:"cart_item_id"`
+	ProductID         uint         `json:"product_id"`
+	Name              string       `json:"name"`
+	Quantity          int          `json:"quantity"`
+	AvailableQuantity *int         `json:"available_quantity,omitempty"`
+	AddedUnitPrice    types.Money  `json:"added_unit_price"`
+	CurrentUnitPrice  *types.Money `json:"current_unit_price,omitempty"`
+	Issues            []string     `json:"issues"`
 }
 
 // CartSummary provides a quick overview of the cart
 type CartSummary struct {
-	UserID      uint    `json:"user_id"`
-	ItemCount   int     `json:"item_count"`
-	TotalAmount float64 `json:"total_amount"`
-	Currency    string  `json:"currency"`
-	IsEmpty     bool    `json:"is_empty"`
+	UserID        uint           `json:"user_id,omitempty"` // Kosong untuk guest cart
+	ItemCount     int            `json:"item_count"`        // Jumlah unit, untuk badge di header
+	DiscountTotal types.Money    `json:"discount_total"`
+	TaxTotal      types.Money    `json:"tax_total"`
+	TotalAmount   types.Money    `json:"total_amount"` // Setelah diskon dan pajak exclusive
+	Currency      string         `json:"currency"`
+	IsEmpty       bool           `json:"is_empty"`
+	ExchangeRates []ExchangeInfo `json:"exchange_rates,omitempty"`
+
+	// Sisa belanja sampai free shipping, dihitung dari total setelah diskon sebelum pajak.
+	// Kosong jika free shipping tidak aktif.
+	FreeShipping *FreeShippingProgress `json:"free_shipping,omitempty"`
+}
+
+// CartMergeResult adalah hasil penggabungan guest cart ke cart user setelah login
+type CartMergeResult struct {
+	Lines    []CartMergeLine `json:"lines"`
+	Adjusted int             `json:"adjusted"` // Baris yang quantity-nya dikurangi karena stok tidak cukup
+}
+
+// CartMergeLine menjelaskan apa yang terjadi pada satu baris guest cart
+type CartMergeLine struct {
+	ProductID uint   `json:"product_id"`
+	Color     string `json:"color,omitempty"`
+	Size      string `json:"size,omitempty"`
+	Action    string `json:"action"`    // moved, combined atau dropped
+	Requested int    `json:"requested"` // Quantity guest, ditambah quantity user untuk combined
+	Quantity  int    `json:"quantity"`  // Quantity akhir di cart user
+
+	SavedForLater bool `json:"saved_for_later,omitempty"` // Baris dipindah ke daftar saved for later user
 }
 
 // CartItemResponse represents the response for cart item operations
 type CartItemResponse struct {
-	ID          uint      `json:"id"`
-	UserID      uint      `json:"user_id"`
-	ProductID   uint      `json:"product_id"`
-	ProductName string    `json:"product_name,omitempty"`
-	Price       float64   `json:"price,omitempty"`
-	Quantity    int       `json:"quantity"`
-	Subtotal    float64   `json:"subtotal,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID          uint        `json:"id"`
+	UserID      uint        `json:"user_id"`
+	ProductID   uint        `json:"product_id"`
+	ProductName string      `json:"product_name,omitempty"`
+	Price       types.Money `json:"price"`
+	Quantity    int         `json:"quantity"`
+	Subtotal    types.Money `json:"subtotal"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
 }
 
 // BulkCartRequest represents bulk operations on cart items
@@ -83,3 +192,19 @@ type CartListResponse struct {
 	PageSize   int                `json:"page_size"`
 	TotalPages int                `json:"total_pages"`
 }
+
+// CartAbandonmentReport adalah laporan abandoned cart untuk cart dengan aktivitas terakhir dalam periode
+type CartAbandonmentReport struct {
+	From                   time.Time `json:"from"`
+	To                     time.Time `json:"to"`
+	AbandonAfterHours      float64   `json:"abandon_after_hours"`
+	ActiveCarts            int64     `json:"active_carts"`
+	AbandonedCarts         int64     `json:"abandoned_carts"`
+	RemindersSent          int64     `json:"reminders_sent"`
+	RecoveredCarts         int64     `json:"recovered_carts"`
+	RecoveredAfterReminder int64     `json:"recovered_after_reminder"`
+	ClosedCarts            int64     `json:"closed_carts"` // Dikosongkan atau dihapus tanpa kembali ke cart
+	PurgedCarts            int64     `json:"purged_carts"`
+	AbandonmentRate        float64   `json:"abandonment_rate"` // abandoned_carts / active_carts
+	RecoveryRate           float64   `json:"recovery_rate"`    // recovered_carts / abandoned_carts
+}
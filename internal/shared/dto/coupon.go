@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+// CouponRequest digunakan admin untuk membuat atau mengubah kupon. Percent diisi untuk kupon
+// percentage, Amount untuk kupon fixed. MinSpend harus dalam mata uang yang sama dengan Amount.
+type CouponRequest struct {
+	Code         string       `json:"code" validate:"required,min=3,max=50"` // Disimpan dalam huruf besar
+	Description  string       `json:"description" validate:"max=500"`
+	DiscountType string       `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	Percent      int64        `json:"percent,omitempty" validate:"required_if=DiscountType percentage,omitempty,min=1,max=100"`
+	Amount       *types.Money `json:"amount,omitempty" validate:"required_if=DiscountType fixed,omitempty,gt=0"`
+	MinSpend     *types.Money `json:"min_spend,omitempty" validate:"omitempty,gt=0"`
+
+	// Kosong berarti semua produk eligible
+	ProductIDs []uint   `json:"product_ids,omitempty" validate:"omitempty,max=500,dive,min=1"`
+	Categories []string `json:"categories,omitempty" validate:"omitempty,max=100,dive,min=1,max=100"`
+
+	UsageLimit   *int       `json:"usage_limit,omitempty" validate:"omitempty,min=1"`
+	PerUserLimit *int       `json:"per_user_limit,omitempty" validate:"omitempty,min=1"`
+	StartsAt     *time.Time `json:"starts_at,omitempty"` // Default sekarang
+	EndsAt       *time.Time `json:"ends_at,omitempty"`
+	IsActive     *bool      `json:"is_active,omitempty"` // Default true
+}
+
+// CouponResponse adalah representasi kupon untuk admin
+type CouponResponse struct {
+	ID           uint         `json:"id"`
+	Code         string       `json:"code"`
+	Description  string       `json:"description"`
+	DiscountType string       `json:"discount_type"`
+	Percent      int64        `json:"percent,omitempty"`
+	Amount       *types.Money `json:"amount,omitempty"`
+	MinSpend     *types.Money `json:"min_spend,omitempty"`
+	ProductIDs   []uint       `json:"product_ids"`
+	Categories   []string     `json:"categories"`
+	UsageLimit   *int         `json:"usage_limit,omitempty"`
+	PerUserLimit *int         `json:"per_user_limit,omitempty"`
+	Redemptions  int          `json:"redemptions"`
+	StartsAt     time.Time    `json:"starts_at"`
+	EndsAt       *time.Time   `json:"ends_at,omitempty"`
+	IsActive     bool         `json:"is_active"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
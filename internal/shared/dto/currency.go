@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// CurrencyRequest digunakan admin untuk menambah atau mengubah mata uang
+type CurrencyRequest struct {
+	Name              string `json:"name" validate:"required,max=100"`
+	RoundingMode      string `json:"rounding_mode" validate:"omitempty,oneof=half_up half_even up down"`
+	RoundingIncrement int64  `json:"rounding_increment" validate:"omitempty,min=1"`
+	IsActive          *bool  `json:"is_active,omitempty"`
+}
+
+// ExchangeRateRequest menambahkan kurs baru: 1 BaseCurrency = Rate QuoteCurrency
+type ExchangeRateRequest struct {
+	BaseCurrency  string     `json:"base_currency" validate:"required,len=3"`
+	QuoteCurrency string     `json:"quote_currency" validate:"required,len=3,nefield=BaseCurrency"`
+	Rate          string     `json:"rate" validate:"required,numeric"`
+	EffectiveAt   *time.Time `json:"effective_at,omitempty"`
+}
+
+// ExchangeInfo menjelaskan kurs yang dipakai untuk mengonversi sebuah harga
+type ExchangeInfo struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	Rate        string    `json:"rate"`
+	RateID      uint      `json:"rate_id"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
@@ -0,0 +1,8 @@
+package dto
+
+type StockAdjustmentRequest struct {
+	Delta     int    `json:"delta" validate:"required,ne=0"`
+	Reason    string `json:"reason" validate:"required,oneof=restock sale adjustment return"`
+	Reference string `json:"reference" validate:"omitempty,max=128"`
+	Note      string `json:"note" validate:"omitempty,max=500"`
+}
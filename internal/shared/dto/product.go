@@ -1,23 +1,216 @@
 package dto
 
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
 // ProductRequest digunakan saat create atau update product
 type ProductRequest struct {
-	Name        string  `json:"name" validate:"required"`
-	Description string  `json:"description" validate:"required"`
-	Quantity    int     `json:"quantity" validate:"required,min=1"`
-	Price       float64 `json:"price" validate:"required,min=0"`
-	Color       string  `json:"color" validate:"required"`
-	Size        string  `json:"size" validate:"required"`
+	SKU         string      `json:"sku,omitempty" validate:"omitempty,max=64"`
+	Name        string      `json:"name" validate:"required"`
+	Description string      `json:"description" validate:"required"`
+	Quantity    int         `json:"quantity" validate:"required_unless=Type bundle,min=0"` // Untuk bundle hanya 0 atau stok turunan dari GET
+	Price       types.Money `json:"price" validate:"required,min=0"`                       // Harga normal, sama dengan regular_price pada response
+	Color       string      `json:"color" validate:"required"`
+	Size        string      `json:"size" validate:"required"`
+
+	// Type default-nya simple dan tidak bisa diubah setelah produk dibuat.
+	// Components wajib untuk bundle; pada update, nil berarti komponen tidak berubah.
+	Type       string                   `json:"type,omitempty" validate:"omitempty,oneof=simple bundle"`
+	Components []BundleComponentRequest `json:"components,omitempty" validate:"omitempty,max=50,dive"`
+
+	ReorderPoint *int `json:"reorder_point,omitempty" validate:"omitempty,min=0"` // Alert dikirim saat stok turun ke angka ini
+	MaxPerOrder  *int `json:"max_per_order,omitempty" validate:"omitempty,min=1"` // Batas quantity produk ini dalam satu cart
+
+	Category *string `json:"category,omitempty" validate:"omitempty,min=1,max=100"`
+	TaxClass string  `json:"tax_class,omitempty" validate:"omitempty,max=50"` // Default standard
+
+	// Berat (gram) dan dimensi kemasan (mm) untuk ongkos kirim
+	WeightGrams *int `json:"weight_grams,omitempty" validate:"omitempty,min=0"`
+	LengthMM    *int `json:"length_mm,omitempty" validate:"omitempty,min=1"`
+	WidthMM     *int `json:"width_mm,omitempty" validate:"omitempty,min=1"`
+	HeightMM    *int `json:"height_mm,omitempty" validate:"omitempty,min=1"`
+
+	Attributes types.Attributes `json:"attributes,omitempty"` // Divalidasi terhadap skema attribute_definitions
+}
+
+// BundleComponentRequest adalah satu komponen bundle beserta jumlahnya per bundle
+type BundleComponentRequest struct {
+	ProductID uint `json:"product_id" validate:"required,min=1"`
+	Quantity  int  `json:"quantity" validate:"required,min=1,max=999"`
+}
+
+// BundleComponentResponse adalah komponen bundle pada response produk
+type BundleComponentResponse struct {
+	ProductID uint        `json:"product_id"`
+	SKU       string      `json:"sku,omitempty"`
+	Name      string      `json:"name"`
+	Quantity  int         `json:"quantity"`
+	Price     types.Money `json:"price"` // Harga normal satu unit komponen
 }
 
 // ProductResponse adalah format response ke client
 type ProductResponse struct {
-	ID          uint    `json:"id"`
-	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Quantity    int     `json:"quantity"`
-	Price       float64 `json:"price"`
-	Color       string  `json:"color"`
-	Size        string  `json:"size"`
-	CreatedAt   string  `json:"created_at"`
+	ID           uint        `json:"id"`
+	SKU          string      `json:"sku,omitempty"`
+	Slug         string      `json:"slug"`
+	Name         string      `json:"name"`
+	Description  string      `json:"description"`
+	Quantity     int         `json:"quantity"`      // Untuk bundle: jumlah bundle yang bisa dirakit dari stok komponen
+	Price        types.Money `json:"price"`         // Harga yang berlaku sekarang (sale jika ada)
+	RegularPrice types.Money `json:"regular_price"` // Harga normal; kirim nilai ini sebagai price pada PUT
+	Color        string      `json:"color"`
+	Size         string      `json:"size"`
+	CreatedAt    string      `json:"created_at"`
+
+	Type       string                    `json:"type"`
+	Components []BundleComponentResponse `json:"components,omitempty"`
+
+	CompareAtPrice *types.Money `json:"compare_at_price,omitempty"` // Harga asli saat produk sedang sale
+	SaleEndsAt     *time.Time   `json:"sale_ends_at,omitempty"`
+
+	Version      int  `json:"version"` // Sama dengan header ETag
+	ReorderPoint *int `json:"reorder_point,omitempty"`
+	LowStock     bool `json:"low_stock"`
+	MaxPerOrder  *int `json:"max_per_order,omitempty"`
+
+	Category *string `json:"category,omitempty"`
+	TaxClass string  `json:"tax_class"`
+
+	WeightGrams *int `json:"weight_grams,omitempty"`
+	LengthMM    *int `json:"length_mm,omitempty"`
+	WidthMM     *int `json:"width_mm,omitempty"`
+	HeightMM    *int `json:"height_mm,omitempty"`
+
+	Attributes types.Attributes `json:"attributes"`
+
+	AverageRating float64 `json:"average_rating"`
+	ReviewCount   int     `json:"review_count"`
+
+	Exchange *ExchangeInfo `json:"exchange,omitempty"` // Terisi jika harga dikonversi ke mata uang lain
+}
+
+// ProductPatchRequest adalah body PATCH dengan semantik JSON Merge Patch (RFC 7396):
+// field yang tidak dikirim tidak berubah, field bernilai null dihapus (hanya untuk field nullable).
+type ProductPatchRequest struct {
+	SKU          *string      `json:"sku" validate:"omitempty,max=64"`
+	Name         *string      `json:"name" validate:"omitempty,min=1"`
+	Description  *string      `json:"description" validate:"omitempty,min=1"`
+	Quantity     *int         `json:"quantity" validate:"omitempty,min=0"`
+	Price        *types.Money `json:"price" validate:"omitempty,min=0"`
+	Color        *string      `json:"color" validate:"omitempty,min=1"`
+	Size         *string      `json:"size" validate:"omitempty,min=1"`
+	ReorderPoint *int         `json:"reorder_point" validate:"omitempty,min=0"`
+	MaxPerOrder  *int         `json:"max_per_order" validate:"omitempty,min=1"`
+	Category     *string      `json:"category" validate:"omitempty,min=1,max=100"`
+	TaxClass     *string      `json:"tax_class" validate:"omitempty,min=1,max=50"`
+	WeightGrams  *int         `json:"weight_grams" validate:"omitempty,min=0"`
+	LengthMM     *int         `json:"length_mm" validate:"omitempty,min=1"`
+	WidthMM      *int         `json:"width_mm" validate:"omitempty,min=1"`
+	HeightMM     *int         `json:"height_mm" validate:"omitempty,min=1"`
+
+	// Di-merge ke atribut yang ada; atribut bernilai null dihapus
+	Attributes map[string]any `json:"attributes"`
+
+	Nulls map[string]bool `json:"-"` // Field yang dikirim dengan nilai null
+}
+
+// productPatchFields adalah field yang boleh dikirim pada PATCH
+var productPatchFields = map[string]bool{
+	"sku": true, "name": true, "description": true, "quantity": true,
+	"price": true, "color": true, "size": true, "reorder_point": true,
+	"max_per_order": true, "category": true, "tax_class": true, "attributes": true,
+	"weight_grams": true, "length_mm": true, "width_mm": true, "height_mm": true,
+}
+
+// UnmarshalJSON membedakan field yang tidak dikirim dengan field bernilai null,
+// dan menolak field yang tidak dikenal
+func (p *ProductPatchRequest) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	p.Nulls = make(map[string]bool)
+	for key, value := range raw {
+		if !productPatchFields[key] {
+			return fmt.Errorf("unknown field %q", key)
+		}
+		if string(bytes.TrimSpace(value)) == "null" {
+			p.Nulls[key] = true
+		}
+	}
+
+	type alias ProductPatchRequest
+	return json.Unmarshal(data, (*alias)(p))
+}
+
+// IsEmpty bernilai true jika patch tidak mengubah apa pun
+func (p *ProductPatchRequest) IsEmpty() bool {
+	return p.SKU == nil && p.Name == nil && p.Description == nil && p.Quantity == nil &&
+		p.Price == nil && p.Color == nil && p.Size == nil && p.ReorderPoint == nil && p.MaxPerOrder == nil && p.Category == nil && p.TaxClass == nil &&
+		p.WeightGrams == nil && p.LengthMM == nil && p.WidthMM == nil && p.HeightMM == nil && p.Attributes == nil && len(p.Nulls) == 0
+}
+
+// ProductFilter adalah filter listing produk dari query string
+type ProductFilter struct {
+	MinRating float64 `schema:"min_rating" validate:"omitempty,min=0,max=5"`
+	Category  string  `schema:"category" validate:"omitempty,max=100"`
+
+	Attributes map[string][]string `schema:"-"` // Dari parameter attr.<code>=<value>
+}
+
+// AttributeFilterPrefix adalah prefix parameter filter atribut, misalnya ?attr.material=cotton
+const AttributeFilterPrefix = "attr."
+
+// ProductSaleRequest digunakan untuk menjadwalkan harga sale pada rentang waktu tertentu.
+// CompareAtPrice opsional, default-nya harga normal produk saat sale dijadwalkan.
+type ProductSaleRequest struct {
+	Price          types.Money  `json:"price" validate:"required,gt=0"`
+	CompareAtPrice *types.Money `json:"compare_at_price,omitempty" validate:"omitempty,gt=0"`
+	ValidFrom      time.Time    `json:"valid_from" validate:"required"`
+	ValidTo        time.Time    `json:"valid_to" validate:"required,gtfield=ValidFrom"`
+}
+
+// ProductPriceResponse adalah satu baris riwayat harga produk
+type ProductPriceResponse struct {
+	ID             uint         `json:"id"`
+	ProductID      uint         `json:"product_id"`
+	Kind           string       `json:"kind"`
+	Price          types.Money  `json:"price"`
+	CompareAtPrice *types.Money `json:"compare_at_price,omitempty"`
+	ValidFrom      time.Time    `json:"valid_from"`
+	ValidTo        *time.Time   `json:"valid_to,omitempty"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+// ProductImportItem adalah satu baris pada file import (CSV atau JSONL).
+// ID bersifat opsional; jika kosong, baris di-upsert berdasarkan SKU.
+type ProductImportItem struct {
+	ID uint `json:"id,omitempty"`
+	ProductRequest
+}
+
+// ProductImportRowResult adalah hasil proses satu baris import
+type ProductImportRowResult struct {
+	Row       int      `json:"row"`
+	ProductID uint     `json:"product_id,omitempty"`
+	SKU       string   `json:"sku,omitempty"`
+	Action    string   `json:"action"`
+	Errors    []string `json:"errors,omitempty"`
+}
+
+// ProductImportReport merangkum hasil import, termasuk laporan per baris
+type ProductImportReport struct {
+	DryRun  bool                     `json:"dry_run"`
+	Total   int                      `json:"total"`
+	Created int                      `json:"created"`
+	Updated int                      `json:"updated"`
+	Failed  int                      `json:"failed"`
+	Rows    []ProductImportRowResult `json:"rows"`
 }
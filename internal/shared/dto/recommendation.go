@@ -0,0 +1,13 @@
+package dto
+
+import "go-fiber-api/internal/shared/types"
+
+// RecommendationResponse adalah satu produk "frequently bought together"
+type RecommendationResponse struct {
+	ProductID uint        `json:"product_id"`
+	Slug      string      `json:"slug"`
+	Name      string      `json:"name"`
+	Price     types.Money `json:"price"`
+	PairCount int         `json:"pair_count"`
+	Score     float64     `json:"score"`
+}
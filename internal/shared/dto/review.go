@@ -0,0 +1,14 @@
+package dto
+
+// ReviewRequest digunakan user untuk menulis review produk
+type ReviewRequest struct {
+	Rating int    `json:"rating" validate:"required,min=1,max=5"`
+	Title  string `json:"title" validate:"required,max=150"`
+	Body   string `json:"body" validate:"required,max=5000"`
+}
+
+// ReviewModerationRequest digunakan admin untuk approve/reject review
+type ReviewModerationRequest struct {
+	Status string `json:"status" validate:"required,oneof=pending approved rejected"`
+	Note   string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
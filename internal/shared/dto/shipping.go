@@ -0,0 +1,66 @@
+package dto
+
+import "go-fiber-api/internal/shared/types"
+
+// ShippingZoneRequest menambah atau mengubah zona pengiriman. Rentang kode pos kosong berarti
+// seluruh negara; jika diisi, postal_from dan postal_to harus sama panjang.
+type ShippingZoneRequest struct {
+	Name       string `json:"name" validate:"required,max=100"`
+	Country    string `json:"country" validate:"required,len=2,alpha"`
+	PostalFrom string `json:"postal_from,omitempty" validate:"omitempty,max=20,required_with=PostalTo"`
+	PostalTo   string `json:"postal_to,omitempty" validate:"omitempty,max=20,required_with=PostalFrom"`
+	IsActive   *bool  `json:"is_active,omitempty"`
+}
+
+// ShippingMethodRequest menambah atau mengubah metode pengiriman beserta seluruh tabel tarifnya.
+// Semua tarif harus memakai mata uang yang sama.
+type ShippingMethodRequest struct {
+	Code         string                `json:"code" validate:"required,max=50"`
+	Name         string                `json:"name" validate:"required,max=100"`
+	MinDays      int                   `json:"min_days" validate:"min=0"`
+	MaxDays      int                   `json:"max_days" validate:"gtefield=MinDays"`
+	FreeShipping bool                  `json:"free_shipping"`
+	IsActive     *bool                 `json:"is_active,omitempty"`
+	Rates        []ShippingRateRequest `json:"rates" validate:"required,min=1,max=50,dive"`
+}
+
+// ShippingRateRequest adalah tarif untuk paket dengan berat sampai MaxWeightGrams
+type ShippingRateRequest struct {
+	MaxWeightGrams int         `json:"max_weight_grams" validate:"required,min=1"`
+	Price          types.Money `json:"price" validate:"min=0"`
+}
+
+// ShippingQuoteRequest adalah alamat tujuan untuk menghitung ongkos kirim cart
+type ShippingQuoteRequest struct {
+	Country    string `json:"country" validate:"required,len=2,alpha"`
+	PostalCode string `json:"postal_code" validate:"required,max=20"`
+}
+
+// ShippingQuoteResponse berisi metode pengiriman yang tersedia untuk isi cart ke alamat tujuan
+type ShippingQuoteResponse struct {
+	Country      string                `json:"country"`
+	PostalCode   string                `json:"postal_code"`
+	Zone         string                `json:"zone"`
+	WeightGrams  int                   `json:"weight_grams"` // Berat yang ditagih, termasuk berat volumetrik
+	Methods      []ShippingMethodQuote `json:"methods"`
+	FreeShipping *FreeShippingProgress `json:"free_shipping,omitempty"`
+
+	ExchangeRates []ExchangeInfo `json:"exchange_rates,omitempty"` // Terisi jika harga dikonversi
+}
+
+// ShippingMethodQuote adalah harga dan estimasi satu metode pengiriman
+type ShippingMethodQuote struct {
+	Code    string      `json:"code"`
+	Name    string      `json:"name"`
+	Price   types.Money `json:"price"`
+	Free    bool        `json:"free"` // Gratis karena ambang free shipping tercapai
+	MinDays int         `json:"min_days"`
+	MaxDays int         `json:"max_days"`
+}
+
+// FreeShippingProgress adalah sisa belanja sampai cart mendapat free shipping
+type FreeShippingProgress struct {
+	Threshold types.Money `json:"threshold"`
+	Remaining types.Money `json:"remaining"`
+	Qualified bool        `json:"qualified"`
+}
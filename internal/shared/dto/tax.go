@@ -0,0 +1,18 @@
+package dto
+
+// TaxClassRequest digunakan admin untuk menambah atau mengubah kelas pajak
+type TaxClassRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// TaxRuleRequest menambah atau mengubah tarif pajak satu kelas di satu wilayah.
+// Rate dalam persen, mis. "11" untuk PPN 11%. Region kosong berarti seluruh negara.
+type TaxRuleRequest struct {
+	TaxClass  string `json:"tax_class" validate:"required,max=50"`
+	Country   string `json:"country" validate:"required,len=2,alpha"`
+	Region    string `json:"region,omitempty" validate:"omitempty,max=50"`
+	Name      string `json:"name" validate:"required,max=100"`
+	Rate      string `json:"rate" validate:"required,numeric"`
+	Inclusive bool   `json:"inclusive"`
+	IsActive  *bool  `json:"is_active,omitempty"`
+}
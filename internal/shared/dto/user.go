@@ -10,8 +10,9 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token string       `json:"token"`
-	User  UserResponse `json:"user"`
+	Token     string           `json:"token"`
+	User      UserResponse     `json:"user"`
+	CartMerge *CartMergeResult `json:"cart_merge,omitempty"` // Terisi jika login membawa X-Cart-Token
 }
 
 type RegisterRequest struct {
@@ -34,6 +35,7 @@ type UserResponse struct {
 
 type JWTClaims struct {
 	ID        string `json:"id"`
+	UserID    uint   `json:"user_id,omitempty"`
 	Username  string `json:"username,omitempty"`
 	SessionId string `json:"session_id,omitempty"`
 	Role      int    `json:"role"`
@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"go-fiber-api/internal/shared/types"
+)
+
+type WishlistRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// WishlistShareRequest mengaktifkan atau mematikan link publik wishlist
+type WishlistShareRequest struct {
+	Public bool `json:"public"`
+}
+
+type WishlistItemRequest struct {
+	ProductID uint   `json:"product_id" validate:"required,min=1"`
+	Color     string `json:"color,omitempty" validate:"omitempty,max=50"`
+	Size      string `json:"size,omitempty" validate:"omitempty,max=50"`
+	Note      string `json:"note,omitempty" validate:"omitempty,max=500"`
+}
+
+// WishlistMoveRequest memindahkan item wishlist ke cart; quantity default 1
+type WishlistMoveRequest struct {
+	Quantity int `json:"quantity,omitempty" validate:"omitempty,min=1,max=999"`
+}
+
+type WishlistItemResponse struct {
+	ID          uint        `json:"id"`
+	ProductID   uint        `json:"product_id"`
+	ProductName string      `json:"product_name"`
+	Slug        string      `json:"slug"`
+	Color       string      `json:"color,omitempty"`
+	Size        string      `json:"size,omitempty"`
+	Note        *string     `json:"note,omitempty"`
+	Price       types.Money `json:"price"`
+	InStock     bool        `json:"in_stock"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+type WishlistResponse struct {
+	ID         uint                   `json:"id"`
+	Name       string                 `json:"name"`
+	ShareToken *string                `json:"share_token,omitempty"`
+	ItemCount  int                    `json:"item_count"`
+	Items      []WishlistItemResponse `json:"items,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+}
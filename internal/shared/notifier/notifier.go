@@ -0,0 +1,84 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notification adalah pesan yang dikirim ke admin atau user, misalnya alert stok menipis
+type Notification struct {
+	Type      string         `json:"type"`
+	Subject   string         `json:"subject"`
+	Message   string         `json:"message"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// NewFromEnv memilih notifier dari NOTIFIER ("log" atau "file").
+// Untuk "file", path diambil dari NOTIFIER_FILE (default notifications.jsonl).
+func NewFromEnv() Notifier {
+	switch os.Getenv("NOTIFIER") {
+	case "file":
+		path := os.Getenv("NOTIFIER_FILE")
+		if path == "" {
+			path = "notifications.jsonl"
+		}
+		return NewFileNotifier(path)
+	default:
+		return NewLogNotifier()
+	}
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier menulis notifikasi ke log aplikasi
+func NewLogNotifier() Notifier {
+	return &logNotifier{}
+}
+
+func (l *logNotifier) Notify(ctx context.Context, n Notification) error {
+	slog.Warn("Notification", "type", n.Type, "subject", n.Subject, "message", n.Message, "data", n.Data)
+	return nil
+}
+
+type fileNotifier struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier menambahkan notifikasi sebagai satu baris JSON per notifikasi ke file
+func NewFileNotifier(path string) Notifier {
+	return &fileNotifier{path: path}
+}
+
+func (f *fileNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.CreatedAt.IsZero() {
+		n.CreatedAt = time.Now()
+	}
+
+	line, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open notification file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Attributes adalah nilai atribut kustom produk, disimpan sebagai kolom JSONB
+type Attributes map[string]any
+
+// Value menulis atribut sebagai JSON; nil ditulis sebagai objek kosong
+func (a Attributes) Value() (driver.Value, error) {
+	if a == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(a)
+}
+
+// Scan membaca kolom JSONB
+func (a *Attributes) Scan(src any) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*a = Attributes{}
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Attributes", src)
+	}
+
+	attrs := Attributes{}
+	if err := json.Unmarshal(data, &attrs); err != nil {
+		return err
+	}
+	*a = attrs
+	return nil
+}
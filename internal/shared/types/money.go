@@ -0,0 +1,305 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// FallbackCurrency dipakai jika env DEFAULT_CURRENCY tidak di-set
+const FallbackCurrency = "IDR"
+
+// currencyExponents adalah jumlah digit minor unit per mata uang (ISO 4217)
+var currencyExponents = map[string]int{
+	"IDR": 2,
+	"USD": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"AUD": 2,
+	"CNY": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"KWD": 3,
+}
+
+// ErrCurrencyMismatch dikembalikan saat operasi aritmatika memakai dua mata uang berbeda
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// DefaultCurrency mengembalikan mata uang default toko (env DEFAULT_CURRENCY)
+func DefaultCurrency() string {
+	if c := strings.ToUpper(strings.TrimSpace(os.Getenv("DEFAULT_CURRENCY"))); c != "" {
+		return c
+	}
+	return FallbackCurrency
+}
+
+// CurrencyExponent mengembalikan jumlah digit minor unit untuk kode mata uang ISO
+func CurrencyExponent(currency string) (int, error) {
+	exp, ok := currencyExponents[strings.ToUpper(currency)]
+	if !ok {
+		return 0, fmt.Errorf("unsupported currency %q", currency)
+	}
+	return exp, nil
+}
+
+// Money adalah nilai uang dalam minor unit (mis. sen) beserta kode mata uang ISO 4217.
+// Semua perhitungan harga memakai integer agar tidak ada selisih pembulatan float.
+//
+// Di JSON, Money ditulis sebagai {"amount":"19.99","currency":"USD","minor_units":1999}.
+// Saat decode, amount boleh berupa string atau number desimal, atau cukup minor_units;
+// nilai tanpa object (mis. "price": 19.99) dianggap dalam DefaultCurrency.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+// NewMoney membuat Money dari minor unit
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// ParseMoney mem-parse nilai desimal (mis. "19.99") ke minor unit mata uang yang diberikan.
+// Hanya satu tanda + atau - di depan yang diterima. Digit di belakang koma yang melebihi
+// presisi mata uang ditolak, bukan dibulatkan diam-diam.
+func ParseMoney(value, currency string) (Money, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		currency = DefaultCurrency()
+	}
+	exp, err := CurrencyExponent(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	value = strings.TrimSpace(value)
+	unsigned := value
+	negative := false
+	if len(unsigned) > 0 && (unsigned[0] == '-' || unsigned[0] == '+') {
+		negative = unsigned[0] == '-'
+		unsigned = unsigned[1:]
+	}
+
+	// Sisa nilai hanya boleh berisi digit, sehingga tanda kedua (mis. "--5") ikut ditolak
+	whole, frac, _ := strings.Cut(unsigned, ".")
+	if (whole == "" && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(frac) > exp {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimal places for %s", value, exp, currency)
+	}
+	frac += strings.Repeat("0", exp-len(frac))
+
+	digits := whole + frac
+	if digits == "" {
+		digits = "0"
+	}
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("invalid amount %q", value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Decimal mengembalikan nilai dalam bentuk desimal, mis. "19.99"
+func (m Money) Decimal() string {
+	exp, err := CurrencyExponent(m.Currency)
+	if err != nil || exp == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	sign := ""
+	amount := m.Amount
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	digits := fmt.Sprintf("%0*d", exp+1, amount)
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Currency + " " + m.Decimal()
+}
+
+// IsZero bernilai true jika amount nol
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add menjumlahkan dua nilai dengan mata uang yang sama
+func (m Money) Add(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub mengurangi dua nilai dengan mata uang yang sama
+func (m Money) Sub(other Money) (Money, error) {
+	if m.Currency != other.Currency {
+		return Money{}, fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}, nil
+}
+
+// Mul mengalikan dengan bilangan bulat (mis. harga satuan x quantity), selalu eksak
+func (m Money) Mul(quantity int) Money {
+	return Money{Amount: m.Amount * int64(quantity), Currency: m.Currency}
+}
+
+// MulRat mengalikan dengan bilangan rasional (persentase, tarif pajak, kurs) lalu membulatkan
+// ke minor unit terdekat dengan aturan half away from zero.
+func (m Money) MulRat(r *big.Rat) Money {
+	product := new(big.Rat).Mul(new(big.Rat).SetInt64(m.Amount), r)
+	return Money{Amount: RoundRat(product), Currency: m.Currency}
+}
+
+// RoundRat membulatkan bilangan rasional ke integer terdekat (half away from zero)
+func RoundRat(r *big.Rat) int64 {
+	return RoundRatTo(r, RoundHalfUp, 1)
+}
+
+// RoundingMode menentukan cara pembulatan hasil konversi ke minor unit
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"   // setengah ke atas (menjauhi nol)
+	RoundHalfEven RoundingMode = "half_even" // banker's rounding
+	RoundUp       RoundingMode = "up"        // selalu menjauhi nol
+	RoundDown     RoundingMode = "down"      // selalu mendekati nol (truncate)
+)
+
+// Valid bernilai true jika mode pembulatan dikenali
+func (m RoundingMode) Valid() bool {
+	switch m {
+	case RoundHalfUp, RoundHalfEven, RoundUp, RoundDown:
+		return true
+	}
+	return false
+}
+
+// RoundRatTo membulatkan bilangan rasional ke kelipatan increment terdekat sesuai mode.
+// Increment 1 berarti pembulatan ke minor unit, 100 berarti ke satuan utuh untuk mata uang 2 desimal.
+func RoundRatTo(r *big.Rat, mode RoundingMode, increment int64) int64 {
+	if increment < 1 {
+		increment = 1
+	}
+	inc := big.NewInt(increment)
+
+	// Bagi dengan increment, bulatkan ke integer, lalu kalikan kembali
+	scaled := new(big.Rat).Quo(r, new(big.Rat).SetInt(inc))
+	num := new(big.Int).Set(scaled.Num())
+	den := scaled.Denom()
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	if rem.Sign() != 0 {
+		twice := new(big.Int).Mul(rem, big.NewInt(2))
+		cmp := twice.Cmp(den)
+		switch mode {
+		case RoundUp:
+			quo.Add(quo, big.NewInt(1))
+		case RoundDown:
+		case RoundHalfEven:
+			if cmp > 0 || (cmp == 0 && quo.Bit(0) == 1) {
+				quo.Add(quo, big.NewInt(1))
+			}
+		default:
+			if cmp >= 0 {
+				quo.Add(quo, big.NewInt(1))
+			}
+		}
+	}
+	if negative {
+		quo.Neg(quo)
+	}
+	return quo.Mul(quo, inc).Int64()
+}
+
+type moneyJSON struct {
+	Amount     string `json:"amount"`
+	Currency   string `json:"currency"`
+	MinorUnits int64  `json:"minor_units"`
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{
+		Amount:     m.Decimal(),
+		Currency:   m.Currency,
+		MinorUnits: m.Amount,
+	})
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	// Bentuk singkat: angka atau string desimal dalam mata uang default
+	if len(data) > 0 && data[0] != '{' {
+		parsed, err := ParseMoney(strings.Trim(string(data), `"`), "")
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	}
+
+	var raw struct {
+		Amount     json.RawMessage `json:"amount"`
+		Currency   string          `json:"currency"`
+		MinorUnits *int64          `json:"minor_units"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(raw.Currency))
+	if currency == "" {
+		currency = DefaultCurrency()
+	}
+	if _, err := CurrencyExponent(currency); err != nil {
+		return err
+	}
+
+	if len(raw.Amount) > 0 && !bytes.Equal(raw.Amount, []byte("null")) {
+		parsed, err := ParseMoney(strings.Trim(string(raw.Amount), `"`), currency)
+		if err != nil {
+			return err
+		}
+		if raw.MinorUnits != nil && *raw.MinorUnits != parsed.Amount {
+			return fmt.Errorf("amount %s and minor_units %d do not match", parsed.Decimal(), *raw.MinorUnits)
+		}
+		*m = parsed
+		return nil
+	}
+
+	if raw.MinorUnits == nil {
+		return errors.New("money requires amount or minor_units")
+	}
+	*m = Money{Amount: *raw.MinorUnits, Currency: currency}
+	return nil
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value    string
+		currency string
+		want     int64
+		wantErr  bool
+	}{
+		{value: "19.99", currency: "USD", want: 1999},
+		{value: "19.9", currency: "USD", want: 1990},
+		{value: "19", currency: "USD", want: 1900},
+		{value: ".5", currency: "USD", want: 50},
+		{value: "5.", currency: "USD", want: 500},
+		{value: " 7.25 ", currency: "usd", want: 725},
+		{value: "-5", currency: "USD", want: -500},
+		{value: "+5", currency: "USD", want: 500},
+		{value: "1500", currency: "JPY", want: 1500},
+		{value: "1.234", currency: "KWD", want: 1234},
+		{value: "100000000", currency: "IDR", want: 10_000_000_000},
+
+		{value: "--5", currency: "USD", wantErr: true},
+		{value: "+-5", currency: "USD", wantErr: true},
+		{value: "-+5", currency: "USD", wantErr: true},
+		{value: "++5", currency: "USD", wantErr: true},
+		{value: "5.-1", currency: "USD", wantErr: true},
+		{value: "-", currency: "USD", wantErr: true},
+		{value: "", currency: "USD", wantErr: true},
+		{value: ".", currency: "USD", wantErr: true},
+		{value: "1.2.3", currency: "USD", wantErr: true},
+		{value: "1e3", currency: "USD", wantErr: true},
+		{value: "abc", currency: "USD", wantErr: true},
+		{value: "19.999", currency: "USD", wantErr: true},
+		{value: "1.5", currency: "JPY", wantErr: true},
+		{value: "10", currency: "XXX", wantErr: true},
+		{value: "99999999999999999999", currency: "USD", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency+" "+tt.value, func(t *testing.T) {
+			got, err := ParseMoney(tt.value, tt.currency)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %d", got.Amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Amount != tt.want {
+				t.Errorf("got %d, want %d", got.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundRatTo(t *testing.T) {
+	tests := []struct {
+		name      string
+		num, den  int64
+		mode      RoundingMode
+		increment int64
+		want      int64
+	}{
+		{name: "half_up below half", num: 124, den: 100, mode: RoundHalfUp, increment: 1, want: 1},
+		{name: "half_up at half", num: 5, den: 2, mode: RoundHalfUp, increment: 1, want: 3},
+		{name: "half_up negative at half", num: -5, den: 2, mode: RoundHalfUp, increment: 1, want: -3},
+		{name: "half_even rounds to even down", num: 5, den: 2, mode: RoundHalfEven, increment: 1, want: 2},
+		{name: "half_even rounds to even up", num: 7, den: 2, mode: RoundHalfEven, increment: 1, want: 4},
+		{name: "half_even above half", num: 251, den: 100, mode: RoundHalfEven, increment: 1, want: 3},
+		{name: "half_even negative", num: -5, den: 2, mode: RoundHalfEven, increment: 1, want: -2},
+		{name: "up", num: 201, den: 100, mode: RoundUp, increment: 1, want: 3},
+		{name: "up negative", num: -201, den: 100, mode: RoundUp, increment: 1, want: -3},
+		{name: "down", num: 299, den: 100, mode: RoundDown, increment: 1, want: 2},
+		{name: "down negative", num: -299, den: 100, mode: RoundDown, increment: 1, want: -2},
+		{name: "exact value is unchanged", num: 300, den: 1, mode: RoundUp, increment: 1, want: 300},
+		{name: "increment 100 half_up", num: 12350, den: 1, mode: RoundHalfUp, increment: 100, want: 12400},
+		{name: "increment 100 down", num: 12399, den: 1, mode: RoundDown, increment: 100, want: 12300},
+		{name: "increment 100 up", num: 12301, den: 1, mode: RoundUp, increment: 100, want: 12400},
+		{name: "increment 50 half_even", num: 125, den: 1, mode: RoundHalfEven, increment: 50, want: 100},
+		{name: "increment below 1 is treated as 1", num: 5, den: 2, mode: RoundHalfUp, increment: 0, want: 3},
+		{name: "unknown mode falls back to half_up", num: 5, den: 2, mode: "", increment: 1, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RoundRatTo(big.NewRat(tt.num, tt.den), tt.mode, tt.increment)
+			if got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
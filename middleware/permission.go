@@ -46,3 +46,16 @@ func AuthMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
+
+// OptionalAuthMiddleware seperti AuthMiddleware, tapi request tanpa header Authorization tetap
+// diteruskan tanpa user_id (misalnya untuk guest cart). Token yang ada tapi tidak valid tetap ditolak.
+func OptionalAuthMiddleware(next http.Handler) http.Handler {
+	auth := AuthMiddleware(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		auth.ServeHTTP(w, r)
+	})
+}
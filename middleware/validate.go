@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"go-fiber-api/internal/shared/types"
 	utils "go-fiber-api/utils/jwt"
 	"go-fiber-api/utils/web"
@@ -29,6 +30,9 @@ func ValidateRole(requiredRole ...types.Roles) func(http.HandlerFunc) http.Handl
 
 			slog.Info("Request info", "path", r.URL.Path, "method", r.Method, "userID", claims.ID, "role", claims.Role)
 
+			// Sisipkan user_id ke context agar handler admin tahu siapa pelakunya
+			r = r.WithContext(context.WithValue(r.Context(), "user_id", claims.UserID))
+
 			// Role 0 (misal: Super Admin) bypass semua
 			if claims.Role == 0 {
 				next.ServeHTTP(w, r)
@@ -0,0 +1,20 @@
+CREATE TABLE product_prices (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  kind TEXT NOT NULL DEFAULT 'regular',
+  price DOUBLE PRECISION NOT NULL,
+  compare_at_price DOUBLE PRECISION,
+  valid_from TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  valid_to TIMESTAMPTZ,
+  CONSTRAINT product_prices_kind_check CHECK (kind IN ('regular', 'sale')),
+  CONSTRAINT product_prices_validity_check CHECK (valid_to IS NULL OR valid_to > valid_from)
+);
+
+CREATE INDEX product_prices_product_id_valid_from_idx ON product_prices (product_id, valid_from DESC);
+
+-- Harga yang ada sekarang menjadi baris pertama di riwayat
+INSERT INTO product_prices (product_id, kind, price, valid_from)
+SELECT id, 'regular', price, COALESCE(created_at, NOW())
+FROM products
+WHERE price IS NOT NULL;
@@ -0,0 +1,9 @@
+ALTER TABLE cart_items DROP COLUMN IF EXISTS currency;
+ALTER TABLE cart_items ALTER COLUMN price TYPE DOUBLE PRECISION USING price / 100.0;
+
+ALTER TABLE product_prices DROP COLUMN IF EXISTS currency;
+ALTER TABLE product_prices ALTER COLUMN compare_at_price TYPE DOUBLE PRECISION USING compare_at_price / 100.0;
+ALTER TABLE product_prices ALTER COLUMN price TYPE DOUBLE PRECISION USING price / 100.0;
+
+ALTER TABLE products DROP COLUMN IF EXISTS currency;
+ALTER TABLE products ALTER COLUMN price TYPE DOUBLE PRECISION USING price / 100.0;
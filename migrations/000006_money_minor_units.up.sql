@@ -0,0 +1,11 @@
+-- Harga disimpan dalam minor unit (sen) beserta kode mata uang ISO 4217.
+-- Data lama diasumsikan dalam IDR dengan 2 digit desimal.
+ALTER TABLE products ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT;
+ALTER TABLE products ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE product_prices ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT;
+ALTER TABLE product_prices ALTER COLUMN compare_at_price TYPE BIGINT USING ROUND(compare_at_price * 100)::BIGINT;
+ALTER TABLE product_prices ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
+
+ALTER TABLE cart_items ALTER COLUMN price TYPE BIGINT USING ROUND(price * 100)::BIGINT;
+ALTER TABLE cart_items ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR';
@@ -0,0 +1,31 @@
+CREATE TABLE currencies (
+  code CHAR(3) PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  name TEXT NOT NULL,
+  exponent INT NOT NULL DEFAULT 2,
+  rounding_mode TEXT NOT NULL DEFAULT 'half_up',
+  rounding_increment BIGINT NOT NULL DEFAULT 1,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  CONSTRAINT currencies_rounding_mode_check CHECK (rounding_mode IN ('half_up', 'half_even', 'up', 'down')),
+  CONSTRAINT currencies_rounding_increment_check CHECK (rounding_increment >= 1)
+);
+
+-- Kurs bersifat append-only: kurs baru ditambahkan, kurs yang dipakai adalah yang effective_at terbaru
+CREATE TABLE exchange_rates (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  base_currency CHAR(3) NOT NULL REFERENCES currencies (code),
+  quote_currency CHAR(3) NOT NULL REFERENCES currencies (code),
+  rate NUMERIC(24, 12) NOT NULL,
+  effective_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  CONSTRAINT exchange_rates_rate_check CHECK (rate > 0),
+  CONSTRAINT exchange_rates_pair_check CHECK (base_currency <> quote_currency)
+);
+
+CREATE INDEX exchange_rates_pair_effective_at_idx ON exchange_rates (base_currency, quote_currency, effective_at DESC);
+
+INSERT INTO currencies (code, name, exponent, rounding_mode, rounding_increment) VALUES
+  ('IDR', 'Indonesian Rupiah', 2, 'half_up', 100),
+  ('USD', 'US Dollar', 2, 'half_up', 1),
+  ('SGD', 'Singapore Dollar', 2, 'half_up', 1);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS products_rating_average_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS review_count;
+ALTER TABLE products DROP COLUMN IF EXISTS rating_average;
+
+DROP TABLE IF EXISTS product_reviews;
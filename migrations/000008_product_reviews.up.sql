@@ -0,0 +1,25 @@
+CREATE TABLE product_reviews (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL,
+  rating SMALLINT NOT NULL,
+  title TEXT NOT NULL,
+  body TEXT NOT NULL,
+  status TEXT NOT NULL DEFAULT 'pending',
+  moderation_note TEXT,
+  moderated_at TIMESTAMPTZ,
+  CONSTRAINT product_reviews_rating_check CHECK (rating BETWEEN 1 AND 5),
+  CONSTRAINT product_reviews_status_check CHECK (status IN ('pending', 'approved', 'rejected')),
+  CONSTRAINT product_reviews_product_user_key UNIQUE (product_id, user_id)
+);
+
+CREATE INDEX product_reviews_product_status_idx ON product_reviews (product_id, status, created_at DESC);
+CREATE INDEX product_reviews_status_idx ON product_reviews (status, created_at);
+
+-- Agregat rating dari review yang sudah approved, di-maintain saat moderasi
+ALTER TABLE products ADD COLUMN rating_average NUMERIC(3, 2) NOT NULL DEFAULT 0;
+ALTER TABLE products ADD COLUMN review_count INT NOT NULL DEFAULT 0;
+
+CREATE INDEX products_rating_average_idx ON products (rating_average);
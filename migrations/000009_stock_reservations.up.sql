@@ -0,0 +1,17 @@
+-- Reservasi stok per baris cart. Reservasi yang sudah lewat expires_at tidak dihitung lagi
+-- dan dibersihkan secara berkala oleh sweeper.
+CREATE TABLE stock_reservations (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  cart_item_id BIGINT NOT NULL,
+  user_id BIGINT NOT NULL,
+  quantity INT NOT NULL,
+  expires_at TIMESTAMPTZ NOT NULL,
+  CONSTRAINT stock_reservations_quantity_check CHECK (quantity > 0),
+  CONSTRAINT stock_reservations_cart_item_product_key UNIQUE (cart_item_id, product_id)
+);
+
+CREATE INDEX stock_reservations_product_expires_at_idx ON stock_reservations (product_id, expires_at);
+CREATE INDEX stock_reservations_expires_at_idx ON stock_reservations (expires_at);
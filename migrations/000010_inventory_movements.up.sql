@@ -0,0 +1,24 @@
+-- Ledger stok append-only. products.quantity tetap disimpan sebagai saldo berjalan
+-- dan selalu diubah dalam transaksi yang sama dengan insert movement.
+CREATE TABLE inventory_movements (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  delta INT NOT NULL,
+  quantity_after INT NOT NULL,
+  reason TEXT NOT NULL,
+  actor_id BIGINT,
+  reference TEXT,
+  note TEXT,
+  CONSTRAINT inventory_movements_delta_check CHECK (delta <> 0),
+  CONSTRAINT inventory_movements_quantity_after_check CHECK (quantity_after >= 0),
+  CONSTRAINT inventory_movements_reason_check CHECK (reason IN ('restock', 'sale', 'adjustment', 'return'))
+);
+
+CREATE INDEX inventory_movements_product_created_at_idx ON inventory_movements (product_id, created_at DESC);
+
+-- Saldo awal untuk produk yang sudah ada
+INSERT INTO inventory_movements (product_id, delta, quantity_after, reason, reference)
+SELECT id, quantity, quantity, 'adjustment', 'opening-balance'
+FROM products
+WHERE quantity IS NOT NULL AND quantity > 0;
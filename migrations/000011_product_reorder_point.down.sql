@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS products_low_stock_idx;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_reorder_point_check;
+ALTER TABLE products DROP COLUMN IF EXISTS reorder_point;
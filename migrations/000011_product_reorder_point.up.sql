@@ -0,0 +1,5 @@
+-- Ambang stok minimum per produk. NULL berarti produk tidak dipantau.
+ALTER TABLE products ADD COLUMN reorder_point INT;
+ALTER TABLE products ADD CONSTRAINT products_reorder_point_check CHECK (reorder_point >= 0);
+
+CREATE INDEX products_low_stock_idx ON products (id) WHERE reorder_point IS NOT NULL AND quantity <= reorder_point;
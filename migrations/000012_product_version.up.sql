@@ -0,0 +1,2 @@
+-- Versi untuk optimistic concurrency (ETag / If-Match). Naik setiap kali produk atau stoknya berubah.
+ALTER TABLE products ADD COLUMN version INT NOT NULL DEFAULT 1;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS product_slug_redirects;
+
+DROP INDEX IF EXISTS products_slug_key;
+ALTER TABLE products DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,47 @@
+ALTER TABLE products ADD COLUMN slug TEXT;
+
+-- Index dibuat sebelum backfill agar pengecekan slug di bawah tidak memindai seluruh tabel
+CREATE UNIQUE INDEX products_slug_key ON products (slug);
+
+-- Backfill dari nama produk dengan aturan yang sama seperti utils.Slugify dan uniqueSlug di service:
+-- huruf diuraikan dengan NFKD dan tanda diakritiknya dibuang, karakter selain huruf/angka ASCII menjadi
+-- tanda hubung, panjang dibatasi 80. Produk diproses berurutan berdasarkan id; slug yang sudah dipakai
+-- atau termasuk segmen route yang dicadangkan (reservedSlugs) diberi suffix -2, -3, dst. sampai unik.
+-- NORMALIZE membutuhkan PostgreSQL 13+ dengan encoding UTF8.
+DO $$
+DECLARE
+  p RECORD;
+  base TEXT;
+  candidate TEXT;
+  n INT;
+BEGIN
+  FOR p IN SELECT id, name FROM products ORDER BY id LOOP
+    base := REGEXP_REPLACE(NORMALIZE(LOWER(p.name), NFKD), '[\u0300-\u036f\u1ab0-\u1aff\u1dc0-\u1dff\u20d0-\u20ff\ufe20-\ufe2f]', '', 'g');
+    base := TRIM(BOTH '-' FROM REGEXP_REPLACE(base, '[^a-zA-Z0-9]+', '-', 'g'));
+    base := RTRIM(LEFT(base, 80), '-');
+    IF base = '' THEN
+      base := 'product';
+    END IF;
+
+    candidate := base;
+    n := 2;
+    WHILE candidate IN ('reviews', 'recommendations', 'stock-movements')
+       OR EXISTS (SELECT 1 FROM products WHERE slug = candidate) LOOP
+      candidate := base || '-' || n;
+      n := n + 1;
+    END LOOP;
+
+    UPDATE products SET slug = candidate WHERE id = p.id;
+  END LOOP;
+END $$;
+
+ALTER TABLE products ALTER COLUMN slug SET NOT NULL;
+
+-- Slug lama setelah produk di-rename, agar URL lama tetap bisa di-redirect
+CREATE TABLE product_slug_redirects (
+  slug TEXT PRIMARY KEY,
+  product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX product_slug_redirects_product_id_idx ON product_slug_redirects (product_id);
@@ -0,0 +1,13 @@
+-- Skor "frequently bought together" hasil job co-occurrence dari cart_items.
+-- Tabel ini dihitung ulang penuh setiap kali job berjalan.
+CREATE TABLE product_recommendations (
+  product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  recommended_product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  pair_count INT NOT NULL,
+  score DOUBLE PRECISION NOT NULL,
+  computed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  PRIMARY KEY (product_id, recommended_product_id),
+  CONSTRAINT product_recommendations_distinct_check CHECK (product_id <> recommended_product_id)
+);
+
+CREATE INDEX product_recommendations_score_idx ON product_recommendations (product_id, score DESC);
@@ -0,0 +1,22 @@
+CREATE TABLE wishlists (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  user_id BIGINT NOT NULL,
+  name TEXT NOT NULL,
+  share_token TEXT, -- Terisi jika wishlist dibagikan secara publik
+  CONSTRAINT wishlists_share_token_key UNIQUE (share_token)
+);
+
+CREATE INDEX wishlists_user_id_idx ON wishlists (user_id, created_at);
+
+CREATE TABLE wishlist_items (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  wishlist_id BIGINT NOT NULL REFERENCES wishlists (id) ON DELETE CASCADE,
+  product_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  color TEXT NOT NULL DEFAULT '',
+  size TEXT NOT NULL DEFAULT '',
+  note TEXT,
+  CONSTRAINT wishlist_items_product_variant_key UNIQUE (wishlist_id, product_id, color, size)
+);
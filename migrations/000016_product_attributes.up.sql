@@ -0,0 +1,16 @@
+-- Skema atribut produk yang didefinisikan admin (material, berat, brand, ...)
+CREATE TABLE attribute_definitions (
+  code TEXT PRIMARY KEY,
+  name TEXT NOT NULL,
+  type TEXT NOT NULL,
+  allowed_values TEXT[] NOT NULL DEFAULT '{}',
+  filterable BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  CONSTRAINT attribute_definitions_code_check CHECK (code ~ '^[a-z][a-z0-9_]*$'),
+  CONSTRAINT attribute_definitions_type_check CHECK (type IN ('string', 'number', 'boolean', 'enum'))
+);
+
+-- Nilai atribut per produk, divalidasi aplikasi terhadap attribute_definitions
+ALTER TABLE products ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX products_attributes_idx ON products USING GIN (attributes jsonb_path_ops);
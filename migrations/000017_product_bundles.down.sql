@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS product_bundle_items;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_type_check;
+ALTER TABLE products DROP COLUMN IF EXISTS type;
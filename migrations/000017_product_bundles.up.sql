@@ -0,0 +1,15 @@
+-- Produk bundle/kit dijual dengan harga sendiri, stoknya diturunkan dari stok komponen
+ALTER TABLE products ADD COLUMN type TEXT NOT NULL DEFAULT 'simple';
+ALTER TABLE products ADD CONSTRAINT products_type_check CHECK (type IN ('simple', 'bundle'));
+
+CREATE TABLE product_bundle_items (
+  bundle_id BIGINT NOT NULL REFERENCES products (id) ON DELETE CASCADE,
+  component_id BIGINT NOT NULL REFERENCES products (id) ON DELETE RESTRICT,
+  quantity INT NOT NULL,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  PRIMARY KEY (bundle_id, component_id),
+  CONSTRAINT product_bundle_items_quantity_check CHECK (quantity > 0),
+  CONSTRAINT product_bundle_items_self_check CHECK (bundle_id <> component_id)
+);
+
+CREATE INDEX product_bundle_items_component_id_idx ON product_bundle_items (component_id);
@@ -0,0 +1 @@
+ALTER TABLE cart_items DROP COLUMN IF EXISTS unit_price;
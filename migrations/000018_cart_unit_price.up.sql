@@ -0,0 +1,5 @@
+-- Snapshot harga satuan saat item ditambahkan ke cart. Harga baris dihitung ulang dari harga
+-- produk saat cart dibaca; snapshot dipakai untuk mendeteksi perubahan harga.
+ALTER TABLE cart_items ADD COLUMN unit_price BIGINT;
+UPDATE cart_items SET unit_price = CASE WHEN quantity > 0 THEN price / quantity ELSE price END;
+ALTER TABLE cart_items ALTER COLUMN unit_price SET NOT NULL;
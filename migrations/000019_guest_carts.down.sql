@@ -0,0 +1,10 @@
+DELETE FROM stock_reservations WHERE user_id IS NULL;
+ALTER TABLE stock_reservations ALTER COLUMN user_id SET NOT NULL;
+
+DELETE FROM cart_items WHERE guest_cart_id IS NOT NULL;
+ALTER TABLE cart_items DROP CONSTRAINT IF EXISTS cart_items_owner_check;
+DROP INDEX IF EXISTS cart_items_guest_cart_id_idx;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS guest_cart_id;
+ALTER TABLE cart_items ALTER COLUMN user_id SET NOT NULL;
+
+DROP TABLE IF EXISTS guest_carts;
@@ -0,0 +1,19 @@
+-- Cart untuk pengunjung yang belum login, diidentifikasi lewat cart token yang ditandatangani.
+-- Baris cart milik tepat satu pemilik: user atau guest cart. Guest cart dihapus setelah
+-- digabung ke cart user saat login atau setelah expires_at lewat.
+CREATE TABLE guest_carts (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX guest_carts_expires_at_idx ON guest_carts (expires_at);
+
+ALTER TABLE cart_items ALTER COLUMN user_id DROP NOT NULL;
+ALTER TABLE cart_items ADD COLUMN guest_cart_id BIGINT REFERENCES guest_carts (id) ON DELETE CASCADE;
+ALTER TABLE cart_items ADD CONSTRAINT cart_items_owner_check CHECK ((user_id IS NULL) <> (guest_cart_id IS NULL));
+
+CREATE INDEX cart_items_guest_cart_id_idx ON cart_items (guest_cart_id) WHERE guest_cart_id IS NOT NULL;
+
+-- Reservasi untuk baris guest cart tidak punya user
+ALTER TABLE stock_reservations ALTER COLUMN user_id DROP NOT NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_max_per_order_check;
+ALTER TABLE products DROP COLUMN IF EXISTS max_per_order;
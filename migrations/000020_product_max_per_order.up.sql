@@ -0,0 +1,3 @@
+-- Batas quantity satu produk dalam satu cart/order, diatur admin. NULL berarti tanpa batas.
+ALTER TABLE products ADD COLUMN max_per_order INT;
+ALTER TABLE products ADD CONSTRAINT products_max_per_order_check CHECK (max_per_order IS NULL OR max_per_order > 0);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS cart_coupons;
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupons;
+
+DROP INDEX IF EXISTS products_category_idx;
+ALTER TABLE products DROP COLUMN IF EXISTS category;
@@ -0,0 +1,53 @@
+-- Kategori produk, dipakai untuk filter listing dan eligibility kupon
+ALTER TABLE products ADD COLUMN category VARCHAR(100);
+CREATE INDEX products_category_idx ON products (category) WHERE category IS NOT NULL;
+
+-- Kode promo. value berisi persen (1-100) untuk percentage, atau minor unit dalam currency untuk fixed.
+-- min_spend juga dalam minor unit currency. product_ids dan categories kosong berarti semua produk eligible;
+-- jika keduanya diisi, produk eligible jika cocok dengan salah satunya.
+CREATE TABLE coupons (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  code VARCHAR(50) NOT NULL UNIQUE,
+  description TEXT NOT NULL DEFAULT '',
+  discount_type TEXT NOT NULL,
+  value BIGINT NOT NULL,
+  currency CHAR(3) REFERENCES currencies (code),
+  min_spend BIGINT,
+  product_ids BIGINT[] NOT NULL DEFAULT '{}',
+  categories TEXT[] NOT NULL DEFAULT '{}',
+  usage_limit INT,
+  per_user_limit INT,
+  starts_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+  ends_at TIMESTAMPTZ,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  CONSTRAINT coupons_code_check CHECK (code = UPPER(code)),
+  CONSTRAINT coupons_discount_type_check CHECK (discount_type IN ('percentage', 'fixed')),
+  CONSTRAINT coupons_value_check CHECK (value > 0 AND (discount_type <> 'percentage' OR value <= 100)),
+  CONSTRAINT coupons_currency_check CHECK (currency IS NOT NULL OR (discount_type = 'percentage' AND min_spend IS NULL)),
+  CONSTRAINT coupons_min_spend_check CHECK (min_spend IS NULL OR min_spend > 0),
+  CONSTRAINT coupons_usage_limit_check CHECK (usage_limit IS NULL OR usage_limit > 0),
+  CONSTRAINT coupons_per_user_limit_check CHECK (per_user_limit IS NULL OR per_user_limit > 0),
+  CONSTRAINT coupons_window_check CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+-- Pemakaian kupon yang sudah dibayar, dasar perhitungan usage_limit dan per_user_limit
+CREATE TABLE coupon_redemptions (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  coupon_id BIGINT NOT NULL REFERENCES coupons (id) ON DELETE CASCADE,
+  user_id BIGINT NOT NULL
+);
+
+CREATE INDEX coupon_redemptions_coupon_user_idx ON coupon_redemptions (coupon_id, user_id);
+
+-- Kupon yang sedang dipasang di cart, satu per cart. Diskonnya dihitung ulang setiap kali cart dibaca.
+CREATE TABLE cart_coupons (
+  id BIGSERIAL PRIMARY KEY,
+  applied_at TIMESTAMPTZ DEFAULT NOW(),
+  coupon_id BIGINT NOT NULL REFERENCES coupons (id) ON DELETE CASCADE,
+  user_id BIGINT UNIQUE,
+  guest_cart_id BIGINT UNIQUE REFERENCES guest_carts (id) ON DELETE CASCADE,
+  CONSTRAINT cart_coupons_owner_check CHECK ((user_id IS NULL) <> (guest_cart_id IS NULL))
+);
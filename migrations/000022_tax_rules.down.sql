@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS tax_rules;
+ALTER TABLE products DROP COLUMN IF EXISTS tax_class;
+DROP TABLE IF EXISTS tax_classes;
@@ -0,0 +1,35 @@
+-- Kelas pajak produk. Produk tanpa rule untuk kelasnya di suatu wilayah tidak dikenai pajak.
+CREATE TABLE tax_classes (
+  code VARCHAR(50) PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  name TEXT NOT NULL
+);
+
+INSERT INTO tax_classes (code, name) VALUES
+  ('standard', 'Standard rate'),
+  ('reduced', 'Reduced rate'),
+  ('exempt', 'Tax exempt');
+
+ALTER TABLE products ADD COLUMN tax_class VARCHAR(50) NOT NULL DEFAULT 'standard' REFERENCES tax_classes (code);
+
+-- Tarif per kelas pajak dan wilayah. rate dalam persen; inclusive berarti harga produk sudah
+-- termasuk pajak. region kosong berlaku untuk seluruh negara, rule dengan region yang cocok didahulukan.
+CREATE TABLE tax_rules (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  tax_class VARCHAR(50) NOT NULL REFERENCES tax_classes (code) ON DELETE CASCADE,
+  country CHAR(2) NOT NULL,
+  region VARCHAR(50) NOT NULL DEFAULT '',
+  name TEXT NOT NULL,
+  rate NUMERIC(7, 4) NOT NULL,
+  inclusive BOOLEAN NOT NULL DEFAULT FALSE,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  CONSTRAINT tax_rules_rate_check CHECK (rate >= 0 AND rate < 100),
+  CONSTRAINT tax_rules_scope_key UNIQUE (tax_class, country, region)
+);
+
+-- Harga yang sudah ada dianggap termasuk PPN, sehingga total cart tidak berubah
+INSERT INTO tax_rules (tax_class, country, name, rate, inclusive) VALUES
+  ('standard', 'ID', 'PPN', 11, TRUE);
@@ -0,0 +1,11 @@
+DROP TABLE IF EXISTS shipping_rates;
+DROP TABLE IF EXISTS shipping_methods;
+DROP TABLE IF EXISTS shipping_zones;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_dimensions_check;
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_weight_grams_check;
+ALTER TABLE products
+  DROP COLUMN IF EXISTS height_mm,
+  DROP COLUMN IF EXISTS width_mm,
+  DROP COLUMN IF EXISTS length_mm,
+  DROP COLUMN IF EXISTS weight_grams;
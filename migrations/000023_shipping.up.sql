@@ -0,0 +1,58 @@
+-- Berat (gram) dan dimensi kemasan (mm) produk untuk ongkos kirim. NULL dihitung 0.
+ALTER TABLE products
+  ADD COLUMN weight_grams INT,
+  ADD COLUMN length_mm INT,
+  ADD COLUMN width_mm INT,
+  ADD COLUMN height_mm INT,
+  ADD CONSTRAINT products_weight_grams_check CHECK (weight_grams IS NULL OR weight_grams >= 0),
+  ADD CONSTRAINT products_dimensions_check CHECK (
+    (length_mm IS NULL OR length_mm > 0) AND (width_mm IS NULL OR width_mm > 0) AND (height_mm IS NULL OR height_mm > 0)
+  );
+
+-- Zona tujuan berdasarkan rentang kode pos dalam satu negara. Rentang kosong berlaku untuk
+-- seluruh negara; zona dengan rentang yang cocok didahulukan.
+CREATE TABLE shipping_zones (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  name TEXT NOT NULL,
+  country CHAR(2) NOT NULL,
+  postal_from VARCHAR(20) NOT NULL DEFAULT '',
+  postal_to VARCHAR(20) NOT NULL DEFAULT '',
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  CONSTRAINT shipping_zones_postal_range_check CHECK (
+    (postal_from = '' AND postal_to = '')
+    OR (postal_from <> '' AND length(postal_from) = length(postal_to) AND postal_from <= postal_to)
+  )
+);
+
+CREATE INDEX idx_shipping_zones_country ON shipping_zones (country) WHERE is_active;
+
+-- Metode pengiriman per zona, mis. regular atau express. free_shipping berarti metode ini gratis
+-- jika cart mencapai ambang free shipping.
+CREATE TABLE shipping_methods (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  updated_at TIMESTAMPTZ DEFAULT NOW(),
+  zone_id BIGINT NOT NULL REFERENCES shipping_zones (id) ON DELETE CASCADE,
+  code VARCHAR(50) NOT NULL,
+  name TEXT NOT NULL,
+  currency CHAR(3) NOT NULL REFERENCES currencies (code),
+  min_days INT NOT NULL,
+  max_days INT NOT NULL,
+  free_shipping BOOLEAN NOT NULL DEFAULT FALSE,
+  is_active BOOLEAN NOT NULL DEFAULT TRUE,
+  CONSTRAINT shipping_methods_zone_code_key UNIQUE (zone_id, code),
+  CONSTRAINT shipping_methods_days_check CHECK (min_days >= 0 AND max_days >= min_days)
+);
+
+-- Tarif per band berat. Band yang dipakai adalah max_weight_grams terkecil yang >= berat paket;
+-- paket yang lebih berat dari band terakhir tidak bisa dikirim dengan metode tersebut.
+CREATE TABLE shipping_rates (
+  method_id BIGINT NOT NULL REFERENCES shipping_methods (id) ON DELETE CASCADE,
+  max_weight_grams INT NOT NULL,
+  price BIGINT NOT NULL,
+  PRIMARY KEY (method_id, max_weight_grams),
+  CONSTRAINT shipping_rates_weight_check CHECK (max_weight_grams > 0),
+  CONSTRAINT shipping_rates_price_check CHECK (price >= 0)
+);
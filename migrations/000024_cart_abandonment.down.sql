@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS cart_items_archive;
+DROP INDEX IF EXISTS cart_items_updated_at_idx;
+DROP TABLE IF EXISTS cart_abandonments;
@@ -0,0 +1,34 @@
+-- Cart yang tidak disentuh melewati batas waktu dicatat sebagai abandoned. Satu cart hanya punya
+-- satu catatan terbuka (belum recovered, closed atau purged) dan reminder terkirim paling banyak
+-- sekali per catatan. Cart yang abandoned lagi setelah catatannya tertutup mendapat catatan dan
+-- reminder baru. Tidak ada foreign key ke users/guest_carts agar catatan tetap ada untuk laporan
+-- setelah cart-nya dihapus.
+CREATE TABLE cart_abandonments (
+  id BIGSERIAL PRIMARY KEY,
+  created_at TIMESTAMPTZ DEFAULT NOW(),
+  user_id BIGINT,
+  guest_cart_id BIGINT,
+  last_activity_at TIMESTAMPTZ NOT NULL,
+  item_count INT NOT NULL,
+  total_amount BIGINT NOT NULL,
+  currency CHAR(3) NOT NULL,
+  notified_at TIMESTAMPTZ,  -- Reminder terkirim
+  recovered_at TIMESTAMPTZ, -- Pemilik kembali mengubah cart
+  closed_at TIMESTAMPTZ,    -- Cart dikosongkan atau dihapus tanpa aktivitas baru, mis. guest cart kedaluwarsa
+  purged_at TIMESTAMPTZ,    -- Cart dihapus/diarsipkan karena melewati masa retensi
+  CONSTRAINT cart_abandonments_owner_check CHECK ((user_id IS NULL) <> (guest_cart_id IS NULL))
+);
+
+CREATE UNIQUE INDEX cart_abandonments_open_user_idx ON cart_abandonments (user_id)
+  WHERE user_id IS NOT NULL AND recovered_at IS NULL AND closed_at IS NULL AND purged_at IS NULL;
+CREATE UNIQUE INDEX cart_abandonments_open_guest_idx ON cart_abandonments (guest_cart_id)
+  WHERE guest_cart_id IS NOT NULL AND recovered_at IS NULL AND closed_at IS NULL AND purged_at IS NULL;
+CREATE INDEX cart_abandonments_last_activity_at_idx ON cart_abandonments (last_activity_at);
+
+CREATE INDEX cart_items_updated_at_idx ON cart_items (updated_at);
+
+-- Baris cart yang melewati masa retensi dipindahkan ke sini jika mode purge-nya archive.
+-- Kolomnya mengikuti cart_items dengan urutan yang sama, ditambah archived_at.
+CREATE TABLE cart_items_archive (LIKE cart_items INCLUDING DEFAULTS);
+ALTER TABLE cart_items_archive ADD COLUMN archived_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+CREATE INDEX cart_items_archive_updated_at_idx ON cart_items_archive (updated_at);
@@ -0,0 +1,2 @@
+ALTER TABLE cart_items_archive DROP COLUMN IF EXISTS saved_for_later;
+ALTER TABLE cart_items DROP COLUMN IF EXISTS saved_for_later;
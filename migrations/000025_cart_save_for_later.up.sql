@@ -0,0 +1,6 @@
+-- Baris yang disimpan untuk nanti tetap ada di cart tetapi tidak ikut total, kupon, ongkos kirim
+-- dan checkout, dan tidak memegang reservasi stok.
+ALTER TABLE cart_items ADD COLUMN saved_for_later BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Kolom baru berada setelah archived_at, sehingga arsip diisi dengan daftar kolom eksplisit
+ALTER TABLE cart_items_archive ADD COLUMN saved_for_later BOOLEAN NOT NULL DEFAULT FALSE;
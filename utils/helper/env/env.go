@@ -1,6 +1,11 @@
 package env
 
-import "os"
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+)
 
 func IsProduction() bool {
 	return os.Getenv("ENV") == "production"
@@ -9,3 +14,39 @@ func IsProduction() bool {
 func IsLocal() bool {
 	return os.Getenv("ENV") == "local"
 }
+
+// Duration membaca env berformat durasi Go (contoh: "30m"), fallback jika kosong atau tidak valid
+func Duration(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		slog.Warn("Invalid duration env, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return d
+}
+
+// Int membaca env berupa angka, fallback jika kosong atau tidak valid
+func Int(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		slog.Warn("Invalid integer env, using default", "key", key, "value", value, "default", fallback)
+		return fallback
+	}
+	return n
+}
+
+// String membaca env berupa teks, fallback jika kosong
+func String(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSlugLength membatasi panjang slug agar URL tetap pendek
+const maxSlugLength = 80
+
+// Slugify mengubah teks menjadi slug URL: huruf kecil ASCII, angka, dan tanda hubung.
+// Huruf beraksen diubah ke huruf dasarnya, contoh "Kaos Café" menjadi "kaos-cafe".
+func Slugify(text string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFKD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Tanda diakritik dibuang
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimRight(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	return slug
+}
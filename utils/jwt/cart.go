@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const cartTokenAudience = "guest_cart"
+
+// CartClaims adalah isi cart token untuk guest cart
+type CartClaims struct {
+	GuestCartID uint `json:"guest_cart_id"`
+	jwt.RegisteredClaims
+}
+
+// cartTokenKey diturunkan dari JWT_SECRET tapi berbeda dari kunci token login,
+// sehingga cart token tidak bisa dipakai sebagai token login dan sebaliknya
+func cartTokenKey() ([]byte, error) {
+	secret := GetJWTSecret()
+	if secret == "" {
+		return nil, errors.New("JWT_SECRET tidak ditemukan di environment")
+	}
+	return []byte("guest-cart:" + secret), nil
+}
+
+// GenerateCartToken membuat cart token yang berlaku sampai expiresAt
+func GenerateCartToken(guestCartID uint, expiresAt time.Time) (string, error) {
+	key, err := cartTokenKey()
+	if err != nil {
+		return "", err
+	}
+
+	claims := CartClaims{
+		GuestCartID: guestCartID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{cartTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+}
+
+// ParseCartToken memverifikasi cart token dan mengembalikan ID guest cart-nya
+func ParseCartToken(tokenString string) (uint, error) {
+	key, err := cartTokenKey()
+	if err != nil {
+		return 0, err
+	}
+
+	claims := &CartClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		return key, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithAudience(cartTokenAudience), jwt.WithExpirationRequired())
+	if err != nil || !token.Valid || claims.GuestCartID == 0 {
+		return 0, errors.New("invalid cart token")
+	}
+	return claims.GuestCartID, nil
+}
@@ -1,9 +1,17 @@
 package web
 
-import "net/http"
+import (
+	"context"
+	"net/http"
+)
 
 func GetUserID(r *http.Request) uint {
-	if uid, ok := r.Context().Value("user_id").(uint); ok {
+	return UserIDFromContext(r.Context())
+}
+
+// UserIDFromContext membaca user_id yang disisipkan middleware auth, 0 jika tidak ada
+func UserIDFromContext(ctx context.Context) uint {
+	if uid, ok := ctx.Value("user_id").(uint); ok {
 		return uid
 	}
 	return 0
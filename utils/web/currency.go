@@ -0,0 +1,19 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+)
+
+// RequestedCurrency membaca mata uang tampilan dari query ?currency= atau header Accept-Currency.
+// Jika header berisi daftar (mis. "USD, SGD;q=0.8"), yang pertama dipakai. Kosong berarti tanpa konversi.
+func RequestedCurrency(r *http.Request) string {
+	value := r.URL.Query().Get("currency")
+	if value == "" {
+		value = r.Header.Get("Accept-Currency")
+	}
+
+	value, _, _ = strings.Cut(value, ",")
+	value, _, _ = strings.Cut(value, ";")
+	return strings.ToUpper(strings.TrimSpace(value))
+}
@@ -0,0 +1,38 @@
+package web
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ETag membentuk ETag dari versi resource, contoh: "v3"
+func ETag(version int) string {
+	return fmt.Sprintf(`"v%d"`, version)
+}
+
+// SetETag menulis header ETag pada response
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", ETag(version))
+}
+
+// IfMatchVersion membaca versi dari header If-Match. Header wajib ada (428 jika kosong);
+// "*" mengembalikan 0 yang berarti versi apa pun diterima.
+func IfMatchVersion(r *http.Request) (int, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		return 0, NewHTTPError(http.StatusPreconditionRequired, "If-Match header is required", ErrPreconditionNeeded)
+	}
+	if header == "*" {
+		return 0, nil
+	}
+
+	tag := strings.TrimPrefix(header, "W/")
+	tag = strings.TrimSuffix(strings.TrimPrefix(tag, `"`), `"`)
+	version, err := strconv.Atoi(strings.TrimPrefix(tag, "v"))
+	if err != nil || version <= 0 {
+		return 0, NewHTTPError(http.StatusBadRequest, "Invalid If-Match header", ErrValidation)
+	}
+	return version, nil
+}
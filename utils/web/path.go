@@ -0,0 +1,16 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// PathID membaca path value numerik name, mis. {id}. label dipakai di pesan error 400,
+// mis. "wishlist" menjadi "Invalid wishlist ID".
+func PathID(r *http.Request, name, label string) (uint, error) {
+	id, err := strconv.ParseUint(r.PathValue(name), 10, 32)
+	if err != nil {
+		return 0, NewHTTPError(http.StatusBadRequest, "Invalid "+label+" ID", ErrValidation)
+	}
+	return uint(id), nil
+}
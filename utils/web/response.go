@@ -18,6 +18,8 @@ const (
 	ErrConflict            = 1005
 	ErrProcessing          = 1006
 	ErrForbidden           = 1007
+	ErrPreconditionFailed  = 1008
+	ErrPreconditionNeeded  = 1009
 	ErrCartNotFound        = 2001
 	ErrCartAccessDenied    = 2002
 	ErrInvalidCartData     = 2003
@@ -28,6 +30,9 @@ const (
 	ErrCartLimitExceeded   = 2008
 	ErrInvalidQuantity     = 2009
 	ErrBulkOperationFailed = 2010
+	ErrCouponNotFound      = 3001
+	ErrCouponNotApplicable = 3002
+	ErrShippingUnavailable = 3003
 )
 
 // Response adalah struktur standar untuk semua response API
@@ -93,6 +98,7 @@ func Err(w http.ResponseWriter, err error) error {
 		return writeJSON(w, httpErr.Code, Response{
 			Status:    "error",
 			Message:   httpErr.Message,
+			Data:      httpErr.Data,
 			ErrorCode: httpErr.ErrorCode,
 		})
 	}
@@ -112,6 +118,7 @@ type HTTPError struct {
 	Code      int
 	Message   string
 	ErrorCode int
+	Data      any // Opsional, misalnya representasi terbaru saat 412
 }
 
 func (e *HTTPError) Error() string {
@@ -125,3 +132,9 @@ func NewHTTPError(code int, message string, errorCode int) *HTTPError {
 		ErrorCode: errorCode, // Default error code, can be set later
 	}
 }
+
+// WithData menyertakan data pada response error
+func (e *HTTPError) WithData(data any) *HTTPError {
+	e.Data = data
+	return e
+}
@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strings"
 
+	"go-fiber-api/internal/shared/types"
+
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
@@ -63,6 +65,14 @@ func Validator() *validator.Validate {
 }
 
 func registerValidation(v *validator.Validate) error {
+	// Money divalidasi berdasarkan amount (minor unit), sehingga tag seperti
+	// `required,min=0` pada field harga tetap berlaku
+	v.RegisterCustomTypeFunc(func(field reflect.Value) interface{} {
+		if m, ok := field.Interface().(types.Money); ok {
+			return m.Amount
+		}
+		return nil
+	}, types.Money{})
 
 	return nil
 }